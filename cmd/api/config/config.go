@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"github.com/caarlos0/env/v9"
 	"github.com/joho/godotenv"
+	"time"
 )
 
 const (
@@ -14,8 +16,37 @@ const (
 	password     = "root"
 	schema       = "clinic"
 	charset      = "utf8"
+
+	postgresPort     = "5432"
+	postgresUser     = "postgres"
+	postgresPassword = "postgres"
 )
 
+// StoreMySQL, StorePostgres, StoreSQLite and StoreMemory are the stores the app can keep its data in.
+const (
+	StoreMySQL    = "mysql"
+	StorePostgres = "postgres"
+	StoreSQLite   = "sqlite"
+	StoreMemory   = "memory"
+)
+
+// ErrInvalidPageSize is the error returned when the page sizes configured are not positive, or the default one is
+// over the maximum.
+var ErrInvalidPageSize = errors.New("invalid page size, it must be positive and not over the maximum")
+
+// ErrInvalidDBTimeout is the error returned when the timeout of the database configured is negative.
+var ErrInvalidDBTimeout = errors.New("invalid database timeout, it must not be negative")
+
+// ErrInvalidDBConnection is the error returned when the limits, timeouts or retries of the database connection configured
+// are negative, or it's never tried to connect.
+var ErrInvalidDBConnection = errors.New("invalid database connection, its limits, timeouts and retries must not be negative and it must be tried at least once")
+
+// ErrInvalidHealthTimeout is the error returned when the timeout of the readiness check configured is not positive.
+var ErrInvalidHealthTimeout = errors.New("invalid health timeout, it must be positive")
+
+// ErrInvalidStore is the error returned when the store configured is not one of the supported ones.
+var ErrInvalidStore = errors.New("invalid store, it must be mysql, postgres, sqlite or memory")
+
 // Config centralizes all the config of dependencies of the whole app.
 type Config struct {
 	DBHost      string `env:"DATABASE_HOST"`
@@ -26,9 +57,61 @@ type Config struct {
 	DBCharset   string `env:"DATABASE_CHARSET"`
 	DBParseTime bool   `env:"DATABASE_PARSE_TIME" envDefault:"true"`
 
+	// DBTLS is the tls parameter of the MySQL driver, true, false, skip-verify or preferred, it's not set when empty.
+	// DBDialTimeout, DBReadTimeout and DBWriteTimeout are the timeouts of its connections, zero ones are not set.
+	DBTLS          string        `env:"DATABASE_TLS"`
+	DBDialTimeout  time.Duration `env:"DATABASE_DIAL_TIMEOUT" envDefault:"10s"`
+	DBReadTimeout  time.Duration `env:"DATABASE_READ_TIMEOUT"`
+	DBWriteTimeout time.Duration `env:"DATABASE_WRITE_TIMEOUT"`
+
+	// DBMaxOpenConns, DBMaxIdleConns and DBConnMaxLifetime limit the connection pool of MySQL, zero ones keep the
+	// defaults of sql.DB.
+	DBMaxOpenConns    int           `env:"DATABASE_MAX_OPEN_CONNS" envDefault:"25"`
+	DBMaxIdleConns    int           `env:"DATABASE_MAX_IDLE_CONNS" envDefault:"25"`
+	DBConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" envDefault:"5m"`
+
+	// DBConnectAttempts is how many times the app tries to connect to MySQL when it starts, waiting DBConnectBackoff
+	// after the first failure and twice as long after each next one.
+	DBConnectAttempts int           `env:"DATABASE_CONNECT_ATTEMPTS" envDefault:"5"`
+	DBConnectBackoff  time.Duration `env:"DATABASE_CONNECT_BACKOFF" envDefault:"1s"`
+
+	// DBTimeout is how long a request waits for the database, the stores give up after it, a zero one never does.
+	DBTimeout time.Duration `env:"DATABASE_TIMEOUT" envDefault:"5s"`
+
+	// HealthTimeout is how long the readiness check waits for the database to answer the ping.
+	HealthTimeout time.Duration `env:"HEALTH_TIMEOUT" envDefault:"2s"`
+
+	// The PostgreSQL store uses the DATABASE_HOST and DATABASE_SCHEMA variables too, as the database name.
+	PostgresPort     string `env:"POSTGRES_PORT"`
+	PostgresUser     string `env:"POSTGRES_USER"`
+	PostgresPassword string `env:"POSTGRES_PASSWORD"`
+	PostgresSSLMode  string `env:"POSTGRES_SSL_MODE" envDefault:"disable"`
+
+	// SQLitePath is the database file of the SQLite store, it's created when it doesn't exist.
+	SQLitePath string `env:"SQLITE_PATH" envDefault:"clinic.db"`
+
+	// Migrate runs the pending migrations of the database when the app starts.
+	Migrate bool `env:"MIGRATE" envDefault:"true"`
+
+	// Store is where the data is kept, StoreSeed is a JSON file loaded in the memory store when it starts.
+	Store     string `env:"STORE" envDefault:"mysql"`
+	StoreSeed string `env:"STORE_SEED"`
+
 	Host    string `env:"HOST"`
 	Port    string `env:"PORT"`
 	GinMode string `env:"GIN_MODE" envDefault:"debug"`
+
+	AppointmentDuration time.Duration `env:"APPOINTMENT_DEFAULT_DURATION" envDefault:"30m"`
+
+	// PageSize is the size of the pages listed when it's not requested, MaxPageSize is the largest one allowed.
+	PageSize    int `env:"PAGE_SIZE" envDefault:"20"`
+	MaxPageSize int `env:"MAX_PAGE_SIZE" envDefault:"100"`
+
+	// CalendarTimezone is the time zone of the clinic, the one the appointment dates are stored in.
+	// CalendarSecret signs the tokens of the calendar feeds, they are disabled when it's empty.
+	CalendarTimezone string `env:"CALENDAR_TIMEZONE" envDefault:"UTC"`
+	CalendarSecret   string `env:"CALENDAR_SECRET"`
+	CalendarLocation *time.Location
 }
 
 // Get returns the config of the whole app.
@@ -42,6 +125,36 @@ func Get() (*Config, error) {
 
 	loadDefaults(cfg)
 
+	switch cfg.Store {
+	case StoreMySQL, StorePostgres, StoreSQLite, StoreMemory:
+	default:
+		return nil, ErrInvalidStore
+	}
+
+	if cfg.DBTimeout < 0 {
+		return nil, ErrInvalidDBTimeout
+	}
+
+	if cfg.DBDialTimeout < 0 || cfg.DBReadTimeout < 0 || cfg.DBWriteTimeout < 0 || cfg.DBMaxOpenConns < 0 ||
+		cfg.DBMaxIdleConns < 0 || cfg.DBConnMaxLifetime < 0 || cfg.DBConnectAttempts < 1 || cfg.DBConnectBackoff < 0 {
+		return nil, ErrInvalidDBConnection
+	}
+
+	if cfg.HealthTimeout <= 0 {
+		return nil, ErrInvalidHealthTimeout
+	}
+
+	if cfg.PageSize < 1 || cfg.MaxPageSize < cfg.PageSize {
+		return nil, ErrInvalidPageSize
+	}
+
+	location, err := time.LoadLocation(cfg.CalendarTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.CalendarLocation = location
+
 	return cfg, nil
 }
 
@@ -54,6 +167,10 @@ func loadDefaults(cfg *Config) {
 	cfg.DBSchema = defaultValue(cfg.DBSchema, schema)
 	cfg.DBCharset = defaultValue(cfg.DBCharset, charset)
 
+	cfg.PostgresPort = defaultValue(cfg.PostgresPort, postgresPort)
+	cfg.PostgresUser = defaultValue(cfg.PostgresUser, postgresUser)
+	cfg.PostgresPassword = defaultValue(cfg.PostgresPassword, postgresPassword)
+
 	cfg.Host = defaultValue(cfg.Host, host)
 	cfg.Port = defaultValue(cfg.Port, port)
 }
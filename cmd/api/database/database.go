@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/migrate"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/postgres"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/sqlite"
+	"log"
+)
+
+// Open opens the database of the store configured, the memory store has none, so it returns nil for it.
+func Open(cfg *config.Config) (*sql.DB, error) {
+	switch cfg.Store {
+	case config.StoreMemory:
+		return nil, nil
+	case config.StorePostgres:
+		return postgres.Open(postgres.New(
+			postgres.WithUsername(cfg.PostgresUser),
+			postgres.WithPassword(cfg.PostgresPassword),
+			postgres.WithHost(cfg.DBHost+":"+cfg.PostgresPort),
+			postgres.WithName(cfg.DBSchema),
+			postgres.WithSSLMode(cfg.PostgresSSLMode),
+		))
+	case config.StoreSQLite:
+		return sqlite.Open(sqlite.New(
+			sqlite.WithPath(cfg.SQLitePath),
+		))
+	default:
+		return mysql.Open(mysql.New(
+			mysql.WithUsername(cfg.DBUser),
+			mysql.WithPassword(cfg.DBPassword),
+			mysql.WithHost(cfg.DBHost+":"+cfg.DBPort),
+			mysql.WithName(cfg.DBSchema),
+			mysql.WithCharset(cfg.DBCharset),
+			mysql.WithParseTime(cfg.DBParseTime),
+			mysql.WithTLS(cfg.DBTLS),
+			mysql.WithTimeouts(cfg.DBDialTimeout, cfg.DBReadTimeout, cfg.DBWriteTimeout),
+			mysql.WithMaxOpenConns(cfg.DBMaxOpenConns),
+			mysql.WithMaxIdleConns(cfg.DBMaxIdleConns),
+			mysql.WithConnMaxLifetime(cfg.DBConnMaxLifetime),
+			mysql.WithConnectRetry(cfg.DBConnectAttempts, cfg.DBConnectBackoff),
+		))
+	}
+}
+
+// baseline has the tables of the first migration, the ones the old clinic.sql script created, so the databases built
+// with it get the migration recorded and the next ones applied instead of being recreated.
+var baseline = migrate.WithBaseline("dentist", "patient", "appointment")
+
+// Migrator creates the migrator of the database of the store configured, with its migrations.
+func Migrator(cfg *config.Config, db *sql.DB) (*migrate.Migrator, error) {
+	switch cfg.Store {
+	case config.StorePostgres:
+		return migrate.New(db, migrate.Postgres, postgres.Migrations, baseline)
+	case config.StoreSQLite:
+		return migrate.New(db, migrate.SQLite, sqlite.Migrations, baseline)
+	default:
+		return migrate.New(db, migrate.MySQL, mysql.Migrations, baseline)
+	}
+}
+
+// ErrNotEmpty is returned when loading the test records into a database that already has records, as loading them
+// twice would duplicate them.
+var ErrNotEmpty = errors.New("the database already has records, the test records are only loaded into an empty one")
+
+// seeded has the tables that must be empty to load the test records.
+var seeded = []string{"dentist", "patient"}
+
+// Seed loads the test records of the database of the store configured, it fails with ErrNotEmpty when it already has
+// dentists or patients.
+func Seed(ctx context.Context, cfg *config.Config, db *sql.DB) error {
+	for _, table := range seeded {
+		var one int
+
+		err := db.QueryRowContext(ctx, "SELECT 1 FROM "+table+" LIMIT 1").Scan(&one)
+		switch {
+		case err == nil:
+			return fmt.Errorf("%w: the %s table isn't empty", ErrNotEmpty, table)
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+	}
+
+	switch cfg.Store {
+	case config.StorePostgres:
+		return migrate.Seed(ctx, db, postgres.TestRecords)
+	case config.StoreSQLite:
+		return migrate.Seed(ctx, db, sqlite.TestRecords)
+	default:
+		return migrate.Seed(ctx, db, mysql.TestRecords)
+	}
+}
+
+// Migrate applies the pending migrations of the database of the store configured, logging each one.
+func Migrate(ctx context.Context, cfg *config.Config, db *sql.DB) error {
+	migrator, err := Migrator(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		log.Printf("applied migration %04d_%s", m.Version, m.Name)
+	}
+
+	return err
+}
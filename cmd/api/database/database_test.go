@@ -0,0 +1,193 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/database"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/migrate"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/sqlite"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// baselineSchema is the database the MySQL test builds with the clinic.sql script, it's dropped when it ends.
+const baselineSchema = "clinic_baseline"
+
+// TestMigrateClinicSQL builds a database with the clinic.sql script used before the migrations, ported to SQLite, and
+// migrates it: the first migration must be recorded without running it, and the next ones applied over its records.
+func TestMigrateClinicSQL(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.Open(sqlite.New(sqlite.WithPath(filepath.Join(t.TempDir(), "clinic.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrateClinicSQL(t, ctx, &config.Config{Store: config.StoreSQLite}, db, "testdata/clinic.sqlite.sql")
+}
+
+// TestSeedNotEmpty loads the test records twice, the second time must fail without loading them again.
+func TestSeedNotEmpty(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Store: config.StoreSQLite}
+
+	db, err := sqlite.Open(sqlite.New(sqlite.WithPath(filepath.Join(t.TempDir(), "clinic.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	err = database.Migrate(ctx, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = database.Seed(ctx, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want int
+
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dentist").Scan(&want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = database.Seed(ctx, cfg, db)
+	if !errors.Is(err, database.ErrNotEmpty) {
+		t.Fatalf("seeding again returned %v, want %v", err, database.ErrNotEmpty)
+	}
+
+	var got int
+
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dentist").Scan(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != want {
+		t.Errorf("there are %d dentists after seeding again, want %d", got, want)
+	}
+}
+
+// TestMigrateClinicSQLMySQL runs the clinic.sql script as it was in a new MySQL database and migrates it.
+// It runs against the server of MYSQL_TEST_DSN, creating and dropping a database of its own.
+func TestMigrateClinicSQLMySQL(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set, like root:root@tcp(localhost:3307)/clinic?parseTime=true")
+	}
+
+	ctx := context.Background()
+
+	server, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = server.Close() })
+
+	_, err = server.ExecContext(ctx, "DROP DATABASE IF EXISTS "+baselineSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _, _ = server.ExecContext(ctx, "DROP DATABASE IF EXISTS "+baselineSchema) })
+
+	cfg, err := mysqlDriver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.DBName = baselineSchema
+
+	_, err = server.ExecContext(ctx, "CREATE DATABASE "+baselineSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrateClinicSQL(t, ctx, &config.Config{Store: config.StoreMySQL}, db, "testdata/clinic.sql")
+}
+
+// migrateClinicSQL runs the script in the database, migrates it, and checks the migrations and the records.
+func migrateClinicSQL(t *testing.T, ctx context.Context, cfg *config.Config, db *sql.DB, script string) {
+	t.Helper()
+
+	content, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrate.Seed(ctx, db, strings.ReplaceAll(string(content), "`clinic`", "`"+baselineSchema+"`"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = database.Migrate(ctx, cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := database.Migrator(cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range status {
+		if !s.Applied {
+			t.Errorf("migration %04d_%s is pending, want it applied", s.Version, s.Name)
+		}
+	}
+
+	var (
+		dentistActive bool
+		patientActive bool
+		duration      int
+		appStatus     string
+		seriesID      sql.NullString
+	)
+
+	err = db.QueryRowContext(ctx, `SELECT d.active, p.active, a.duration, a.status, a.series_id FROM appointment a
+	INNER JOIN dentist d ON d.id = a.dentist_id INNER JOIN patient p ON p.id = a.patient_id WHERE a.id = 1`).
+		Scan(&dentistActive, &patientActive, &duration, &appStatus, &seriesID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !dentistActive || !patientActive || duration != 30 || appStatus != "scheduled" || seriesID.Valid {
+		t.Errorf("the first appointment has active %v and %v, duration %d, status %q and series %v, want true, true, "+
+			"30, scheduled and none", dentistActive, patientActive, duration, appStatus, seriesID)
+	}
+
+	var shifts int
+
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shift").Scan(&shifts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if shifts != 0 {
+		t.Errorf("there are %d shifts, want none", shifts)
+	}
+}
@@ -0,0 +1,48 @@
+-- The clinic.sql script the databases were built with before the migrations, ported to SQLite.
+
+CREATE TABLE IF NOT EXISTS `dentist` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `first_name` VARCHAR(45) NOT NULL,
+  `last_name` VARCHAR(45) NOT NULL,
+  `registration_number` VARCHAR(45) NOT NULL,
+  CONSTRAINT `registration_number_UNIQUE` UNIQUE (`registration_number`));
+
+CREATE TABLE IF NOT EXISTS `patient` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `first_name` VARCHAR(45) NOT NULL,
+  `last_name` VARCHAR(45) NOT NULL,
+  `address` VARCHAR(80) NOT NULL,
+  `dni` INT NOT NULL,
+  `discharge_date` DATETIME NOT NULL,
+  CONSTRAINT `dni_UNIQUE` UNIQUE (`dni`));
+
+CREATE TABLE IF NOT EXISTS `appointment` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `patient_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NOT NULL,
+  `date` DATETIME NOT NULL,
+  `description` VARCHAR(100) NOT NULL,
+  CONSTRAINT `appointment_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`),
+  CONSTRAINT `appointment_patient_patient_id_id`
+    FOREIGN KEY (`patient_id`)
+    REFERENCES `patient` (`id`));
+
+-- Test records for the 'dentist' table
+INSERT INTO `dentist` (`first_name`, `last_name`, `registration_number`) VALUES
+ ('Dr. Smile', 'McDentist', '12345'),
+ ('Dr. Sparkle', 'Tooth Fairy', '67890'),
+ ('Dr. Chomp', 'Flossington', '54321');
+
+-- Test records for the 'patient' table
+INSERT INTO `patient` (`first_name`, `last_name`, `address`, `dni`, `discharge_date`) VALUES
+('Toothless', 'McGums', '123 Cavity Ln', 12345678, '2023-09-15 09:00:00'),
+('Candy', 'Cane', '456 Sugar Ave', 98765432, '2023-09-16 10:30:00'),
+('Molar', 'Incisor', '789 Brush St', 56789012, '2023-09-17 14:15:00');
+
+-- Test records for the 'appointment' table
+INSERT INTO `appointment` (`patient_id`, `dentist_id`, `date`, `description`) VALUES
+(1, 1, '2023-09-15 11:30:00', 'Appointment for a dazzling smile'),
+(2, 2, '2023-09-16 15:45:00', 'Magical dental cleaning'),
+(3, 3, '2023-09-17 09:30:00', 'Chew-style tooth extraction operation');
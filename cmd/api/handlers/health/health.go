@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// StatusAlive, StatusReady and StatusUnavailable are the states answered by the probes.
+const (
+	StatusAlive       = "alive"
+	StatusReady       = "ready"
+	StatusUnavailable = "unavailable"
+)
+
+// Build is the metadata of the build of the app, set at link time.
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Status is the state of the app answered by the probes.
+type Status struct {
+	Status string `json:"status"`
+}
+
+// Readiness is the state of the app and its database, Database is only set for the stores with one.
+type Readiness struct {
+	Status   string `json:"status"`
+	Store    string `json:"store"`
+	Database *Pool  `json:"database,omitempty"`
+}
+
+// Pool is the state of the connection pool of the database.
+type Pool struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+// Handler is a structure for the health handler.
+type Handler struct {
+	db      *sql.DB
+	store   string
+	timeout time.Duration
+	build   Build
+}
+
+// NewHandler is a function to create a handler, db is nil for the stores without a database, like the memory one.
+func NewHandler(db *sql.DB, store string, timeout time.Duration, build Build) *Handler {
+	return &Handler{
+		db:      db,
+		store:   store,
+		timeout: timeout,
+		build:   build,
+	}
+}
+
+// Live is the handler answering whether the app is running, it never checks its dependencies, so the orchestrator
+// doesn't restart it while the database is down.
+func (h *Handler) Live() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		web.Success(ctx, http.StatusOK, Status{Status: StatusAlive})
+	}
+}
+
+// Ready is the handler answering whether the app can take requests, it pings the database, giving up after the
+// timeout, and answers 503 when it doesn't answer, with the stats of its connection pool either way.
+func (h *Handler) Ready() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		readiness := Readiness{
+			Status: StatusReady,
+			Store:  h.store,
+		}
+
+		if h.db == nil {
+			web.Success(ctx, http.StatusOK, readiness)
+			return
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+
+		status := http.StatusOK
+
+		err := h.db.PingContext(pingCtx)
+		if err != nil {
+			_ = ctx.Error(err)
+
+			readiness.Status = StatusUnavailable
+			status = http.StatusServiceUnavailable
+		}
+
+		readiness.Database = pool(h.db.Stats())
+
+		web.Success(ctx, status, readiness)
+	}
+}
+
+// Version is the handler responsible for retrieving the build metadata of the app.
+func (h *Handler) Version() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		web.Success(ctx, http.StatusOK, h.build)
+	}
+}
+
+// pool returns the state of the connection pool from its stats.
+func pool(stats sql.DBStats) *Pool {
+	return &Pool{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
@@ -1,11 +1,16 @@
 package apointment
 
 import (
+	"context"
 	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
 	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
 	"github.com/Nachofra/final-esp-backend-3/pkg/web"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -24,16 +29,23 @@ type Handler struct {
 	service        appointment.Service
 	patientService patient.Service
 	dentistService dentist.Service
+	transactions   transaction.Manager
 	validator      *en_validator.Validator
+	limits         pagination.Limits
 }
 
-// NewHandler is a function to create a handler
-func NewHandler(service appointment.Service, patientService patient.Service, dentistService dentist.Service, validator *en_validator.Validator) *Handler {
+// NewHandler is a function to create a handler,
+// transactions runs the requests using many services or reading and then writing in a unit of work,
+// limits are the default and the maximum size of the pages listed.
+func NewHandler(service appointment.Service, patientService patient.Service, dentistService dentist.Service,
+	transactions transaction.Manager, validator *en_validator.Validator, limits pagination.Limits) *Handler {
 	return &Handler{
 		service:        service,
 		patientService: patientService,
 		dentistService: dentistService,
+		transactions:   transactions,
 		validator:      validator,
+		limits:         limits,
 	}
 }
 
@@ -49,6 +61,8 @@ func NewHandler(service appointment.Service, patientService patient.Service, den
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment [post]
 func (h *Handler) Create() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -74,36 +88,77 @@ func (h *Handler) Create() gin.HandlerFunc {
 
 		app, err := h.service.Create(ctx, request)
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 		web.Success(ctx, http.StatusCreated, app)
 	}
 }
 
-// GetAll is the handler responsible for retrieving all appointments.
+// CreateSeries is the handler responsible for creating a recurring appointment.
+// @Summary Create a recurring appointment
+// @Description Create all the occurrences of a recurring appointment from a recurrence rule with JSON input, either all of them are created or none
+// @Tags appointment
+// @Accept json
+// @Produce json
+// @Param request body appointment.NewAppointmentSeries true "Recurring appointment data"
+// @Success 201 {array} appointment.Appointment
+// @Failure 400 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /appointment/series [post]
+func (h *Handler) CreateSeries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		var request appointment.NewAppointmentSeries
+
+		err := ctx.ShouldBindJSON(&request)
+		if err != nil {
+			web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
+			return
+		}
+
+		err = h.validator.Validate.Struct(request)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		apps, err := h.service.CreateSeries(ctx, request)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, apps)
+	}
+}
+
+// GetAll is the handler responsible for retrieving all appointments, a page at a time.
 // @Summary Get all appointments
-// @Description Get a list of all appointments with optional query parameters
+// @Description Get a page of the appointments, sorted by date, with optional query parameters
 // @Tags appointment
 // @Accept json
 // @Produce json
 // @Param filters query appointment.FilterAppointment false "Optional filters" default({}) Example({"dni":"12345678", "from_date":""2023-09-15 11:30:00""})
+// @Param page query int false "Page number, starting from 1"
+// @Param page_size query int false "Page size, the default and the maximum are configured"
+// @Param cursor query string false "Cursor of the next page, returned by the previous one"
+// @Param sort query string false "Comma separated fields to sort by, descending when prefixed by -"
+// @Param fields query string false "Comma separated fields to return, all of them when it's not set"
 // @Success 200 {array} appointment.Appointment
 // @Failure 400 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment [get]
 func (h *Handler) GetAll() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -132,9 +187,29 @@ func (h *Handler) GetAll() gin.HandlerFunc {
 			return
 		}
 
-		appointments := h.service.GetAll(ctx, filters)
+		page, ok := h.bindPage(ctx)
+		if !ok {
+			return
+		}
+
+		options, ok := h.bindOptions(ctx)
+		if !ok {
+			return
+		}
+
+		appointments, meta, err := h.service.GetAll(ctx, filters, page, options)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		data, err := listing.Select(appointments, options.Fields)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
 
-		web.Success(ctx, http.StatusOK, appointments)
+		web.Page(ctx, http.StatusOK, data, meta)
 	}
 }
 
@@ -149,6 +224,8 @@ func (h *Handler) GetAll() gin.HandlerFunc {
 // @Failure 400 {object} web.errorResponse
 // @Failure 404 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment/{id} [get]
 func (h *Handler) GetByID() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -160,14 +237,8 @@ func (h *Handler) GetByID() gin.HandlerFunc {
 
 		app, err := h.service.GetByID(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, app)
@@ -188,6 +259,8 @@ func (h *Handler) GetByID() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment/{id} [put]
 func (h *Handler) Update() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -221,32 +294,14 @@ func (h *Handler) Update() gin.HandlerFunc {
 
 		_, err = h.service.GetByID(ctx, idInt)
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		app, err := h.service.Update(ctx, idInt, ua)
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 		web.Success(ctx, http.StatusOK, app)
 	}
@@ -254,11 +309,12 @@ func (h *Handler) Update() gin.HandlerFunc {
 
 // Patch is the handler responsible for partially updating an appointment by its ID.
 // @Summary Partially update an appointment by ID
-// @Description Partially update an appointment with JSON input by its unique ID
+// @Description Partially update an appointment with JSON input by its unique ID, and optionally the rest of its series
 // @Tags appointment
 // @Accept json
 // @Produce json
 // @Param id path int true "Appointment ID"
+// @Param scope query string false "Occurrences of the series to update, the following ones or all of them return an array" Enums(this, following, all)
 // @Param request body appointment.PatchAppointment true "Partial update data"
 // @Success 200 {object} appointment.Appointment
 // @Failure 400 {object} web.errorResponse
@@ -266,12 +322,13 @@ func (h *Handler) Update() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment/{id} [patch]
 func (h *Handler) Patch() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
 		var pa appointment.PatchAppointment
-		var app appointment.Appointment
 
 		errBind := ctx.ShouldBindJSON(&pa)
 		if errBind != nil {
@@ -279,6 +336,17 @@ func (h *Handler) Patch() gin.HandlerFunc {
 			return
 		}
 
+		err := h.validator.Validate.Struct(pa)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
 		id := ctx.Param("id")
 		idInt, err := strconv.Atoi(id)
 		if err != nil {
@@ -286,36 +354,36 @@ func (h *Handler) Patch() gin.HandlerFunc {
 			return
 		}
 
-		app, err = h.service.GetByID(ctx, idInt)
+		scope, err := appointment.ParseScope(ctx.Query("scope"))
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			web.Error(ctx, http.StatusBadRequest, "%s", err)
+			return
 		}
 
-		a, err := h.service.Patch(ctx, app, pa)
-		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
+		var apps []appointment.Appointment
+
+		// The appointment is read and patched in a unit of work, so it doesn't change in between.
+		err = h.transactions.Do(ctx, func(ctx context.Context) error {
+			app, err := h.service.GetByID(ctx, idInt)
+			if err != nil {
+				return err
 			}
+
+			apps, err = h.service.PatchSeries(ctx, app, pa, scope)
+
+			return err
+		})
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		if scope == appointment.ScopeThis {
+			web.Success(ctx, http.StatusOK, apps[0])
+			return
 		}
-		web.Success(ctx, http.StatusOK, a)
+
+		web.Success(ctx, http.StatusOK, apps)
 	}
 }
 
@@ -331,6 +399,8 @@ func (h *Handler) Patch() gin.HandlerFunc {
 // @Failure 404 {object} web.errorResponse
 // @Failure 409 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment/{id} [delete]
 func (h *Handler) Delete() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -342,22 +412,153 @@ func (h *Handler) Delete() gin.HandlerFunc {
 
 		err = h.service.Delete(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 		web.Success(ctx, http.StatusNoContent, nil)
 	}
 }
 
+// Cancel is the handler responsible for cancelling an appointment, which is kept for history.
+// @Summary Cancel an appointment
+// @Description Cancel an appointment by its unique ID, only if its current status allows it, and optionally the rest of its series
+// @Tags appointment
+// @Param id path int true "Appointment ID"
+// @Param scope query string false "Occurrences of the series to cancel, the following ones or all of them return an array" Enums(this, following, all)
+// @Accept json
+// @Produce json
+// @Success 200 {object} appointment.Appointment
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /appointment/{id}/cancel [post]
+func (h *Handler) Cancel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		scope, err := appointment.ParseScope(ctx.Query("scope"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", err)
+			return
+		}
+
+		apps, err := h.service.CancelSeries(ctx, id, scope)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		if scope == appointment.ScopeThis {
+			web.Success(ctx, http.StatusOK, apps[0])
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, apps)
+	}
+}
+
+// Confirm is the handler responsible for confirming an appointment.
+// @Summary Confirm an appointment
+// @Description Confirm an appointment by its unique ID, only if its current status allows it
+// @Tags appointment
+// @Param id path int true "Appointment ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} appointment.Appointment
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /appointment/{id}/confirm [post]
+func (h *Handler) Confirm() gin.HandlerFunc {
+	return h.changeStatus(appointment.StatusConfirmed)
+}
+
+// CheckIn is the handler responsible for checking in the patient of an appointment.
+// @Summary Check in an appointment
+// @Description Check in an appointment by its unique ID, only if its current status allows it
+// @Tags appointment
+// @Param id path int true "Appointment ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} appointment.Appointment
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /appointment/{id}/check-in [post]
+func (h *Handler) CheckIn() gin.HandlerFunc {
+	return h.changeStatus(appointment.StatusCheckedIn)
+}
+
+// Complete is the handler responsible for completing an appointment.
+// @Summary Complete an appointment
+// @Description Complete an appointment by its unique ID, only if its current status allows it
+// @Tags appointment
+// @Param id path int true "Appointment ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} appointment.Appointment
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /appointment/{id}/complete [post]
+func (h *Handler) Complete() gin.HandlerFunc {
+	return h.changeStatus(appointment.StatusCompleted)
+}
+
+// NoShow is the handler responsible for marking an appointment as no-show.
+// @Summary Mark an appointment as no-show
+// @Description Mark an appointment as no-show by its unique ID, only if its current status allows it
+// @Tags appointment
+// @Param id path int true "Appointment ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} appointment.Appointment
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /appointment/{id}/no-show [post]
+func (h *Handler) NoShow() gin.HandlerFunc {
+	return h.changeStatus(appointment.StatusNoShow)
+}
+
+// changeStatus builds the handler responsible for moving an appointment to the given status.
+func (h *Handler) changeStatus(status appointment.Status) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		app, err := h.service.ChangeStatus(ctx, id, status)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, app)
+	}
+}
+
 // CreateByDNI is the handler responsible for creating appointments by patient DNI and dentist registration number.
 // @Summary Create an appointment by patient DNI and dentist registration number
 // @Description Create a new appointment with JSON input using patient DNI and dentist registration number
@@ -371,14 +572,13 @@ func (h *Handler) Delete() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /appointment/dni [post]
 func (h *Handler) CreateByDNI() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
 		var request appointment.NewAppointmentDNIRegistrationNumber
-		var app appointment.NewAppointment
-		var pa patient.Patient
-		var de dentist.Dentist
 
 		err := ctx.ShouldBindJSON(&request)
 		if err != nil {
@@ -397,51 +597,86 @@ func (h *Handler) CreateByDNI() gin.HandlerFunc {
 			return
 		}
 
-		pa, err = h.patientService.GetByDNI(ctx, request.PatientDNI)
-		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
+		var newApp appointment.Appointment
+
+		// The dentist and the patient are looked up and booked in a unit of work, so they don't change in between.
+		// The dentist is looked up first, since booking locks it before the patient.
+		err = h.transactions.Do(ctx, func(ctx context.Context) error {
+			de, err := h.dentistService.GetByRegistrationNumber(ctx, request.DentistNumber)
+			if err != nil {
+				return err
 			}
-		}
-		de, err = h.dentistService.GetByRegistrationNumber(ctx, request.DentistNumber)
-		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
+
+			pa, err := h.patientService.GetByDNI(ctx, request.PatientDNI)
+			if err != nil {
+				return err
 			}
-		}
 
-		app.PatientID = pa.ID
-		app.DentistID = de.ID
-		app.Date = request.Date
-		app.Description = request.Description
+			var app appointment.NewAppointment
+			app.PatientID = pa.ID
+			app.DentistID = de.ID
+			app.Date = request.Date
+			app.Duration = request.Duration
+			app.Description = request.Description
 
-		newApp, err := h.service.Create(ctx, app)
+			newApp, err = h.service.Create(ctx, app)
+
+			return err
+		})
 		if err != nil {
-			switch {
-			case errors.Is(err, appointment.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, appointment.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 		web.Success(ctx, http.StatusCreated, newApp)
 	}
 }
+
+// bindPage binds and validates the pagination params of the request, it writes the error response when they're invalid.
+func (h *Handler) bindPage(ctx *gin.Context) (pagination.Request, bool) {
+	var query pagination.Query
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return pagination.Request{}, false
+	}
+
+	err = h.validator.Validate.Struct(query)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		errors.As(err, &validationErrors)
+
+		msg := h.validator.Translate(validationErrors)
+
+		web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+		return pagination.Request{}, false
+	}
+
+	page, err := query.Request(h.limits)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return pagination.Request{}, false
+	}
+
+	return page, true
+}
+
+// bindOptions binds and validates the sorting and the fields of the request, it writes the error response when they're
+// invalid.
+func (h *Handler) bindOptions(ctx *gin.Context) (listing.Options, bool) {
+	var query listing.Query
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return listing.Options{}, false
+	}
+
+	options, err := query.Options(appointment.SortFields, appointment.Fields)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return listing.Options{}, false
+	}
+
+	return options, true
+}
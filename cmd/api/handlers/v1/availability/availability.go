@@ -0,0 +1,166 @@
+package availability
+
+import (
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidID      = errors.New("invalid ID")
+	ErrInternalServer = errors.New("internal server error")
+)
+
+// Handler is a structure for availability handler.
+type Handler struct {
+	appointmentService appointment.Service
+	dentistService     dentist.Service
+	validator          *en_validator.Validator
+}
+
+// NewHandler is a function to create a handler
+func NewHandler(appointmentService appointment.Service, dentistService dentist.Service, validator *en_validator.Validator) *Handler {
+	return &Handler{
+		appointmentService: appointmentService,
+		dentistService:     dentistService,
+		validator:          validator,
+	}
+}
+
+// GetByDentist is the handler responsible for searching the free slots of a dentist.
+// @Summary Get the free slots of a dentist
+// @Description Get the free slots of a dentist between two dates, computed from its working hours minus its appointments
+// @Tags availability
+// @Param id path int true "Dentist ID"
+// @Param filters query appointment.FilterAvailability true "Search window and slot duration in minutes"
+// @Accept json
+// @Produce json
+// @Success 200 {object} appointment.Availability
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/availability [get]
+func (h *Handler) GetByDentist() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		filters, ok := h.bindFilters(ctx)
+		if !ok {
+			return
+		}
+
+		d, err := h.dentistService.GetByID(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		// Inactive dentists can't take appointments, so they have no free slots.
+		if !d.Active {
+			web.Success(ctx, http.StatusOK, appointment.Availability{DentistID: d.ID, Slots: []custom_time.Range{}})
+			return
+		}
+
+		availability, err := h.appointmentService.Availability(ctx, d.ID, filters)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, availability)
+	}
+}
+
+// GetAll is the handler responsible for searching the free slots of all the active dentists.
+// @Summary Get the free slots of all dentists
+// @Description Get the free slots of every active dentist between two dates, dentists without free slots are omitted
+// @Tags availability
+// @Param filters query appointment.FilterAvailability true "Search window and slot duration in minutes"
+// @Accept json
+// @Produce json
+// @Success 200 {array} appointment.Availability
+// @Failure 400 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/availability [get]
+func (h *Handler) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filters, ok := h.bindFilters(ctx)
+		if !ok {
+			return
+		}
+
+		dentists, _, err := h.dentistService.GetAll(ctx, dentist.FilterDentist{}, pagination.All, listing.Default)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		availabilities := make([]appointment.Availability, 0)
+
+		for _, d := range dentists {
+			availability, err := h.appointmentService.Availability(ctx, d.ID, filters)
+			if err != nil {
+				failure.Write(ctx, err)
+				return
+			}
+
+			if len(availability.Slots) > 0 {
+				availabilities = append(availabilities, availability)
+			}
+		}
+
+		web.Success(ctx, http.StatusOK, availabilities)
+	}
+}
+
+// bindFilters binds and validates the search filters from the query.
+// When it fails, the error response is already written and false is returned.
+func (h *Handler) bindFilters(ctx *gin.Context) (appointment.FilterAvailability, bool) {
+	var filters appointment.FilterAvailability
+
+	err := ctx.ShouldBindQuery(&filters)
+	if err != nil {
+		msg := ""
+
+		if strings.Contains(err.Error(), "top-level") {
+			msg = ": If you are using dates via query parameters, please ensure they are wrapped in quotes."
+		}
+
+		web.Error(ctx, http.StatusBadRequest, "%s%s", err, msg)
+		return appointment.FilterAvailability{}, false
+	}
+
+	err = h.validator.Validate.Struct(filters)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		errors.As(err, &validationErrors)
+
+		msg := h.validator.Translate(validationErrors)
+
+		web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+		return appointment.FilterAvailability{}, false
+	}
+
+	return filters, true
+}
@@ -0,0 +1,312 @@
+package calendar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/ical"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/middleware"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// uidDomain is the right side of the UIDs of the events, it must never change or calendar apps will duplicate them.
+	uidDomain = "final-esp-backend-3"
+
+	// history is how long past appointments are kept in the feeds.
+	history = 90 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidID       = errors.New("invalid ID")
+	ErrInternalServer  = errors.New("internal server error")
+	ErrTokensDisabled  = errors.New("calendar feed tokens are disabled, set CALENDAR_SECRET to enable them")
+	ErrUnknownResource = errors.New("unknown calendar resource")
+)
+
+// Feed describes the tokenized URL of a calendar feed.
+type Feed struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// Handler is a structure for calendar handler.
+type Handler struct {
+	appointmentService appointment.Service
+	dentistService     dentist.Service
+	patientService     patient.Service
+	location           *time.Location
+	secret             string
+}
+
+// NewHandler is a function to create a handler.
+// location is the time zone the appointment dates are stored in and secret signs the feed tokens.
+func NewHandler(appointmentService appointment.Service, dentistService dentist.Service, patientService patient.Service, location *time.Location, secret string) *Handler {
+	return &Handler{
+		appointmentService: appointmentService,
+		dentistService:     dentistService,
+		patientService:     patientService,
+		location:           location,
+		secret:             secret,
+	}
+}
+
+// Dentist is the handler responsible for the calendar feed of a dentist.
+// @Summary Get the calendar feed of a dentist
+// @Description Get the appointments of a dentist as an iCalendar (RFC 5545) document, authenticated by the TOKEN header or the token of its feed
+// @Tags calendar
+// @Param id path int true "Dentist ID"
+// @Param token query string false "Feed token"
+// @Produce text/calendar
+// @Success 200 {string} string
+// @Failure 400 {object} web.errorResponse
+// @Failure 401 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/calendar.ics [get]
+func (h *Handler) Dentist() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		d, err := h.dentistService.GetByID(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		appointments, err := h.appointments(ctx, appointment.FilterAppointment{DentistID: &d.ID})
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		filters := patient.FilterPatient{IncludeInactive: true}
+		for _, a := range appointments {
+			filters.IDs = append(filters.IDs, a.PatientID)
+		}
+
+		patients := make(map[int]string)
+
+		if len(filters.IDs) > 0 {
+			list, _, err := h.patientService.GetAll(ctx, filters, pagination.All, listing.Default)
+			if err != nil {
+				web.InternalError(ctx, err, ErrInternalServer)
+				return
+			}
+
+			for _, p := range list {
+				patients[p.ID] = p.FirstName + " " + p.LastName
+			}
+		}
+
+		summary := func(a appointment.Appointment) (string, error) {
+			name, ok := patients[a.PatientID]
+			if !ok {
+				return "", fmt.Errorf("%w: id %d", patient.ErrNotFound, a.PatientID)
+			}
+
+			return "Appointment with " + name, nil
+		}
+
+		name := fmt.Sprintf("%s %s agenda", d.FirstName, d.LastName)
+
+		h.write(ctx, name, appointments, summary)
+	}
+}
+
+// Patient is the handler responsible for the calendar feed of a patient.
+// @Summary Get the calendar feed of a patient
+// @Description Get the appointments of a patient as an iCalendar (RFC 5545) document, authenticated by the TOKEN header or the token of its feed
+// @Tags calendar
+// @Param id path int true "Patient ID"
+// @Param token query string false "Feed token"
+// @Produce text/calendar
+// @Success 200 {string} string
+// @Failure 400 {object} web.errorResponse
+// @Failure 401 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /patient/{id}/calendar.ics [get]
+func (h *Handler) Patient() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		p, err := h.patientService.GetByID(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		appointments, err := h.appointments(ctx, appointment.FilterAppointment{PatientID: &p.ID})
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		filters := dentist.FilterDentist{IncludeInactive: true}
+		for _, a := range appointments {
+			filters.IDs = append(filters.IDs, a.DentistID)
+		}
+
+		dentists := make(map[int]string)
+
+		if len(filters.IDs) > 0 {
+			list, _, err := h.dentistService.GetAll(ctx, filters, pagination.All, listing.Default)
+			if err != nil {
+				web.InternalError(ctx, err, ErrInternalServer)
+				return
+			}
+
+			for _, d := range list {
+				dentists[d.ID] = d.FirstName + " " + d.LastName
+			}
+		}
+
+		summary := func(a appointment.Appointment) (string, error) {
+			name, ok := dentists[a.DentistID]
+			if !ok {
+				return "", fmt.Errorf("%w: id %d", dentist.ErrNotFound, a.DentistID)
+			}
+
+			return "Dental appointment with " + name, nil
+		}
+
+		name := fmt.Sprintf("%s %s appointments", p.FirstName, p.LastName)
+
+		h.write(ctx, name, appointments, summary)
+	}
+}
+
+// Token is the handler responsible for giving the tokenized URL of a calendar feed.
+// @Summary Get the tokenized URL of a calendar feed
+// @Description Get the URL of the calendar feed of a dentist or a patient with a token, so calendar apps can subscribe to it without the TOKEN header
+// @Tags calendar
+// @Param id path int true "Dentist or patient ID"
+// @Produce json
+// @Success 200 {object} Feed
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 501 {object} web.errorResponse
+// @Router /dentist/{id}/calendar-token [get]
+// @Router /patient/{id}/calendar-token [get]
+func (h *Handler) Token(resource string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if h.secret == "" {
+			web.Error(ctx, http.StatusNotImplemented, "%s", ErrTokensDisabled)
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		switch resource {
+		case "dentist":
+			_, err = h.dentistService.GetByID(ctx, id)
+		case "patient":
+			_, err = h.patientService.GetByID(ctx, id)
+		default:
+			err = ErrUnknownResource
+		}
+
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		token := middleware.FeedToken(h.secret, fmt.Sprintf("%s/%d", resource, id))
+
+		web.Success(ctx, http.StatusOK, Feed{
+			Token: token,
+			URL:   fmt.Sprintf("/v1/%s/%d/calendar.ics?token=%s", resource, id, token),
+		})
+	}
+}
+
+// appointments returns the appointments matching the filters, from the last days of history on.
+func (h *Handler) appointments(ctx *gin.Context, filters appointment.FilterAppointment) ([]appointment.Appointment, error) {
+	from := custom_time.Time{Time: time.Now().In(h.location).Add(-history)}
+	filters.FromDate = &from
+
+	appointments, _, err := h.appointmentService.GetAll(ctx, filters, pagination.All, listing.Default)
+
+	return appointments, err
+}
+
+// write renders the appointments as an iCalendar document, summary gives the summary of their events.
+// Every appointment must have one, so a summary error aborts it.
+func (h *Handler) write(ctx *gin.Context, name string, appointments []appointment.Appointment, summary func(appointment.Appointment) (string, error)) {
+	calendar := ical.Calendar{Name: name, Events: make([]ical.Event, 0)}
+
+	for _, a := range appointments {
+		start := h.inLocation(a.Date.Time)
+
+		text, err := summary(a)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("appointment-%d@%s", a.ID, uidDomain),
+			Start:       start,
+			End:         start.Add(time.Duration(a.Duration) * time.Minute),
+			Summary:     text,
+			Description: a.Description,
+			Status:      eventStatus(a.Status),
+		})
+	}
+
+	var body bytes.Buffer
+
+	err := calendar.Encode(&body, time.Now())
+	if err != nil {
+		web.InternalError(ctx, err, ErrInternalServer)
+		return
+	}
+
+	ctx.Data(http.StatusOK, ical.ContentType, body.Bytes())
+}
+
+// inLocation returns the same wall clock of the stored date in the time zone of the clinic.
+func (h *Handler) inLocation(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, h.location)
+}
+
+// eventStatus maps the status of an appointment to the status of its event.
+// Cancelled and missed appointments are kept, so calendar apps remove them.
+func eventStatus(status appointment.Status) ical.Status {
+	switch status {
+	case appointment.StatusScheduled:
+		return ical.StatusTentative
+	case appointment.StatusCancelled, appointment.StatusNoShow:
+		return ical.StatusCancelled
+	default:
+		return ical.StatusConfirmed
+	}
+}
@@ -1,12 +1,18 @@
 package dentist
 
 import (
+	"context"
 	"errors"
 	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
 	"github.com/go-playground/validator/v10"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
 	"github.com/Nachofra/final-esp-backend-3/pkg/web"
 	"github.com/gin-gonic/gin"
@@ -19,15 +25,21 @@ var (
 
 // Handler is a structure for dentist handler.
 type Handler struct {
-	service   dentist.Service
-	validator *en_validator.Validator
+	service      dentist.Service
+	transactions transaction.Manager
+	validator    *en_validator.Validator
+	limits       pagination.Limits
 }
 
-// NewHandler is a function to create a handler
-func NewHandler(service dentist.Service, validator *en_validator.Validator) *Handler {
+// NewHandler is a function to create a handler,
+// transactions runs the requests reading and then writing in a unit of work,
+// limits are the default and the maximum size of the pages listed.
+func NewHandler(service dentist.Service, transactions transaction.Manager, validator *en_validator.Validator, limits pagination.Limits) *Handler {
 	return &Handler{
-		service:   service,
-		validator: validator,
+		service:      service,
+		transactions: transactions,
+		validator:    validator,
+		limits:       limits,
 	}
 }
 
@@ -43,6 +55,8 @@ func NewHandler(service dentist.Service, validator *en_validator.Validator) *Han
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /dentist [post]
 func (h *Handler) Create() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -68,40 +82,83 @@ func (h *Handler) Create() gin.HandlerFunc {
 
 		d, err := h.service.Create(ctx, request)
 		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusCreated, d)
 	}
 }
 
-// GetAll is the handler responsible for retrieving all dentists.
+// GetAll is the handler responsible for retrieving all dentists, a page at a time.
 // @Summary Get all dentists
-// @Description Get a list of all dentists
+// @Description Get a page of the active dentists, sorted by ID, with optional query parameters, inactive ones are only listed when requested
 // @Tags dentist
 // @Accept json
 // @Produce json
+// @Param filters query dentist.FilterDentist false "Optional filters"
+// @Param page query int false "Page number, starting from 1"
+// @Param page_size query int false "Page size, the default and the maximum are configured"
+// @Param cursor query string false "Cursor of the next page, returned by the previous one"
+// @Param sort query string false "Comma separated fields to sort by, descending when prefixed by -"
+// @Param fields query string false "Comma separated fields to return, all of them when it's not set"
 // @Success 200 {array} dentist.Dentist
+// @Failure 400 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /dentist [get]
 func (h *Handler) GetAll() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		d := h.service.GetAll(ctx)
+		var filters dentist.FilterDentist
 
-		web.Success(ctx, http.StatusOK, d)
+		err := ctx.ShouldBindQuery(&filters)
+		if err != nil {
+			msg := ""
+
+			if strings.Contains(err.Error(), "top-level") {
+				msg = ": If you are using dates via query parameters, please ensure they are wrapped in quotes."
+			}
+
+			web.Error(ctx, http.StatusBadRequest, "%s%s", err, msg)
+			return
+		}
+
+		err = h.validator.Validate.Struct(filters)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		page, ok := h.bindPage(ctx)
+		if !ok {
+			return
+		}
+
+		options, ok := h.bindOptions(ctx)
+		if !ok {
+			return
+		}
+
+		d, meta, err := h.service.GetAll(ctx, filters, page, options)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		data, err := listing.Select(d, options.Fields)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		web.Page(ctx, http.StatusOK, data, meta)
 	}
 }
 
@@ -116,6 +173,8 @@ func (h *Handler) GetAll() gin.HandlerFunc {
 // @Failure 400 {object} web.errorResponse
 // @Failure 404 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /dentist/{id} [get]
 func (h *Handler) GetByID() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -127,14 +186,8 @@ func (h *Handler) GetByID() gin.HandlerFunc {
 
 		d, err := h.service.GetByID(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, d)
@@ -155,6 +208,8 @@ func (h *Handler) GetByID() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /dentist/{id} [put]
 func (h *Handler) Update() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -188,32 +243,14 @@ func (h *Handler) Update() gin.HandlerFunc {
 
 		_, err = h.service.GetByID(ctx, idInt)
 		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		d, err := h.service.Update(ctx, request, idInt)
 		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, d)
@@ -232,6 +269,8 @@ func (h *Handler) Update() gin.HandlerFunc {
 // @Failure 404 {object} web.errorResponse
 // @Failure 409 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /dentist/{id} [delete]
 func (h *Handler) Delete() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -243,17 +282,8 @@ func (h *Handler) Delete() gin.HandlerFunc {
 
 		err = h.service.Delete(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusNoContent, nil)
@@ -274,11 +304,12 @@ func (h *Handler) Delete() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /dentist/{id} [patch]
 func (h *Handler) Patch() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
-		var de dentist.Dentist
 		var pd dentist.PatchDentist
 
 		errBind := ctx.ShouldBindJSON(&pd)
@@ -294,36 +325,138 @@ func (h *Handler) Patch() gin.HandlerFunc {
 			return
 		}
 
-		de, err = h.service.GetByID(ctx, idInt)
-		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
+		var d dentist.Dentist
+
+		// The dentist is read and patched in a unit of work, so it doesn't change in between.
+		err = h.transactions.Do(ctx, func(ctx context.Context) error {
+			de, err := h.service.GetByID(ctx, idInt)
+			if err != nil {
+				return err
 			}
-		}
 
-		d, err := h.service.Patch(ctx, de, pd)
+			d, err = h.service.Patch(ctx, de, pd)
+
+			return err
+		})
 		if err != nil {
-			switch {
-			case errors.Is(err, dentist.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, dentist.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, d)
 	}
 }
+
+// Deactivate is the handler responsible for deactivating a dentist, which also cancels all its future appointments.
+// @Summary Deactivate a dentist by ID
+// @Description Deactivate a dentist by its unique ID and cancel all its future appointments
+// @Tags dentist
+// @Param id path int true "Dentist ID"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/deactivate [post]
+func (h *Handler) Deactivate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		err = h.service.Deactivate(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// Reactivate is the handler responsible for reactivating a dentist.
+// @Summary Reactivate a dentist by ID
+// @Description Reactivate a dentist by its unique ID
+// @Tags dentist
+// @Param id path int true "Dentist ID"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/reactivate [post]
+func (h *Handler) Reactivate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		err = h.service.Reactivate(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// bindPage binds and validates the pagination params of the request, it writes the error response when they're invalid.
+func (h *Handler) bindPage(ctx *gin.Context) (pagination.Request, bool) {
+	var query pagination.Query
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return pagination.Request{}, false
+	}
+
+	err = h.validator.Validate.Struct(query)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		errors.As(err, &validationErrors)
+
+		msg := h.validator.Translate(validationErrors)
+
+		web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+		return pagination.Request{}, false
+	}
+
+	page, err := query.Request(h.limits)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return pagination.Request{}, false
+	}
+
+	return page, true
+}
+
+// bindOptions binds and validates the sorting and the fields of the request, it writes the error response when they're
+// invalid.
+func (h *Handler) bindOptions(ctx *gin.Context) (listing.Options, bool) {
+	var query listing.Query
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return listing.Options{}, false
+	}
+
+	options, err := query.Options(dentist.SortFields, dentist.Fields)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return listing.Options{}, false
+	}
+
+	return options, true
+}
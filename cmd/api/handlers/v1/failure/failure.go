@@ -0,0 +1,89 @@
+package failure
+
+import (
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ErrInternalServer is the message of the error responses of the errors the client can't fix.
+var ErrInternalServer = errors.New("internal server error")
+
+// statuses maps the errors of the domains to the status of their responses, every route answers the same error with
+// the same status.
+var statuses = []struct {
+	status int
+	errs   []error
+}{
+	{
+		status: http.StatusBadRequest,
+		errs: []error{
+			pagination.ErrInvalidCursor,
+			appointment.ErrInvalidScope,
+		},
+	},
+	{
+		status: http.StatusNotFound,
+		errs: []error{
+			appointment.ErrNotFound,
+			dentist.ErrNotFound,
+			patient.ErrNotFound,
+			schedule.ErrNotFound,
+			waitlist.ErrNotFound,
+		},
+	},
+	{
+		status: http.StatusConflict,
+		errs: []error{
+			appointment.ErrAlreadyExists,
+			appointment.ErrConflict,
+			appointment.ErrSlotTaken,
+			appointment.ErrInactive,
+			appointment.ErrInvalidTransition,
+			dentist.ErrAlreadyExists,
+			dentist.ErrConflict,
+			patient.ErrAlreadyExists,
+			patient.ErrConflict,
+			schedule.ErrConflict,
+			schedule.ErrOverlap,
+			waitlist.ErrConflict,
+		},
+	},
+	{
+		status: http.StatusUnprocessableEntity,
+		errs: []error{
+			appointment.ErrValueExceeded,
+			appointment.ErrOutsideWorkingHours,
+			appointment.ErrInvalidWindow,
+			appointment.ErrInvalidRecurrence,
+			appointment.ErrSeriesTooLong,
+			dentist.ErrValueExceeded,
+			patient.ErrValueExceeded,
+			patient.ErrInvalidQuery,
+			schedule.ErrInvalidRange,
+			waitlist.ErrInvalidRange,
+		},
+	},
+}
+
+// Write creates the error response of an error returned by the services, with the status of its domain error and its
+// message. Any other error is one the client can't fix, answered by web.InternalError.
+func Write(ctx *gin.Context, err error) {
+	for _, s := range statuses {
+		for _, target := range s.errs {
+			if errors.Is(err, target) {
+				web.Error(ctx, s.status, "%s", err)
+				return
+			}
+		}
+	}
+
+	web.InternalError(ctx, err, ErrInternalServer)
+}
@@ -1,14 +1,20 @@
 package patient
 
 import (
+	"context"
 	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
 	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
 	"github.com/Nachofra/final-esp-backend-3/pkg/web"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -18,15 +24,21 @@ var (
 
 // Handler is a structure for patient handler.
 type Handler struct {
-	service   patient.Service
-	validator *en_validator.Validator
+	service      patient.Service
+	transactions transaction.Manager
+	validator    *en_validator.Validator
+	limits       pagination.Limits
 }
 
-// NewHandler is a function to create a handler
-func NewHandler(service patient.Service, validator *en_validator.Validator) *Handler {
+// NewHandler is a function to create a handler,
+// transactions runs the requests reading and then writing in a unit of work,
+// limits are the default and the maximum size of the pages listed.
+func NewHandler(service patient.Service, transactions transaction.Manager, validator *en_validator.Validator, limits pagination.Limits) *Handler {
 	return &Handler{
-		service:   service,
-		validator: validator,
+		service:      service,
+		transactions: transactions,
+		validator:    validator,
+		limits:       limits,
 	}
 }
 
@@ -42,6 +54,8 @@ func NewHandler(service patient.Service, validator *en_validator.Validator) *Han
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /patient [post]
 func (h *Handler) Create() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -67,40 +81,129 @@ func (h *Handler) Create() gin.HandlerFunc {
 
 		p, err := h.service.Create(ctx, request)
 		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, patient.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, patient.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusCreated, p)
 	}
 }
 
-// GetAll is the handler responsible for retrieving all patients.
+// GetAll is the handler responsible for retrieving all patients, a page at a time.
 // @Summary Get all patients
-// @Description Get a list of all patients
+// @Description Get a page of the active patients, sorted by ID, with optional query parameters, inactive ones are only listed when requested
 // @Tags patient
 // @Accept json
 // @Produce json
+// @Param filters query patient.FilterPatient false "Optional filters"
+// @Param page query int false "Page number, starting from 1"
+// @Param page_size query int false "Page size, the default and the maximum are configured"
+// @Param cursor query string false "Cursor of the next page, returned by the previous one"
+// @Param sort query string false "Comma separated fields to sort by, descending when prefixed by -"
+// @Param fields query string false "Comma separated fields to return, all of them when it's not set"
 // @Success 200 {array} patient.Patient
+// @Failure 400 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /patient [get]
 func (h *Handler) GetAll() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		p := h.service.GetAll(ctx)
+		var filters patient.FilterPatient
 
-		web.Success(ctx, http.StatusOK, p)
+		err := ctx.ShouldBindQuery(&filters)
+		if err != nil {
+			msg := ""
+
+			if strings.Contains(err.Error(), "top-level") {
+				msg = ": If you are using dates via query parameters, please ensure they are wrapped in quotes."
+			}
+
+			web.Error(ctx, http.StatusBadRequest, "%s%s", err, msg)
+			return
+		}
+
+		err = h.validator.Validate.Struct(filters)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		page, ok := h.bindPage(ctx)
+		if !ok {
+			return
+		}
+
+		options, ok := h.bindOptions(ctx)
+		if !ok {
+			return
+		}
+
+		p, meta, err := h.service.GetAll(ctx, filters, page, options)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		data, err := listing.Select(p, options.Fields)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		web.Page(ctx, http.StatusOK, data, meta)
+	}
+}
+
+// Search is the handler responsible for searching patients by an approximate text.
+// @Summary Search patients
+// @Description Search the active patients by first name, last name, address or the start of their DNI, ignoring accents, case and small typos, from the most relevant to the least
+// @Tags patient
+// @Accept json
+// @Produce json
+// @Param q query string true "Text to search"
+// @Param limit query int false "Maximum number of patients found"
+// @Success 200 {array} patient.Match
+// @Failure 400 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /patient/search [get]
+func (h *Handler) Search() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request patient.SearchPatient
+
+		err := ctx.ShouldBindQuery(&request)
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", err)
+			return
+		}
+
+		err = h.validator.Validate.Struct(request)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		matches, err := h.service.Search(ctx, request)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, matches)
 	}
 }
 
@@ -115,6 +218,8 @@ func (h *Handler) GetAll() gin.HandlerFunc {
 // @Failure 400 {object} web.errorResponse
 // @Failure 404 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /patient/{id} [get]
 func (h *Handler) GetByID() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -126,14 +231,8 @@ func (h *Handler) GetByID() gin.HandlerFunc {
 
 		p, err := h.service.GetByID(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, p)
@@ -154,6 +253,8 @@ func (h *Handler) GetByID() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /patient/{id} [put]
 func (h *Handler) Update() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -187,32 +288,14 @@ func (h *Handler) Update() gin.HandlerFunc {
 
 		_, err = h.service.GetByID(ctx, idInt)
 		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		p, err := h.service.Update(ctx, request, idInt)
 		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, patient.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, patient.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, p)
@@ -233,12 +316,13 @@ func (h *Handler) Update() gin.HandlerFunc {
 // @Failure 409 {object} web.errorResponse
 // @Failure 422 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /patient/{id} [patch]
 func (h *Handler) Patch() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
 		var pp patient.PatchPatient
-		var pa patient.Patient
 
 		err := ctx.ShouldBindJSON(&pp)
 		if err != nil {
@@ -264,34 +348,22 @@ func (h *Handler) Patch() gin.HandlerFunc {
 			return
 		}
 
-		pa, err = h.service.GetByID(ctx, idInt)
-		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
+		var p patient.Patient
+
+		// The patient is read and patched in a unit of work, so it doesn't change in between.
+		err = h.transactions.Do(ctx, func(ctx context.Context) error {
+			pa, err := h.service.GetByID(ctx, idInt)
+			if err != nil {
+				return err
 			}
-		}
 
-		p, err := h.service.Patch(ctx, pa, pp)
+			p, err = h.service.Patch(ctx, pa, pp)
+
+			return err
+		})
 		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrAlreadyExists):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, patient.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			case errors.Is(err, patient.ErrValueExceeded):
-				web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusOK, p)
@@ -310,6 +382,8 @@ func (h *Handler) Patch() gin.HandlerFunc {
 // @Failure 404 {object} web.errorResponse
 // @Failure 409 {object} web.errorResponse
 // @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
 // @Router /patient/{id} [delete]
 func (h *Handler) Delete() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -321,19 +395,124 @@ func (h *Handler) Delete() gin.HandlerFunc {
 
 		err = h.service.Delete(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, patient.ErrNotFound):
-				web.Error(ctx, http.StatusNotFound, "%s", err)
-				return
-			case errors.Is(err, patient.ErrConflict):
-				web.Error(ctx, http.StatusConflict, "%s", err)
-				return
-			default:
-				web.Error(ctx, http.StatusInternalServerError, "%s", ErrInternalServer)
-				return
-			}
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// Deactivate is the handler responsible for deactivating a patient, which also cancels all its future appointments.
+// @Summary Deactivate a patient by ID
+// @Description Deactivate a patient by its unique ID and cancel all its future appointments
+// @Tags patient
+// @Param id path int true "Patient ID"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /patient/{id}/deactivate [post]
+func (h *Handler) Deactivate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		err = h.service.Deactivate(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// Reactivate is the handler responsible for reactivating a patient.
+// @Summary Reactivate a patient by ID
+// @Description Reactivate a patient by its unique ID
+// @Tags patient
+// @Param id path int true "Patient ID"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /patient/{id}/reactivate [post]
+func (h *Handler) Reactivate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		err = h.service.Reactivate(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
 		}
 
 		web.Success(ctx, http.StatusNoContent, nil)
 	}
 }
+
+// bindPage binds and validates the pagination params of the request, it writes the error response when they're invalid.
+func (h *Handler) bindPage(ctx *gin.Context) (pagination.Request, bool) {
+	var query pagination.Query
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return pagination.Request{}, false
+	}
+
+	err = h.validator.Validate.Struct(query)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		errors.As(err, &validationErrors)
+
+		msg := h.validator.Translate(validationErrors)
+
+		web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+		return pagination.Request{}, false
+	}
+
+	page, err := query.Request(h.limits)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return pagination.Request{}, false
+	}
+
+	return page, true
+}
+
+// bindOptions binds and validates the sorting and the fields of the request, it writes the error response when they're
+// invalid.
+func (h *Handler) bindOptions(ctx *gin.Context) (listing.Options, bool) {
+	var query listing.Query
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return listing.Options{}, false
+	}
+
+	options, err := query.Options(patient.SortFields, patient.Fields)
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", err)
+		return listing.Options{}, false
+	}
+
+	return options, true
+}
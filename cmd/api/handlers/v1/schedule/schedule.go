@@ -0,0 +1,275 @@
+package schedule
+
+import (
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/Nachofra/final-esp-backend-3/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidID      = errors.New("invalid ID")
+	ErrInternalServer = errors.New("internal server error")
+)
+
+// Handler is a structure for schedule handler.
+type Handler struct {
+	service        schedule.Service
+	dentistService dentist.Service
+	validator      *en_validator.Validator
+}
+
+// NewHandler is a function to create a handler
+func NewHandler(service schedule.Service, dentistService dentist.Service, validator *en_validator.Validator) *Handler {
+	return &Handler{
+		service:        service,
+		dentistService: dentistService,
+		validator:      validator,
+	}
+}
+
+// GetAll is the handler responsible for retrieving the weekly schedule of a dentist.
+// @Summary Get the schedule of a dentist
+// @Description Get all the weekly shifts of a dentist
+// @Tags schedule
+// @Param id path int true "Dentist ID"
+// @Accept json
+// @Produce json
+// @Success 200 {array} schedule.Shift
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/schedule [get]
+func (h *Handler) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dentistID, ok := h.dentistID(ctx)
+		if !ok {
+			return
+		}
+
+		shifts, err := h.service.GetByDentist(ctx, dentistID)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, shifts)
+	}
+}
+
+// GetByID is the handler responsible for retrieving a shift of a dentist by its ID.
+// @Summary Get a shift by ID
+// @Description Get a shift of a dentist by its unique ID
+// @Tags schedule
+// @Param id path int true "Dentist ID"
+// @Param shift_id path int true "Shift ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} schedule.Shift
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/schedule/{shift_id} [get]
+func (h *Handler) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dentistID, ok := h.dentistID(ctx)
+		if !ok {
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("shift_id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		shift, err := h.service.GetByID(ctx, dentistID, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, shift)
+	}
+}
+
+// Create is the handler responsible for adding a shift to the schedule of a dentist.
+// @Summary Add a shift to the schedule of a dentist
+// @Description Add a weekly shift to the schedule of a dentist with JSON input
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Dentist ID"
+// @Param request body schedule.NewShift true "Shift data"
+// @Success 201 {object} schedule.Shift
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/schedule [post]
+func (h *Handler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		var request schedule.NewShift
+
+		err := ctx.ShouldBindJSON(&request)
+		if err != nil {
+			web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
+			return
+		}
+
+		err = h.validator.Validate.Struct(request)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		dentistID, ok := h.dentistID(ctx)
+		if !ok {
+			return
+		}
+
+		shift, err := h.service.Create(ctx, dentistID, request)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusCreated, shift)
+	}
+}
+
+// Update is the handler responsible for updating a shift of a dentist by its ID.
+// @Summary Update a shift by ID
+// @Description Update a shift of a dentist with JSON input by its unique ID
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Dentist ID"
+// @Param shift_id path int true "Shift ID"
+// @Param request body schedule.NewShift true "Updated shift data"
+// @Success 200 {object} schedule.Shift
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/schedule/{shift_id} [put]
+func (h *Handler) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		var request schedule.NewShift
+
+		err := ctx.ShouldBindJSON(&request)
+		if err != nil {
+			web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
+			return
+		}
+
+		err = h.validator.Validate.Struct(request)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		dentistID, ok := h.dentistID(ctx)
+		if !ok {
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("shift_id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		shift, err := h.service.Update(ctx, dentistID, id, request)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, shift)
+	}
+}
+
+// Delete is the handler responsible for removing a shift from the schedule of a dentist.
+// @Summary Delete a shift by ID
+// @Description Delete a shift of a dentist by its unique ID
+// @Tags schedule
+// @Param id path int true "Dentist ID"
+// @Param shift_id path int true "Shift ID"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /dentist/{id}/schedule/{shift_id} [delete]
+func (h *Handler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dentistID, ok := h.dentistID(ctx)
+		if !ok {
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("shift_id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		err = h.service.Delete(ctx, dentistID, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// dentistID parses the dentist ID from the path and checks that the dentist exists.
+// When it fails, the error response is already written and false is returned.
+func (h *Handler) dentistID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+		return 0, false
+	}
+
+	_, err = h.dentistService.GetByID(ctx, id)
+	if err != nil {
+		failure.Write(ctx, err)
+		return 0, false
+	}
+
+	return id, true
+}
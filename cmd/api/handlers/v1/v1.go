@@ -3,18 +3,25 @@ package v1
 import (
 	"database/sql"
 	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/health"
 	handlerAppointment "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/apointment"
+	handlerAvailability "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/availability"
+	handlerCalendar "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/calendar"
 	handlerDentist "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/dentist"
 	handlerPatient "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/patient"
+	handlerSchedule "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/schedule"
+	handlerWaitlist "github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/stores"
 	"github.com/Nachofra/final-esp-backend-3/docs"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
-	mysqlAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/mysql"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
-	mysqlDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/mysql"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
-	mysqlPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
 	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
 	"github.com/Nachofra/final-esp-backend-3/pkg/middleware"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -23,11 +30,15 @@ import (
 )
 
 // Config has all the dependencies and requirements to initialize handlers.
+// DB is used by the MySQL, PostgreSQL and SQLite stores and Memory by the memory ones, only the one of the store
+// configured is needed.
 type Config struct {
 	Log       *log.Logger
 	DB        *sql.DB
+	Memory    *memory.DB
 	Validator *en_validator.Validator
 	Env       *config.Config
+	Build     health.Build
 }
 
 // Routes sets all the version 1 routes.
@@ -39,50 +50,102 @@ func Routes(eng *gin.Engine, cfg Config) {
 		c.JSON(http.StatusOK, "pong")
 	})
 
+	// Probes and build metadata, out of the version prefix like ping
+	healthHandler := health.NewHandler(cfg.DB, cfg.Env.Store, cfg.Env.HealthTimeout, cfg.Build)
+	h := eng.Group("/health")
+	{
+		h.GET("/live", healthHandler.Live())
+		h.GET("/ready", healthHandler.Ready())
+	}
+	eng.GET("/version", healthHandler.Version())
+
 	const prefix = "/v1"
 	v1 := eng.Group(prefix)
 
-	repoDentist := mysqlDentist.New(cfg.DB)
-	dentistService := dentist.NewService(repoDentist)
+	repo := stores.New(cfg.Env, cfg.DB, cfg.Memory)
 
-	repoPatient := mysqlPatient.NewStore(cfg.DB)
-	patientService := patient.NewService(repoPatient)
+	scheduleService := schedule.NewService(repo.Schedule)
+	waitlistService := waitlist.NewService(repo.Waitlist)
+	appointmentService := appointment.NewService(repo.Appointment, repo.Transaction, scheduleService, waitlistService, cfg.Env.AppointmentDuration)
+	dentistService := dentist.NewService(repo.Dentist, repo.Transaction, appointmentService, waitlistService)
+	patientService := patient.NewService(repo.Patient, repo.Transaction, appointmentService, waitlistService)
 
-	repoAppointment := mysqlAppointment.NewStore(cfg.DB)
-	appointmentService := appointment.NewService(repoAppointment)
+	limits := pagination.Limits{Default: cfg.Env.PageSize, Max: cfg.Env.MaxPageSize}
 
-	dentistHandler := handlerDentist.NewHandler(dentistService, cfg.Validator)
+	dentistHandler := handlerDentist.NewHandler(dentistService, repo.Transaction, cfg.Validator, limits)
+	availabilityHandler := handlerAvailability.NewHandler(appointmentService, dentistService, cfg.Validator)
+	calendarHandler := handlerCalendar.NewHandler(appointmentService, dentistService, patientService,
+		cfg.Env.CalendarLocation, cfg.Env.CalendarSecret)
 	d := v1.Group("/dentist")
 	{
 		d.GET("/:id", dentistHandler.GetByID())
 		d.GET("", dentistHandler.GetAll())
+		d.GET("/availability", availabilityHandler.GetAll())
+		d.GET("/:id/availability", availabilityHandler.GetByDentist())
+		d.GET("/:id/calendar.ics", middleware.AuthenticateFeed(cfg.Env.CalendarSecret, "dentist"), calendarHandler.Dentist())
+		d.GET("/:id/calendar-token", middleware.Authenticate(), calendarHandler.Token("dentist"))
 		d.POST("/", middleware.Authenticate(), dentistHandler.Create())
 		d.PUT("/:id", middleware.Authenticate(), dentistHandler.Update())
 		d.PATCH("/:id", middleware.Authenticate(), dentistHandler.Patch())
 		d.DELETE("/:id", middleware.Authenticate(), dentistHandler.Delete())
+		d.POST("/:id/deactivate", middleware.Authenticate(), dentistHandler.Deactivate())
+		d.POST("/:id/reactivate", middleware.Authenticate(), dentistHandler.Reactivate())
 	}
 
-	patientHandler := handlerPatient.NewHandler(patientService, cfg.Validator)
+	scheduleHandler := handlerSchedule.NewHandler(scheduleService, dentistService, cfg.Validator)
+	sc := d.Group("/:id/schedule")
+	{
+		sc.GET("", scheduleHandler.GetAll())
+		sc.GET("/:shift_id", scheduleHandler.GetByID())
+		sc.POST("", middleware.Authenticate(), scheduleHandler.Create())
+		sc.PUT("/:shift_id", middleware.Authenticate(), scheduleHandler.Update())
+		sc.DELETE("/:shift_id", middleware.Authenticate(), scheduleHandler.Delete())
+	}
+
+	patientHandler := handlerPatient.NewHandler(patientService, repo.Transaction, cfg.Validator, limits)
 	p := v1.Group("/patient")
 	{
 		p.GET("/:id", patientHandler.GetByID())
 		p.GET("/", patientHandler.GetAll())
+		p.GET("/search", patientHandler.Search())
+		p.GET("/:id/calendar.ics", middleware.AuthenticateFeed(cfg.Env.CalendarSecret, "patient"), calendarHandler.Patient())
+		p.GET("/:id/calendar-token", middleware.Authenticate(), calendarHandler.Token("patient"))
 		p.POST("/", middleware.Authenticate(), patientHandler.Create())
 		p.PUT("/:id", middleware.Authenticate(), patientHandler.Update())
 		p.PATCH("/:id", middleware.Authenticate(), patientHandler.Patch())
 		p.DELETE("/:id", middleware.Authenticate(), patientHandler.Delete())
+		p.POST("/:id/deactivate", middleware.Authenticate(), patientHandler.Deactivate())
+		p.POST("/:id/reactivate", middleware.Authenticate(), patientHandler.Reactivate())
 	}
 
-	appointmentHandler := handlerAppointment.NewHandler(appointmentService, patientService, dentistService, cfg.Validator)
+	appointmentHandler := handlerAppointment.NewHandler(appointmentService, patientService, dentistService, repo.Transaction,
+		cfg.Validator, limits)
 	a := v1.Group("/appointment")
 	{
 		a.GET("/:id", appointmentHandler.GetByID())
 		a.GET("/", appointmentHandler.GetAll())
 		a.POST("/", middleware.Authenticate(), appointmentHandler.Create())
 		a.POST("/dni", middleware.Authenticate(), appointmentHandler.CreateByDNI())
+		a.POST("/series", middleware.Authenticate(), appointmentHandler.CreateSeries())
 		a.PUT("/:id", middleware.Authenticate(), appointmentHandler.Update())
 		a.PATCH("/:id", middleware.Authenticate(), appointmentHandler.Patch())
 		a.DELETE("/:id", middleware.Authenticate(), appointmentHandler.Delete())
+		a.POST("/:id/cancel", middleware.Authenticate(), appointmentHandler.Cancel())
+		a.POST("/:id/confirm", middleware.Authenticate(), appointmentHandler.Confirm())
+		a.POST("/:id/check-in", middleware.Authenticate(), appointmentHandler.CheckIn())
+		a.POST("/:id/complete", middleware.Authenticate(), appointmentHandler.Complete())
+		a.POST("/:id/no-show", middleware.Authenticate(), appointmentHandler.NoShow())
+	}
+
+	waitlistHandler := handlerWaitlist.NewHandler(waitlistService, cfg.Validator)
+	w := v1.Group("/waitlist")
+	{
+		w.GET("", waitlistHandler.GetAll())
+		w.GET("/:id", waitlistHandler.GetByID())
+		w.GET("/:id/offers", waitlistHandler.GetOffers())
+		w.POST("", middleware.Authenticate(), waitlistHandler.Create())
+		w.PUT("/:id", middleware.Authenticate(), waitlistHandler.Update())
+		w.DELETE("/:id", middleware.Authenticate(), waitlistHandler.Delete())
 	}
 
 	docs.SwaggerInfo.Host = cfg.Env.Host + ":" + cfg.Env.Port
@@ -0,0 +1,252 @@
+package waitlist
+
+import (
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1/failure"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/Nachofra/final-esp-backend-3/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidID      = errors.New("invalid ID")
+	ErrInternalServer = errors.New("internal server error")
+)
+
+// Handler is a structure for waitlist handler.
+type Handler struct {
+	service   waitlist.Service
+	validator *en_validator.Validator
+}
+
+// NewHandler is a function to create a handler
+func NewHandler(service waitlist.Service, validator *en_validator.Validator) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+// GetAll is the handler responsible for retrieving all the waitlist entries.
+// @Summary Get all waitlist entries
+// @Description Get a list of all the patients waiting for a free slot
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Success 200 {array} waitlist.Entry
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /waitlist [get]
+func (h *Handler) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		entries, err := h.service.GetAll(ctx)
+		if err != nil {
+			web.InternalError(ctx, err, ErrInternalServer)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, entries)
+	}
+}
+
+// GetByID is the handler responsible for retrieving a waitlist entry by its ID.
+// @Summary Get a waitlist entry by ID
+// @Description Get a waitlist entry by its unique ID
+// @Tags waitlist
+// @Param id path int true "Entry ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} waitlist.Entry
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /waitlist/{id} [get]
+func (h *Handler) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		entry, err := h.service.GetByID(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, entry)
+	}
+}
+
+// Create is the handler responsible for adding a patient to the waitlist.
+// @Summary Add a patient to the waitlist
+// @Description Add a patient to the waitlist of a dentist, or of any dentist, with JSON input
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param request body waitlist.NewEntry true "Entry data"
+// @Success 201 {object} waitlist.Entry
+// @Failure 409 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /waitlist [post]
+func (h *Handler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		var request waitlist.NewEntry
+
+		err := ctx.ShouldBindJSON(&request)
+		if err != nil {
+			web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
+			return
+		}
+
+		err = h.validator.Validate.Struct(request)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		entry, err := h.service.Create(ctx, request)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusCreated, entry)
+	}
+}
+
+// Update is the handler responsible for updating a waitlist entry by its ID.
+// @Summary Update a waitlist entry by ID
+// @Description Update a waitlist entry with JSON input by its unique ID, the entry goes back to waiting for a slot
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Param request body waitlist.NewEntry true "Updated entry data"
+// @Success 200 {object} waitlist.Entry
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 409 {object} web.errorResponse
+// @Failure 422 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /waitlist/{id} [put]
+func (h *Handler) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		var request waitlist.NewEntry
+
+		err := ctx.ShouldBindJSON(&request)
+		if err != nil {
+			web.Error(ctx, http.StatusUnprocessableEntity, "%s", err)
+			return
+		}
+
+		err = h.validator.Validate.Struct(request)
+		if err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+
+			msg := h.validator.Translate(validationErrors)
+
+			web.Error(ctx, http.StatusUnprocessableEntity, "%v", msg)
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		entry, err := h.service.Update(ctx, id, request)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, entry)
+	}
+}
+
+// Delete is the handler responsible for removing a patient from the waitlist.
+// @Summary Delete a waitlist entry by ID
+// @Description Delete a waitlist entry, and its offers, by its unique ID
+// @Tags waitlist
+// @Param id path int true "Entry ID"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /waitlist/{id} [delete]
+func (h *Handler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		err = h.service.Delete(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// GetOffers is the handler responsible for retrieving the slots offered to a waitlist entry.
+// @Summary Get the offers of a waitlist entry
+// @Description Get the freed slots offered to a waitlist entry by its unique ID
+// @Tags waitlist
+// @Param id path int true "Entry ID"
+// @Accept json
+// @Produce json
+// @Success 200 {array} waitlist.Offer
+// @Failure 400 {object} web.errorResponse
+// @Failure 404 {object} web.errorResponse
+// @Failure 500 {object} web.errorResponse
+// @Failure 503 {object} web.errorResponse
+// @Failure 504 {object} web.errorResponse
+// @Router /waitlist/{id}/offers [get]
+func (h *Handler) GetOffers() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Error(ctx, http.StatusBadRequest, "%s", ErrInvalidID)
+			return
+		}
+
+		offers, err := h.service.GetOffers(ctx, id)
+		if err != nil {
+			failure.Write(ctx, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, offers)
+	}
+}
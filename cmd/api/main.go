@@ -1,15 +1,30 @@
 package main
 
 import (
+	"context"
 	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/database"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/health"
 	"github.com/Nachofra/final-esp-backend-3/cmd/api/handlers/v1"
-	"github.com/Nachofra/final-esp-backend-3/pkg/db/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
 	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
 	"github.com/Nachofra/final-esp-backend-3/pkg/middleware"
 	"github.com/gin-gonic/gin"
-	_ "github.com/go-sql-driver/mysql"
 	"log"
 	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// version, commit and date are the build metadata answered by /version, they're set at link time:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%FT%TZ)" ./cmd/api
+//
+// When they aren't, the commit and date are taken from the version control info Go embeds in the binary, if any.
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
 )
 
 // @title Final Backend Specialization 3
@@ -22,33 +37,78 @@ func main() {
 		panic(err)
 	}
 
-	database, err := mysql.Open(mysql.New(
-		mysql.WithUsername(cfg.DBUser),
-		mysql.WithPassword(cfg.DBPassword),
-		mysql.WithHost(cfg.DBHost+":"+cfg.DBPort),
-		mysql.WithName(cfg.DBSchema),
-		mysql.WithCharset(cfg.DBCharset),
-		mysql.WithParseTime(cfg.DBParseTime),
-	))
+	var memoryDB *memory.DB
+	if cfg.Store == config.StoreMemory {
+		memoryDB = memory.New()
+	}
+
+	db, err := database.Open(cfg)
 	if err != nil {
 		panic(err)
 	}
 
+	if db != nil && cfg.Migrate {
+		err = database.Migrate(context.Background(), cfg, db)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	eng := gin.New()
-	eng.Use(middleware.Logger())
+
+	// Handlers pass their gin.Context to the services, with the fallback it's done when the request is, so the stores
+	// give up on the database once the deadline set by the middleware is reached.
+	eng.ContextWithFallback = true
+	eng.Use(middleware.Logger(), middleware.Deadline(cfg.DBTimeout))
 
 	logger := log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime)
 	validator := en_validator.Get()
 
 	v1.Routes(eng, v1.Config{
 		Log:       logger,
-		DB:        database,
+		DB:        db,
+		Memory:    memoryDB,
 		Validator: validator,
 		Env:       cfg,
+		Build:     build(),
 	})
 
+	// The tables of the memory store are created with the stores, so it's seeded once the routes are set.
+	if memoryDB != nil && cfg.StoreSeed != "" {
+		err = memoryDB.LoadFile(cfg.StoreSeed)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	err = eng.Run(cfg.Host + ":" + cfg.Port)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// build returns the build metadata of the app.
+func build() health.Build {
+	b := health.Build{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+
+	for _, setting := range info.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && b.Commit == "":
+			b.Commit = setting.Value
+		case setting.Key == "vcs.time" && b.Date == "":
+			b.Date = setting.Value
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,99 @@
+package stores
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	memoryAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/memory"
+	mysqlAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/mysql"
+	postgresAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/postgres"
+	sqliteAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	memoryDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/memory"
+	mysqlDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/mysql"
+	postgresDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/postgres"
+	sqliteDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	memoryPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/memory"
+	mysqlPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/mysql"
+	postgresPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/postgres"
+	sqlitePatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	memorySchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/memory"
+	mysqlSchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/mysql"
+	postgresSchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/postgres"
+	sqliteSchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	memoryWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/memory"
+	mysqlWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/mysql"
+	postgresWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/postgres"
+	sqliteWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/postgres"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+)
+
+// Stores has the stores of every domain, and the manager of the units of work they take part in.
+type Stores struct {
+	Dentist     dentist.Store
+	Patient     patient.Store
+	Schedule    schedule.Store
+	Waitlist    waitlist.Store
+	Appointment appointment.Store
+	Transaction transaction.Manager
+}
+
+// New creates the stores of every domain, in the store configured.
+// db is used by the MySQL, PostgreSQL and SQLite stores and memoryDB by the memory ones, only the one of the store
+// configured is needed.
+func New(cfg *config.Config, db *sql.DB, memoryDB *memory.DB) Stores {
+	switch cfg.Store {
+	case config.StoreMemory:
+		return Stores{
+			Dentist:     memoryDentist.NewStore(memoryDB),
+			Patient:     memoryPatient.NewStore(memoryDB),
+			Schedule:    memorySchedule.NewStore(memoryDB),
+			Waitlist:    memoryWaitlist.NewStore(memoryDB),
+			Appointment: memoryAppointment.NewStore(memoryDB),
+			Transaction: transaction.None,
+		}
+	case config.StorePostgres:
+		return Stores{
+			Dentist:     postgresDentist.NewStore(db),
+			Patient:     postgresPatient.NewStore(db),
+			Schedule:    postgresSchedule.NewStore(db),
+			Waitlist:    postgresWaitlist.NewStore(db),
+			Appointment: postgresAppointment.NewStore(db),
+			Transaction: transaction.New(db, sql.LevelReadCommitted, deadlock(postgres.CheckError)),
+		}
+	case config.StoreSQLite:
+		return Stores{
+			Dentist:     sqliteDentist.NewStore(db),
+			Patient:     sqlitePatient.NewStore(db),
+			Schedule:    sqliteSchedule.NewStore(db),
+			Waitlist:    sqliteWaitlist.NewStore(db),
+			Appointment: sqliteAppointment.NewStore(db),
+			// SQLite runs a transaction at a time, so every one sees the ones committed before it and it has no
+			// deadlocks to run the units of work again.
+			Transaction: transaction.New(db, sql.LevelDefault, nil),
+		}
+	default:
+		return Stores{
+			Dentist:     mysqlDentist.New(db),
+			Patient:     mysqlPatient.NewStore(db),
+			Schedule:    mysqlSchedule.NewStore(db),
+			Waitlist:    mysqlWaitlist.NewStore(db),
+			Appointment: mysqlAppointment.NewStore(db),
+			Transaction: transaction.New(db, sql.LevelReadCommitted, deadlock(mysql.CheckError)),
+		}
+	}
+}
+
+// deadlock returns whether an error, once checked by checkError, is a deadlock, so the unit of work is run again.
+func deadlock(checkError func(err error) error) func(err error) bool {
+	return func(err error) bool {
+		return errors.Is(checkError(err), mysql.ErrDBDeadlock)
+	}
+}
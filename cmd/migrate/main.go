@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/database"
+	"os"
+)
+
+// usage is how the command is run.
+const usage = "usage: migrate up|down|status"
+
+// migrate applies, reverts or lists the migrations of the database of the store configured, with the same environment
+// variables as the app:
+//
+//	migrate up      applies the pending migrations
+//	migrate down    reverts the newest migration applied
+//	migrate status  lists the migrations and whether they're applied
+func main() {
+	if len(os.Args) != 2 {
+		fail(usage)
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		fail(err)
+	}
+
+	if cfg.Store == config.StoreMemory {
+		fail("the memory store has no migrations")
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	migrator, err := database.Migrator(cfg, db)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+
+		if err != nil {
+			fail(err)
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			fail(err)
+		}
+
+		fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			fail(err)
+		}
+
+		for _, s := range status {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+	default:
+		fail(usage)
+	}
+}
+
+// fail prints the error and exits with a non zero status.
+func fail(err any) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
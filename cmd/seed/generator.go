@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"github.com/go-playground/validator/v10"
+	"math/rand"
+	"time"
+)
+
+const (
+	// minDNI and maxDNI are the DNIs NewPatient accepts.
+	minDNI = 100000
+	maxDNI = 99999999
+
+	// minRegistrationNumber and maxRegistrationNumber are the registration numbers given to dentists.
+	minRegistrationNumber = 10000
+	maxRegistrationNumber = 999999
+
+	// maxAttempts is how many times a random value is generated again when it's taken.
+	maxAttempts = 20
+)
+
+var (
+	firstNames = []string{"María", "José", "Lucía", "Juan", "Sofía", "Martín", "Valentina", "Nicolás", "Camila", "Tomás",
+		"Martina", "Santiago", "Julieta", "Mateo", "Agustina", "Facundo", "Florencia", "Joaquín", "Victoria", "Ignacio",
+		"Paula", "Gonzalo", "Carolina", "Franco", "Ana", "Matías", "Belén", "Federico", "Rocío", "Andrés"}
+
+	lastNames = []string{"González", "Rodríguez", "Gómez", "Fernández", "López", "Díaz", "Martínez", "Pérez", "García",
+		"Sánchez", "Romero", "Sosa", "Torres", "Álvarez", "Ruiz", "Ramírez", "Flores", "Benítez", "Acosta", "Medina",
+		"Herrera", "Suárez", "Aguirre", "Giménez", "Gutiérrez", "Pereyra", "Molina", "Castro", "Ortiz", "Silva"}
+
+	streets = []string{"Av. Corrientes", "Av. Santa Fe", "Av. Rivadavia", "Belgrano", "San Martín", "Mitre",
+		"Sarmiento", "Moreno", "Av. Colón", "Bv. San Juan", "Lavalle", "Tucumán", "Av. Pellegrini", "Italia", "España"}
+
+	descriptions = []string{"Checkup", "Cleaning", "Filling", "Root canal", "Extraction", "Whitening",
+		"Orthodontic adjustment", "Crown fitting", "X-ray", "Gum treatment"}
+
+	// workingHours are the shifts dentists work on their working days, the first ones work mornings, the second ones
+	// afternoons and the last ones both.
+	workingHours = [][][2]string{
+		{{"08:00", "12:00"}},
+		{{"14:00", "19:00"}},
+		{{"09:00", "13:00"}, {"14:00", "18:00"}},
+	}
+)
+
+// generator creates random data through the domain services, the same rand generates the same data.
+type generator struct {
+	rand         *rand.Rand
+	validator    *en_validator.Validator
+	dentists     dentist.Service
+	patients     patient.Service
+	schedule     schedule.Service
+	appointments appointment.Service
+	duration     time.Duration
+}
+
+// createDentists creates n dentists with unique registration numbers, and the weekly schedule of each one.
+func (g *generator) createDentists(ctx context.Context, n int) ([]dentist.Dentist, []schedule.Shift, error) {
+	dentists := make([]dentist.Dentist, 0, n)
+	shifts := make([]schedule.Shift, 0)
+	used := make(map[int]bool)
+
+	for len(dentists) < n {
+		d, err := g.createDentist(ctx, used)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dentists = append(dentists, d)
+
+		dentistShifts, err := g.createSchedule(ctx, d.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		shifts = append(shifts, dentistShifts...)
+	}
+
+	return dentists, shifts, nil
+}
+
+// createDentist creates a dentist with a registration number not used yet, neither by this generator nor the store.
+func (g *generator) createDentist(ctx context.Context, used map[int]bool) (dentist.Dentist, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		nd := dentist.NewDentist{
+			FirstName:          g.pick(firstNames),
+			LastName:           g.pick(lastNames),
+			RegistrationNumber: g.between(minRegistrationNumber, maxRegistrationNumber),
+		}
+
+		if used[nd.RegistrationNumber] {
+			continue
+		}
+
+		used[nd.RegistrationNumber] = true
+
+		err := g.validate(nd)
+		if err != nil {
+			return dentist.Dentist{}, err
+		}
+
+		d, err := g.dentists.Create(ctx, nd)
+		if errors.Is(err, dentist.ErrAlreadyExists) {
+			continue
+		}
+
+		if err != nil {
+			return dentist.Dentist{}, err
+		}
+
+		return d, nil
+	}
+
+	return dentist.Dentist{}, errors.New("no free registration number found, too many dentists")
+}
+
+// createSchedule creates the shifts of a dentist, the same working hours from three to six days a week, Monday to
+// Saturday.
+func (g *generator) createSchedule(ctx context.Context, dentistID int) ([]schedule.Shift, error) {
+	hours := workingHours[g.rand.Intn(len(workingHours))]
+	days := 3 + g.rand.Intn(4)
+	weekdays := g.rand.Perm(6)[:days]
+
+	shifts := make([]schedule.Shift, 0, days*len(hours))
+
+	for _, weekday := range weekdays {
+		weekday := weekday + 1
+
+		for _, h := range hours {
+			ns := schedule.NewShift{
+				Weekday:   &weekday,
+				StartTime: h[0],
+				EndTime:   h[1],
+			}
+
+			err := g.validate(ns)
+			if err != nil {
+				return nil, err
+			}
+
+			sh, err := g.schedule.Create(ctx, dentistID, ns)
+			if err != nil {
+				return nil, err
+			}
+
+			shifts = append(shifts, sh)
+		}
+	}
+
+	return shifts, nil
+}
+
+// createPatients creates n patients with unique DNIs, discharged during the two years before from.
+func (g *generator) createPatients(ctx context.Context, n int, from time.Time) ([]patient.Patient, error) {
+	patients := make([]patient.Patient, 0, n)
+	used := make(map[int]bool)
+
+	for len(patients) < n {
+		p, err := g.createPatient(ctx, used, from)
+		if err != nil {
+			return nil, err
+		}
+
+		patients = append(patients, p)
+	}
+
+	return patients, nil
+}
+
+// createPatient creates a patient with a DNI not used yet, neither by this generator nor the store.
+func (g *generator) createPatient(ctx context.Context, used map[int]bool, from time.Time) (patient.Patient, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		discharge := from.AddDate(0, 0, -1-g.rand.Intn(730)).Add(time.Duration(8+g.rand.Intn(10)) * time.Hour)
+
+		np := patient.NewPatient{
+			FirstName:     g.pick(firstNames),
+			LastName:      g.pick(lastNames) + " " + g.pick(lastNames),
+			Address:       fmt.Sprintf("%s %d", g.pick(streets), g.between(1, 5000)),
+			DNI:           g.between(minDNI, maxDNI),
+			DischargeDate: custom_time.Time{Time: discharge},
+		}
+
+		if used[np.DNI] {
+			continue
+		}
+
+		used[np.DNI] = true
+
+		err := g.validate(np)
+		if err != nil {
+			return patient.Patient{}, err
+		}
+
+		p, err := g.patients.Create(ctx, np)
+		if errors.Is(err, patient.ErrAlreadyExists) {
+			continue
+		}
+
+		if err != nil {
+			return patient.Patient{}, err
+		}
+
+		return p, nil
+	}
+
+	return patient.Patient{}, errors.New("no free DNI found, too many patients")
+}
+
+// createAppointments creates up to n appointments between the days from and to, both included, inside the working
+// hours of the dentists. None of them overlaps another one of the same dentist or patient, so it gives up on a slot
+// taken and tries another one, and returns how many it created.
+func (g *generator) createAppointments(ctx context.Context, n int, dentists []dentist.Dentist,
+	shifts []schedule.Shift, patients []patient.Patient, from time.Time, to time.Time) (int, error) {
+	if len(dentists) == 0 || len(patients) == 0 {
+		return 0, nil
+	}
+
+	// shiftsByDay has the shifts of every dentist by weekday.
+	shiftsByDay := make(map[int]map[time.Weekday][]schedule.Shift)
+	for _, sh := range shifts {
+		if shiftsByDay[sh.DentistID] == nil {
+			shiftsByDay[sh.DentistID] = make(map[time.Weekday][]schedule.Shift)
+		}
+
+		weekday := time.Weekday(sh.Weekday)
+		shiftsByDay[sh.DentistID][weekday] = append(shiftsByDay[sh.DentistID][weekday], sh)
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	busy := make(map[string][]custom_time.Range)
+	created := 0
+
+	for attempt := 0; created < n && attempt < n*maxAttempts; attempt++ {
+		d := dentists[g.rand.Intn(len(dentists))]
+		p := patients[g.rand.Intn(len(patients))]
+		day := from.AddDate(0, 0, g.rand.Intn(days))
+
+		dayShifts := shiftsByDay[d.ID][day.Weekday()]
+		if len(dayShifts) == 0 {
+			continue
+		}
+
+		sh := dayShifts[g.rand.Intn(len(dayShifts))]
+
+		start, err := atClock(day, sh.StartTime)
+		if err != nil {
+			return created, err
+		}
+
+		end, err := atClock(day, sh.EndTime)
+		if err != nil {
+			return created, err
+		}
+
+		slots := int(end.Sub(start) / g.duration)
+		if slots < 1 {
+			continue
+		}
+
+		date := start.Add(time.Duration(g.rand.Intn(slots)) * g.duration)
+		dentistKey := fmt.Sprintf("dentist-%d", d.ID)
+		patientKey := fmt.Sprintf("patient-%d", p.ID)
+
+		if overlaps(busy[dentistKey], date, date.Add(g.duration)) || overlaps(busy[patientKey], date, date.Add(g.duration)) {
+			continue
+		}
+
+		na := appointment.NewAppointment{
+			PatientID:   p.ID,
+			DentistID:   d.ID,
+			Date:        custom_time.Time{Time: date},
+			Description: g.pick(descriptions),
+		}
+
+		err = g.validate(na)
+		if err != nil {
+			return created, err
+		}
+
+		_, err = g.appointments.Create(ctx, na)
+		if errors.Is(err, appointment.ErrSlotTaken) {
+			continue
+		}
+
+		if err != nil {
+			return created, err
+		}
+
+		slot := custom_time.NewRange(date, date.Add(g.duration))
+		busy[dentistKey] = append(busy[dentistKey], slot)
+		busy[patientKey] = append(busy[patientKey], slot)
+		created++
+	}
+
+	return created, nil
+}
+
+// validate validates the request like the handlers do.
+func (g *generator) validate(request any) error {
+	err := g.validator.Validate.Struct(request)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			return errors.New(g.validator.Translate(validationErrors))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// pick returns a random value of the list.
+func (g *generator) pick(values []string) string {
+	return values[g.rand.Intn(len(values))]
+}
+
+// between returns a random number between min and max, both included.
+func (g *generator) between(min int, max int) int {
+	return min + g.rand.Intn(max-min+1)
+}
+
+// atClock returns the day at the HH:mm hour.
+func atClock(day time.Time, hour string) (time.Time, error) {
+	clock, err := time.Parse("15:04", hour)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location()), nil
+}
+
+// overlaps reports whether any of the ranges overlaps the period between start and end.
+func overlaps(ranges []custom_time.Range, start time.Time, end time.Time) bool {
+	for _, r := range ranges {
+		if r.Overlaps(start, end) {
+			return true
+		}
+	}
+
+	return false
+}
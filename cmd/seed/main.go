@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/config"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/database"
+	"github.com/Nachofra/final-esp-backend-3/cmd/api/stores"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/en_validator"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// seed fills the database of the store configured with fake dentists, with their schedules, patients and
+// appointments, with the same environment variables as the app. Everything is created through the domain services, so
+// it's validated like the requests to the API. The same seed generates the same data, given the same flags:
+//
+//	seed --seed 42 --dentists 10 --patients 200 --appointments 500 --from 2024-03-01 --to 2024-05-31
+//
+// With --test-records it loads the few test records of the clinic into an empty database instead:
+//
+//	seed --test-records
+func main() {
+	today := time.Now().UTC().Format(time.DateOnly)
+
+	seed := flag.Int64("seed", 1, "seed of the random data, the same seed generates the same data")
+	dentists := flag.Int("dentists", 10, "dentists to create, each one with its weekly schedule")
+	patients := flag.Int("patients", 200, "patients to create")
+	appointments := flag.Int("appointments", 500, "appointments to create, none of them overlapping")
+	from := flag.String("from", today, "first day of the appointments, as YYYY-MM-DD")
+	to := flag.String("to", "", "last day of the appointments, as YYYY-MM-DD, 30 days after from by default")
+	testRecords := flag.Bool("test-records", false, "load the test records into an empty database instead of fake data")
+	flag.Parse()
+
+	start, err := time.Parse(time.DateOnly, *from)
+	if err != nil {
+		fail(fmt.Errorf("invalid from date: %w", err))
+	}
+
+	end := start.AddDate(0, 0, 30)
+	if *to != "" {
+		end, err = time.Parse(time.DateOnly, *to)
+		if err != nil {
+			fail(fmt.Errorf("invalid to date: %w", err))
+		}
+	}
+
+	if end.Before(start) {
+		fail("the to date must not be before the from date")
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		fail(err)
+	}
+
+	if cfg.AppointmentDuration < time.Minute {
+		fail("the default duration of the appointments must be at least a minute")
+	}
+
+	if cfg.Store == config.StoreMemory {
+		fail("the memory store is lost when the command ends, seed a database instead")
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+
+	if cfg.Migrate {
+		err = database.Migrate(ctx, cfg, db)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	if *testRecords {
+		err = database.Seed(ctx, cfg, db)
+		if err != nil {
+			fail(err)
+		}
+
+		fmt.Println("loaded the test records")
+
+		return
+	}
+
+	repo := stores.New(cfg, db, nil)
+
+	scheduleService := schedule.NewService(repo.Schedule)
+	waitlistService := waitlist.NewService(repo.Waitlist)
+	appointmentService := appointment.NewService(repo.Appointment, repo.Transaction, scheduleService, waitlistService,
+		cfg.AppointmentDuration)
+
+	g := generator{
+		rand:         rand.New(rand.NewSource(*seed)),
+		validator:    en_validator.Get(),
+		dentists:     dentist.NewService(repo.Dentist, repo.Transaction, appointmentService, waitlistService),
+		patients:     patient.NewService(repo.Patient, repo.Transaction, appointmentService, waitlistService),
+		schedule:     scheduleService,
+		appointments: appointmentService,
+		duration:     cfg.AppointmentDuration,
+	}
+
+	createdDentists, shifts, err := g.createDentists(ctx, *dentists)
+	if err != nil {
+		fail(err)
+	}
+
+	createdPatients, err := g.createPatients(ctx, *patients, start)
+	if err != nil {
+		fail(err)
+	}
+
+	createdAppointments, err := g.createAppointments(ctx, *appointments, createdDentists, shifts, createdPatients, start, end)
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Printf("created %d dentists, %d shifts, %d patients and %d appointments\n",
+		len(createdDentists), len(shifts), len(createdPatients), createdAppointments)
+
+	if createdAppointments < *appointments {
+		fmt.Println("the schedules had no room for more appointments between the dates, try a longer range")
+	}
+}
+
+// fail prints the error and exits with a non zero status.
+func fail(err any) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
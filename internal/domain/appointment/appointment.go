@@ -3,6 +3,15 @@ package appointment
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/rrule"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"sort"
+	"time"
 )
 
 var (
@@ -10,45 +19,105 @@ var (
 	ErrConflict      = errors.New("constraint conflict while doing an action with the store layer")
 	ErrAlreadyExists = errors.New("appointment already exists")
 	ErrValueExceeded = errors.New("attribute value exceed type limit")
+	ErrSlotTaken     = errors.New("the dentist or the patient already has an appointment overlapping that time")
+	ErrInactive      = errors.New("the dentist or the patient is inactive")
+
+	ErrOutsideWorkingHours = errors.New("the appointment is outside the working hours of the dentist")
+	ErrInvalidWindow       = errors.New("the search window must end after it starts and be at most 31 days long")
+
+	ErrInvalidTransition = errors.New("the appointment can't change to that status from its current one")
+
+	ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+	ErrSeriesTooLong     = errors.New("the series must end, with COUNT or UNTIL, and have at most 100 occurrences")
+	ErrInvalidScope      = errors.New("the scope must be this, following or all")
 )
 
 // Store specifies the contract needed for the Store in the Service.
+// Create and Update must fail with ErrSlotTaken when the dentist or the patient already has another appointment
+// overlapping the same time, even under concurrent requests. Cancelled appointments don't take any slot.
+// They must also fail with ErrInactive when the dentist or the patient is inactive.
+// Update never changes the status, that's only done by UpdateStatus, which must fail with ErrInvalidTransition
+// when the appointment is no longer in the from status.
+// GetAll returns the requested page of the appointments, with pagination.All they're all returned. They're sorted by
+// date and ID unless the options sort them otherwise, and only the fields of the options need to be read.
+// CreateSeries and UpdateSeries do the same as Create and Update for many appointments at once, either all of them
+// are saved or none. CancelSeries cancels the scheduled and confirmed occurrences of a series starting from a date.
 type Store interface {
-	GetAll(ctx context.Context, filters map[string]string) []Appointment
+	GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]Appointment, pagination.Meta, error)
 	GetByID(ctx context.Context, ID int) (Appointment, error)
 	Create(ctx context.Context, appointment Appointment) (Appointment, error)
+	CreateSeries(ctx context.Context, appointments []Appointment) ([]Appointment, error)
 	Update(ctx context.Context, appointment Appointment) (Appointment, error)
+	UpdateSeries(ctx context.Context, appointments []Appointment) ([]Appointment, error)
+	UpdateStatus(ctx context.Context, ID int, from Status, to Status) error
+	CancelSeries(ctx context.Context, seriesID string, from time.Time) error
 	Delete(ctx context.Context, ID int) error
 }
 
+// maxAvailabilityWindow is the longest period that can be searched for free slots at once.
+const maxAvailabilityWindow = 31 * 24 * time.Hour
+
+// Schedule specifies the contract needed to know when dentists work.
+// Ranges returns the periods the dentist works between from and to, trimmed to them and with contiguous periods merged.
+type Schedule interface {
+	Ranges(ctx context.Context, dentistID int, from time.Time, to time.Time) ([]custom_time.Range, error)
+}
+
+// Waitlist specifies the contract needed to offer the slots freed by cancelled or deleted appointments.
+type Waitlist interface {
+	SlotFreed(ctx context.Context, appointment Appointment) error
+}
+
 // service unifies all the business operation for the domain.
 type service struct {
-	store Store
+	store           Store
+	transactions    transaction.Manager
+	schedule        Schedule
+	waitlist        Waitlist
+	defaultDuration time.Duration
 }
 
 // Service specifies the contract needed for the Service.
 type Service interface {
-	GetAll(ctx context.Context, filters FilterAppointment) []Appointment
+	GetAll(ctx context.Context, filters FilterAppointment, page pagination.Request, options listing.Options) ([]Appointment, pagination.Meta, error)
 	GetByID(ctx context.Context, ID int) (Appointment, error)
 	Create(ctx context.Context, newAppointment NewAppointment) (Appointment, error)
+	CreateSeries(ctx context.Context, ns NewAppointmentSeries) ([]Appointment, error)
 	Update(ctx context.Context, ID int, ua UpdateAppointment) (Appointment, error)
 	Patch(ctx context.Context, appointment Appointment, pa PatchAppointment) (Appointment, error)
+	PatchSeries(ctx context.Context, appointment Appointment, pa PatchAppointment, scope Scope) ([]Appointment, error)
+	ChangeStatus(ctx context.Context, ID int, status Status) (Appointment, error)
+	CancelSeries(ctx context.Context, ID int, scope Scope) ([]Appointment, error)
+	CancelPending(ctx context.Context, filters FilterAppointment, from time.Time) ([]Appointment, error)
 	Delete(ctx context.Context, ID int) error
+	Availability(ctx context.Context, dentistID int, fa FilterAvailability) (Availability, error)
 }
 
 // NewService creates a new service.
-func NewService(store Store) Service {
+// transactions runs every operation reading and then writing in a unit of work, so the appointments, their dentists
+// and patients and their working hours don't change in between.
+// The waitlist is told about every slot freed through the service, once it's freed, and defaultDuration is used for the
+// appointments created or updated without an explicit duration.
+func NewService(store Store, transactions transaction.Manager, schedule Schedule, waitlist Waitlist, defaultDuration time.Duration) Service {
 	return &service{
-		store: store,
+		store:           store,
+		transactions:    transactions,
+		schedule:        schedule,
+		waitlist:        waitlist,
+		defaultDuration: defaultDuration,
 	}
 }
 
-// GetAll returns appointments by filter.
-func (s *service) GetAll(ctx context.Context, filters FilterAppointment) []Appointment {
+// GetAll returns a page of appointments by filter.
+func (s *service) GetAll(ctx context.Context, filters FilterAppointment, page pagination.Request, options listing.Options) ([]Appointment, pagination.Meta, error) {
 	f := filters.ToMap()
 
-	appointments := s.store.GetAll(ctx, f)
-	return appointments
+	appointments, meta, err := s.store.GetAll(ctx, f, page, options)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return appointments, meta, nil
 }
 
 // GetByID returns an appointment by its ID.
@@ -67,10 +136,23 @@ func (s *service) Create(ctx context.Context, newAppointment NewAppointment) (Ap
 		PatientID:   newAppointment.PatientID,
 		DentistID:   newAppointment.DentistID,
 		Date:        newAppointment.Date,
+		Duration:    s.duration(newAppointment.Duration),
 		Description: newAppointment.Description,
+		Status:      StatusScheduled,
 	}
 
-	a, err := s.store.Create(ctx, appointment)
+	var a Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		err := s.checkWorkingHours(ctx, appointment)
+		if err != nil {
+			return err
+		}
+
+		a, err = s.store.Create(ctx, appointment)
+
+		return err
+	})
 	if err != nil {
 		return Appointment{}, err
 	}
@@ -78,17 +160,92 @@ func (s *service) Create(ctx context.Context, newAppointment NewAppointment) (Ap
 	return a, nil
 }
 
-// Update updates an appointment.
-func (s *service) Update(ctx context.Context, ID int, ua UpdateAppointment) (Appointment, error) {
-	appointment := Appointment{
-		ID:          ID,
-		PatientID:   ua.PatientID,
-		DentistID:   ua.DentistID,
-		Date:        ua.Date,
-		Description: ua.Description,
+// CreateSeries creates all the occurrences of a recurring appointment, sharing a new series ID.
+// Every occurrence must fit in the working hours of the dentist and be free, otherwise none is created.
+func (s *service) CreateSeries(ctx context.Context, ns NewAppointmentSeries) ([]Appointment, error) {
+	rule, err := rrule.Parse(ns.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRecurrence, err)
 	}
 
-	a, err := s.store.Update(ctx, appointment)
+	dates := rule.Occurrences(ns.Date.Time, maxSeriesOccurrences+1)
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("%w: it has no occurrences from the date", ErrInvalidRecurrence)
+	}
+
+	if len(dates) > maxSeriesOccurrences {
+		return nil, ErrSeriesTooLong
+	}
+
+	seriesID, err := newSeriesID()
+	if err != nil {
+		return nil, err
+	}
+
+	var response []Appointment
+
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		appointments := make([]Appointment, 0, len(dates))
+
+		for _, date := range dates {
+			appointment := Appointment{
+				PatientID:   ns.PatientID,
+				DentistID:   ns.DentistID,
+				Date:        custom_time.Time{Time: date},
+				Duration:    s.duration(ns.Duration),
+				Description: ns.Description,
+				Status:      StatusScheduled,
+				SeriesID:    seriesID,
+			}
+
+			err := s.checkWorkingHours(ctx, appointment)
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, date.Format(time.DateTime))
+			}
+
+			appointments = append(appointments, appointment)
+		}
+
+		response, err = s.store.CreateSeries(ctx, appointments)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Update updates an appointment, its status is kept as it is.
+func (s *service) Update(ctx context.Context, ID int, ua UpdateAppointment) (Appointment, error) {
+	var a Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		current, err := s.store.GetByID(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		appointment := Appointment{
+			ID:          ID,
+			PatientID:   ua.PatientID,
+			DentistID:   ua.DentistID,
+			Date:        ua.Date,
+			Duration:    s.duration(ua.Duration),
+			Description: ua.Description,
+			Status:      current.Status,
+		}
+
+		err = s.checkWorkingHours(ctx, appointment)
+		if err != nil {
+			return err
+		}
+
+		a, err = s.store.Update(ctx, appointment)
+
+		return err
+	})
 	if err != nil {
 		return Appointment{}, err
 	}
@@ -110,11 +267,26 @@ func (s *service) Patch(ctx context.Context, appointment Appointment, pa PatchAp
 		appointment.Date = *pa.Date
 	}
 
+	if pa.Duration != nil {
+		appointment.Duration = *pa.Duration
+	}
+
 	if pa.Description != nil {
 		appointment.Description = *pa.Description
 	}
 
-	a, err := s.store.Update(ctx, appointment)
+	var a Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		err := s.checkWorkingHours(ctx, appointment)
+		if err != nil {
+			return err
+		}
+
+		a, err = s.store.Update(ctx, appointment)
+
+		return err
+	})
 	if err != nil {
 		return Appointment{}, err
 	}
@@ -122,12 +294,342 @@ func (s *service) Patch(ctx context.Context, appointment Appointment, pa PatchAp
 	return a, nil
 }
 
-// Delete deletes an appointment.
+// PatchSeries patches an appointment and, depending on the scope, the following occurrences of its series or all of
+// them. Only occurrences still scheduled or confirmed are changed, and a new date moves each of them by the same amount.
+func (s *service) PatchSeries(ctx context.Context, appointment Appointment, pa PatchAppointment, scope Scope) ([]Appointment, error) {
+	if scope == ScopeThis || appointment.SeriesID == "" {
+		a, err := s.Patch(ctx, appointment, pa)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Appointment{a}, nil
+	}
+
+	var shift time.Duration
+	if pa.Date != nil {
+		shift = pa.Date.Sub(appointment.Date.Time)
+	}
+
+	var response []Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		occurrences, err := s.occurrences(ctx, appointment, scope)
+		if err != nil {
+			return err
+		}
+
+		for i := range occurrences {
+			o := &occurrences[i]
+
+			if pa.PatientID != nil {
+				o.PatientID = *pa.PatientID
+			}
+
+			if pa.DentistID != nil {
+				o.DentistID = *pa.DentistID
+			}
+
+			if pa.Duration != nil {
+				o.Duration = *pa.Duration
+			}
+
+			if pa.Description != nil {
+				o.Description = *pa.Description
+			}
+
+			o.Date = custom_time.Time{Time: o.Date.Add(shift)}
+
+			err := s.checkWorkingHours(ctx, *o)
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, o.Date.Format(time.DateTime))
+			}
+		}
+
+		response, err = s.store.UpdateSeries(ctx, occurrences)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ChangeStatus moves an appointment to the given status, only if its lifecycle allows it.
+func (s *service) ChangeStatus(ctx context.Context, ID int, status Status) (Appointment, error) {
+	var appointment Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		var err error
+		appointment, err = s.changeStatus(ctx, ID, status)
+
+		return err
+	})
+	if err != nil {
+		return Appointment{}, err
+	}
+
+	if status == StatusCancelled {
+		s.slotFreed(ctx, appointment)
+	}
+
+	return appointment, nil
+}
+
+// CancelSeries cancels an appointment and, depending on the scope, the following occurrences of its series or all of
+// them. Occurrences already cancelled, completed or missed are left as they are. It returns the cancelled appointments.
+func (s *service) CancelSeries(ctx context.Context, ID int, scope Scope) ([]Appointment, error) {
+	var cancelled []Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		appointment, err := s.store.GetByID(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if scope == ScopeThis || appointment.SeriesID == "" {
+			a, err := s.changeStatus(ctx, ID, StatusCancelled)
+			if err != nil {
+				return err
+			}
+
+			cancelled = []Appointment{a}
+
+			return nil
+		}
+
+		occurrences, err := s.occurrences(ctx, appointment, scope)
+		if err != nil {
+			return err
+		}
+
+		if len(occurrences) == 0 {
+			return ErrInvalidTransition
+		}
+
+		err = s.store.CancelSeries(ctx, appointment.SeriesID, occurrences[0].Date.Time)
+		if err != nil {
+			return err
+		}
+
+		for i := range occurrences {
+			occurrences[i].Status = StatusCancelled
+		}
+
+		cancelled = occurrences
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range cancelled {
+		s.slotFreed(ctx, a)
+	}
+
+	return cancelled, nil
+}
+
+// CancelPending cancels the appointments matching the filters that are still scheduled or confirmed after the given
+// date. Their slots are not offered to the waitlist, it's up to the caller once the unit of work it runs in is
+// committed. It returns the cancelled appointments.
+func (s *service) CancelPending(ctx context.Context, filters FilterAppointment, from time.Time) ([]Appointment, error) {
+	var cancelled []Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		filters.FromDate = &custom_time.Time{Time: from}
+
+		appointments, _, err := s.store.GetAll(ctx, filters.ToMap(), pagination.All, listing.Default)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range appointments {
+			if !a.pending() || !a.Date.After(from) {
+				continue
+			}
+
+			err = s.store.UpdateStatus(ctx, a.ID, a.Status, StatusCancelled)
+			if err != nil {
+				return err
+			}
+
+			a.Status = StatusCancelled
+			cancelled = append(cancelled, a)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cancelled, nil
+}
+
+// Delete deletes an appointment, its slot is offered to the waitlist if it was still pending.
 func (s *service) Delete(ctx context.Context, ID int) error {
-	err := s.store.Delete(ctx, ID)
+	var appointment Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		var err error
+
+		appointment, err = s.store.GetByID(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		return s.store.Delete(ctx, ID)
+	})
 	if err != nil {
 		return err
 	}
 
+	if appointment.pending() {
+		s.slotFreed(ctx, appointment)
+	}
+
 	return nil
 }
+
+// Availability returns the free slots of a dentist between two dates, computed from its working hours minus its
+// appointments. Slots last the requested duration and start as early as possible after a working period starts or
+// an appointment ends.
+func (s *service) Availability(ctx context.Context, dentistID int, fa FilterAvailability) (Availability, error) {
+	from, to := fa.From.Time, fa.To.Time
+	if !to.After(from) || to.Sub(from) > maxAvailabilityWindow {
+		return Availability{}, ErrInvalidWindow
+	}
+
+	duration := time.Duration(s.duration(fa.Duration)) * time.Minute
+
+	ranges, err := s.schedule.Ranges(ctx, dentistID, from, to)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	filters := FilterAppointment{DentistID: &dentistID, FromDate: &fa.From, ToDate: &fa.To}
+
+	appointments, _, err := s.store.GetAll(ctx, filters.ToMap(), pagination.All, listing.Default)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	busy := make([]Appointment, 0)
+	for _, a := range appointments {
+		if a.Status != StatusCancelled {
+			busy = append(busy, a)
+		}
+	}
+
+	slots := make([]custom_time.Range, 0)
+
+	for _, r := range ranges {
+		start := r.Start.Time
+
+		for !start.Add(duration).After(r.End.Time) {
+			end := start.Add(duration)
+
+			blocked := false
+			for _, a := range busy {
+				if a.Date.Before(end) && a.End().After(start) {
+					blocked = true
+					start = a.End()
+					break
+				}
+			}
+
+			if !blocked {
+				slots = append(slots, custom_time.NewRange(start, end))
+				start = end
+			}
+		}
+	}
+
+	return Availability{DentistID: dentistID, Slots: slots}, nil
+}
+
+// occurrences returns, sorted by date, the occurrences of the series of the appointment the scope applies to that are
+// still scheduled or confirmed.
+func (s *service) occurrences(ctx context.Context, appointment Appointment, scope Scope) ([]Appointment, error) {
+	filters := FilterAppointment{SeriesID: &appointment.SeriesID}
+
+	appointments, _, err := s.store.GetAll(ctx, filters.ToMap(), pagination.All, listing.Default)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := make([]Appointment, 0)
+	for _, a := range appointments {
+		if !a.pending() || (scope == ScopeFollowing && a.Date.Before(appointment.Date.Time)) {
+			continue
+		}
+
+		occurrences = append(occurrences, a)
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Date.Before(occurrences[j].Date.Time)
+	})
+
+	return occurrences, nil
+}
+
+// changeStatus moves an appointment to the given status, only if its lifecycle allows it, without offering its slot.
+func (s *service) changeStatus(ctx context.Context, ID int, status Status) (Appointment, error) {
+	appointment, err := s.store.GetByID(ctx, ID)
+	if err != nil {
+		return Appointment{}, err
+	}
+
+	if !appointment.Status.CanTransition(status) {
+		return Appointment{}, ErrInvalidTransition
+	}
+
+	err = s.store.UpdateStatus(ctx, ID, appointment.Status, status)
+	if err != nil {
+		return Appointment{}, err
+	}
+
+	appointment.Status = status
+
+	return appointment, nil
+}
+
+// slotFreed offers the slot of the appointment to the waitlist.
+// The appointment is already cancelled or deleted at this point, out of the unit of work that did it, so errors are
+// only logged.
+func (s *service) slotFreed(ctx context.Context, appointment Appointment) {
+	err := s.waitlist.SlotFreed(ctx, appointment)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// checkWorkingHours checks that the appointment is fully inside the working hours of its dentist.
+func (s *service) checkWorkingHours(ctx context.Context, appointment Appointment) error {
+	ranges, err := s.schedule.Ranges(ctx, appointment.DentistID, appointment.Date.Time, appointment.End())
+	if err != nil {
+		return err
+	}
+
+	for _, r := range ranges {
+		if r.Contains(appointment.Date.Time, appointment.End()) {
+			return nil
+		}
+	}
+
+	return ErrOutsideWorkingHours
+}
+
+// duration returns the given duration in minutes, or the default duration of the service when it's not set.
+func (s *service) duration(minutes int) int {
+	if minutes > 0 {
+		return minutes
+	}
+
+	return int(s.defaultDuration / time.Minute)
+}
@@ -0,0 +1,451 @@
+package appointment_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	memoryAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/memory"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	memoryDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/memory"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	memoryPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/memory"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	memorySchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/memory"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	memoryWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// monday is a Monday the dentists of the clinic work, from 09:00 to 13:00 and from 14:00 to 18:00.
+var monday = time.Date(2030, time.May, 6, 0, 0, 0, 0, time.UTC)
+
+// clinic is a service over the memory stores with two dentists working on Mondays and two patients.
+type clinic struct {
+	service  appointment.Service
+	dentists []int
+	patients []int
+}
+
+// newClinic creates the clinic, appointments last 30 minutes by default.
+func newClinic(t *testing.T) clinic {
+	t.Helper()
+
+	ctx := context.Background()
+	db := memory.New()
+
+	dentistStore := memoryDentist.NewStore(db)
+	patientStore := memoryPatient.NewStore(db)
+	scheduleStore := memorySchedule.NewStore(db)
+	waitlistStore := memoryWaitlist.NewStore(db)
+	appointmentStore := memoryAppointment.NewStore(db)
+
+	var c clinic
+
+	for i := 0; i < 2; i++ {
+		d, err := dentistStore.Create(ctx, dentist.Dentist{FirstName: "Ana", LastName: "Díaz",
+			RegistrationNumber: 1234 + i, Active: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, hours := range [][2]string{{"09:00", "13:00"}, {"14:00", "18:00"}} {
+			_, err = scheduleStore.Create(ctx, schedule.Shift{DentistID: d.ID, Weekday: int(time.Monday),
+				StartTime: hours[0], EndTime: hours[1]})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		c.dentists = append(c.dentists, d.ID)
+	}
+
+	for i := 0; i < 2; i++ {
+		p, err := patientStore.Create(ctx, patient.Patient{FirstName: "Juan", LastName: "Pérez", Address: "Mitre 100",
+			DNI: 30000000 + i, DischargeDate: custom_time.Time{Time: monday.AddDate(-1, 0, 0)}, Active: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.patients = append(c.patients, p.ID)
+	}
+
+	c.service = appointment.NewService(appointmentStore, transaction.None, schedule.NewService(scheduleStore),
+		waitlist.NewService(waitlistStore), 30*time.Minute)
+
+	return c
+}
+
+// at returns the time of the Monday of the clinic at the hour and minute.
+func at(hour int, minute int) custom_time.Time {
+	return custom_time.Time{Time: monday.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)}
+}
+
+func TestCreateSeriesWithoutOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rrule string
+	}{
+		{name: "until before the start", rrule: "FREQ=WEEKLY;UNTIL=20300101"},
+		{name: "until before the start time", rrule: "FREQ=DAILY;UNTIL=20300506T080000Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClinic(t)
+
+			appointments, err := c.service.CreateSeries(context.Background(), appointment.NewAppointmentSeries{
+				PatientID: c.patients[0], DentistID: c.dentists[0], Date: at(10, 0), Description: "Checkup", RRule: tt.rrule,
+			})
+			if !errors.Is(err, appointment.ErrInvalidRecurrence) {
+				t.Errorf("creating the series got %v and %d appointments, want %v", err, len(appointments),
+					appointment.ErrInvalidRecurrence)
+			}
+		})
+	}
+}
+
+// TestCreateOverlap books an appointment of the first dentist and the first patient from 10:00 to 10:30, and then
+// another one, which must be rejected only when it overlaps the first for the same dentist or the same patient.
+func TestCreateOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		dentist  int
+		patient  int
+		date     custom_time.Time
+		duration int
+		want     error
+	}{
+		{name: "same time for the dentist", dentist: 0, patient: 1, date: at(10, 0), want: appointment.ErrSlotTaken},
+		{name: "same time for the patient", dentist: 1, patient: 0, date: at(10, 0), want: appointment.ErrSlotTaken},
+		{name: "starts inside", dentist: 0, patient: 1, date: at(10, 15), want: appointment.ErrSlotTaken},
+		{name: "ends inside", dentist: 1, patient: 0, date: at(9, 45), want: appointment.ErrSlotTaken},
+		{name: "contains it", dentist: 0, patient: 1, date: at(9, 30), duration: 90, want: appointment.ErrSlotTaken},
+		{name: "right after", dentist: 0, patient: 0, date: at(10, 30)},
+		{name: "right before", dentist: 0, patient: 0, date: at(9, 30)},
+		{name: "other dentist and patient", dentist: 1, patient: 1, date: at(10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClinic(t)
+
+			_, err := c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[0],
+				DentistID: c.dentists[0], Date: at(10, 0), Description: "Checkup"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[tt.patient],
+				DentistID: c.dentists[tt.dentist], Date: tt.date, Duration: tt.duration, Description: "Cleaning"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("booking it returned %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestUpdateExcludesItself moves an appointment over its own slot, which must be allowed, and then over the slot of
+// another appointment of the dentist, which must be rejected.
+func TestUpdateExcludesItself(t *testing.T) {
+	ctx := context.Background()
+	c := newClinic(t)
+
+	a, err := c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[0], DentistID: c.dentists[0],
+		Date: at(10, 0), Description: "Checkup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[1], DentistID: c.dentists[0],
+		Date: at(11, 0), Description: "Cleaning"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := appointment.UpdateAppointment{PatientID: c.patients[0], DentistID: c.dentists[0], Date: at(10, 15),
+		Duration: 45, Description: "Checkup"}
+
+	_, err = c.service.Update(ctx, a.ID, update)
+	if err != nil {
+		t.Errorf("moving the appointment over its own slot returned %v, want no error", err)
+	}
+
+	update.Date = at(10, 45)
+
+	_, err = c.service.Update(ctx, a.ID, update)
+	if !errors.Is(err, appointment.ErrSlotTaken) {
+		t.Errorf("moving the appointment over another one returned %v, want %v", err, appointment.ErrSlotTaken)
+	}
+}
+
+// TestChangeStatus moves a new appointment through the statuses of the path, only the last move can fail.
+func TestChangeStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		path []appointment.Status
+		want error
+	}{
+		{
+			name: "confirmed, checked in and completed",
+			path: []appointment.Status{appointment.StatusConfirmed, appointment.StatusCheckedIn,
+				appointment.StatusCompleted},
+		},
+		{
+			name: "cancelled to completed",
+			path: []appointment.Status{appointment.StatusCancelled, appointment.StatusCompleted},
+			want: appointment.ErrInvalidTransition,
+		},
+		{
+			name: "no show to scheduled",
+			path: []appointment.Status{appointment.StatusNoShow, appointment.StatusScheduled},
+			want: appointment.ErrInvalidTransition,
+		},
+		{
+			name: "completed to cancelled",
+			path: []appointment.Status{appointment.StatusCheckedIn, appointment.StatusCompleted,
+				appointment.StatusCancelled},
+			want: appointment.ErrInvalidTransition,
+		},
+		{
+			name: "scheduled to completed",
+			path: []appointment.Status{appointment.StatusCompleted},
+			want: appointment.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClinic(t)
+
+			a, err := c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[0],
+				DentistID: c.dentists[0], Date: at(10, 0), Description: "Checkup"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			last := len(tt.path) - 1
+
+			for _, status := range tt.path[:last] {
+				_, err = c.service.ChangeStatus(ctx, a.ID, status)
+				if err != nil {
+					t.Fatalf("moving it to %s: %v", status, err)
+				}
+			}
+
+			got, err := c.service.ChangeStatus(ctx, a.ID, tt.path[last])
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("moving it to %s returned %v, want %v", tt.path[last], err, tt.want)
+			}
+
+			if err == nil && got.Status != tt.path[last] {
+				t.Errorf("the appointment is %s, want %s", got.Status, tt.path[last])
+			}
+		})
+	}
+}
+
+// TestStatusFreesSlot books the slot of an appointment for another patient once the appointment reached a final
+// status, only cancelled appointments give their slot back.
+func TestStatusFreesSlot(t *testing.T) {
+	tests := []struct {
+		name string
+		path []appointment.Status
+		want error
+	}{
+		{name: "cancelled", path: []appointment.Status{appointment.StatusCancelled}},
+		{
+			name: "no show",
+			path: []appointment.Status{appointment.StatusNoShow},
+			want: appointment.ErrSlotTaken,
+		},
+		{
+			name: "completed",
+			path: []appointment.Status{appointment.StatusCheckedIn, appointment.StatusCompleted},
+			want: appointment.ErrSlotTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClinic(t)
+
+			a, err := c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[0],
+				DentistID: c.dentists[0], Date: at(10, 0), Description: "Checkup"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, status := range tt.path {
+				_, err = c.service.ChangeStatus(ctx, a.ID, status)
+				if err != nil {
+					t.Fatalf("moving it to %s: %v", status, err)
+				}
+			}
+
+			_, err = c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[1],
+				DentistID: c.dentists[0], Date: at(10, 0), Description: "Cleaning"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("booking its slot returned %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// booking is an appointment of the first patient booked before looking for the availability of the first dentist.
+type booking struct {
+	dentist  int
+	date     custom_time.Time
+	duration int
+	status   appointment.Status
+}
+
+func TestAvailability(t *testing.T) {
+	tests := []struct {
+		name     string
+		bookings []booking
+		from     custom_time.Time
+		to       custom_time.Time
+		duration int
+		want     []string
+	}{
+		{
+			name:     "free day",
+			duration: 60,
+			want: []string{"09:00-10:00", "10:00-11:00", "11:00-12:00", "12:00-13:00",
+				"14:00-15:00", "15:00-16:00", "16:00-17:00", "17:00-18:00"},
+		},
+		{
+			name:     "duration not dividing the shifts",
+			duration: 90,
+			want:     []string{"09:00-10:30", "10:30-12:00", "14:00-15:30", "15:30-17:00"},
+		},
+		{
+			name:     "appointment subtracted",
+			bookings: []booking{{date: at(10, 0)}},
+			duration: 60,
+			want: []string{"09:00-10:00", "10:30-11:30", "11:30-12:30",
+				"14:00-15:00", "15:00-16:00", "16:00-17:00", "17:00-18:00"},
+		},
+		{
+			name: "appointments at the edges of the shifts",
+			bookings: []booking{
+				{date: at(9, 0)},
+				{date: at(12, 30)},
+				{date: at(14, 0), duration: 15},
+				{date: at(17, 0), duration: 60},
+			},
+			duration: 60,
+			want:     []string{"09:30-10:30", "10:30-11:30", "11:30-12:30", "14:15-15:15", "15:15-16:15"},
+		},
+		{
+			name:     "cancelled appointment not subtracted",
+			bookings: []booking{{date: at(10, 0), status: appointment.StatusCancelled}},
+			from:     at(9, 0),
+			to:       at(13, 0),
+			want: []string{"09:00-09:30", "09:30-10:00", "10:00-10:30", "10:30-11:00",
+				"11:00-11:30", "11:30-12:00", "12:00-12:30", "12:30-13:00"},
+		},
+		{
+			name:     "no show appointment subtracted",
+			bookings: []booking{{date: at(10, 0), status: appointment.StatusNoShow}},
+			from:     at(9, 0),
+			to:       at(11, 0),
+			want:     []string{"09:00-09:30", "09:30-10:00", "10:30-11:00"},
+		},
+		{
+			name:     "appointment of another dentist not subtracted",
+			bookings: []booking{{dentist: 1, date: at(10, 0)}},
+			from:     at(9, 0),
+			to:       at(11, 0),
+			want:     []string{"09:00-09:30", "09:30-10:00", "10:00-10:30", "10:30-11:00"},
+		},
+		{
+			name:     "window starting during an appointment",
+			bookings: []booking{{date: at(10, 0)}},
+			from:     at(10, 15),
+			to:       at(12, 0),
+			want:     []string{"10:30-11:00", "11:00-11:30", "11:30-12:00"},
+		},
+		{
+			name:     "window shorter than the duration",
+			from:     at(9, 0),
+			to:       at(9, 45),
+			duration: 60,
+			want:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClinic(t)
+
+			for _, b := range tt.bookings {
+				a, err := c.service.Create(ctx, appointment.NewAppointment{PatientID: c.patients[0],
+					DentistID: c.dentists[b.dentist], Date: b.date, Duration: b.duration, Description: "Checkup"})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if b.status != "" {
+					_, err = c.service.ChangeStatus(ctx, a.ID, b.status)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			filters := appointment.FilterAvailability{From: tt.from, To: tt.to, Duration: tt.duration}
+			if filters.From.IsZero() {
+				filters.From = at(0, 0)
+				filters.To = at(24, 0)
+			}
+
+			availability, err := c.service.Availability(ctx, c.dentists[0], filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+			for _, slot := range availability.Slots {
+				got = append(got, slot.Start.Format("15:04")+"-"+slot.End.Format("15:04"))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("the free slots are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailabilityInvalidWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		from custom_time.Time
+		to   custom_time.Time
+	}{
+		{name: "ending before it starts", from: at(12, 0), to: at(9, 0)},
+		{name: "ending when it starts", from: at(9, 0), to: at(9, 0)},
+		{name: "longer than 31 days", from: at(0, 0), to: custom_time.Time{Time: monday.AddDate(0, 0, 32)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClinic(t)
+
+			_, err := c.service.Availability(context.Background(), c.dentists[0],
+				appointment.FilterAvailability{From: tt.from, To: tt.to})
+			if !errors.Is(err, appointment.ErrInvalidWindow) {
+				t.Errorf("looking for the availability returned %v, want %v", err, appointment.ErrInvalidWindow)
+			}
+		})
+	}
+}
@@ -7,20 +7,49 @@ import (
 )
 
 // Appointment describes an Appointment between a dentist and its patient.
+// Date is when the appointment starts and Duration how many minutes it lasts.
+// SeriesID is shared by all the occurrences of a recurring appointment, and empty for single appointments.
 type Appointment struct {
 	ID          int              `json:"id"`
 	PatientID   int              `json:"patient_id"`
 	DentistID   int              `json:"dentist_id"`
 	Date        custom_time.Time `json:"date"`
+	Duration    int              `json:"duration"`
 	Description string           `json:"description"`
+	Status      Status           `json:"status"`
+	SeriesID    string           `json:"series_id,omitempty"`
+}
+
+// SortFields are the fields appointments can be sorted by, and Fields the ones that can be selected when listing them.
+var (
+	SortFields = []string{"id", "date", "patient_id", "dentist_id", "status"}
+	Fields     = []string{"id", "patient_id", "dentist_id", "date", "duration", "description", "status", "series_id"}
+)
+
+// End returns the date when the appointment finishes.
+func (a Appointment) End() time.Time {
+	return a.Date.Add(time.Duration(a.Duration) * time.Minute)
 }
 
 // NewAppointment describes the data needed to create a new Appointment.
+// When Duration is not set, the default duration of the service is used.
 type NewAppointment struct {
 	PatientID   int              `json:"patient_id"  validate:"required"`
 	DentistID   int              `json:"dentist_id"  validate:"required"`
 	Date        custom_time.Time `json:"date"        validate:"required"`
+	Duration    int              `json:"duration"    validate:"omitempty,min=1,max=1440"`
+	Description string           `json:"description" validate:"required"`
+}
+
+// NewAppointmentSeries describes the data needed to create a recurring Appointment.
+// RRule is an RFC 5545 recurrence rule (FREQ, INTERVAL, COUNT, UNTIL and BYDAY) and Date is its first occurrence.
+type NewAppointmentSeries struct {
+	PatientID   int              `json:"patient_id"  validate:"required"`
+	DentistID   int              `json:"dentist_id"  validate:"required"`
+	Date        custom_time.Time `json:"date"        validate:"required"`
+	Duration    int              `json:"duration"    validate:"omitempty,min=1,max=1440"`
 	Description string           `json:"description" validate:"required"`
+	RRule       string           `json:"rrule"       validate:"required" example:"FREQ=WEEKLY;INTERVAL=4;COUNT=13"`
 }
 
 // NewAppointmentDNIRegistrationNumber describes the request body for creating an appointment by DNI and dentist registration number.
@@ -28,14 +57,17 @@ type NewAppointmentDNIRegistrationNumber struct {
 	PatientDNI    int              `json:"patient_dni"    validate:"required,min=100000,max=999999999"`
 	DentistNumber int              `json:"dentist_number" validate:"required"`
 	Date          custom_time.Time `json:"date"           validate:"required"`
+	Duration      int              `json:"duration"       validate:"omitempty,min=1,max=1440"`
 	Description   string           `json:"description"    validate:"required"`
 }
 
 // UpdateAppointment describes the data needed to update an Appointment.
+// When Duration is not set, the default duration of the service is used.
 type UpdateAppointment struct {
 	PatientID   int              `json:"patient_id"  validate:"required"`
 	DentistID   int              `json:"dentist_id"  validate:"required"`
 	Date        custom_time.Time `json:"date"        validate:"required"`
+	Duration    int              `json:"duration"    validate:"omitempty,min=1,max=1440"`
 	Description string           `json:"description" validate:"required"`
 }
 
@@ -44,16 +76,20 @@ type PatchAppointment struct {
 	PatientID   *int              `json:"patient_id"`
 	DentistID   *int              `json:"dentist_id"`
 	Date        *custom_time.Time `json:"date"`
+	Duration    *int              `json:"duration" validate:"omitempty,min=1,max=1440"`
 	Description *string           `json:"description"`
 }
 
 // FilterAppointment describes the data needed to filter an Appointment.
+// FromDate and ToDate match every appointment that overlaps that window, not only the ones starting inside it.
 type FilterAppointment struct {
 	PatientID *int              `form:"patient_id"`
 	DentistID *int              `form:"dentist_id"`
 	DNI       *int              `form:"dni" validate:"omitempty,min=100000,max=99999999"`
 	FromDate  *custom_time.Time `form:"from_date"`
 	ToDate    *custom_time.Time `form:"to_date"`
+	Status    *string           `form:"status" validate:"omitempty,oneof=scheduled confirmed checked_in completed cancelled no_show"`
+	SeriesID  *string           `form:"series_id"`
 }
 
 // ToMap parses FilterAppointment to a map[string]string.
@@ -80,5 +116,27 @@ func (fa FilterAppointment) ToMap() map[string]string {
 		newMap["to_date"] = fa.ToDate.Format(time.DateTime)
 	}
 
+	if fa.Status != nil {
+		newMap["status"] = *fa.Status
+	}
+
+	if fa.SeriesID != nil {
+		newMap["series_id"] = *fa.SeriesID
+	}
+
 	return newMap
 }
+
+// FilterAvailability describes the data needed to search the free slots of a dentist.
+// When Duration is not set, the default duration of the service is used.
+type FilterAvailability struct {
+	From     custom_time.Time `form:"from"     validate:"required"`
+	To       custom_time.Time `form:"to"       validate:"required"`
+	Duration int              `form:"duration" validate:"omitempty,min=1,max=1440"`
+}
+
+// Availability describes the free slots of a dentist.
+type Availability struct {
+	DentistID int                 `json:"dentist_id"`
+	Slots     []custom_time.Range `json:"slots"`
+}
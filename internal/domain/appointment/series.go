@@ -0,0 +1,51 @@
+package appointment
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// Scope describes which occurrences of a recurring Appointment an edit or a cancellation applies to.
+type Scope string
+
+const (
+	ScopeThis      Scope = "this"
+	ScopeFollowing Scope = "following"
+	ScopeAll       Scope = "all"
+)
+
+// maxSeriesOccurrences is the largest number of occurrences a recurring appointment can have.
+const maxSeriesOccurrences = 100
+
+// ParseScope parses a Scope, an empty one means ScopeThis.
+func ParseScope(s string) (Scope, error) {
+	switch Scope(s) {
+	case "", ScopeThis:
+		return ScopeThis, nil
+	case ScopeFollowing, ScopeAll:
+		return Scope(s), nil
+	default:
+		return "", ErrInvalidScope
+	}
+}
+
+// pending reports whether the appointment hasn't happened nor been cancelled yet, so it can still be changed as part
+// of its series.
+func (a Appointment) pending() bool {
+	return a.Status == StatusScheduled || a.Status == StatusConfirmed
+}
+
+// newSeriesID returns a random UUID (version 4) to identify a new series.
+func newSeriesID() (string, error) {
+	var b [16]byte
+
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", err
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,32 @@
+package appointment
+
+// Status describes the stage of its lifecycle an Appointment is in.
+type Status string
+
+const (
+	StatusScheduled Status = "scheduled"
+	StatusConfirmed Status = "confirmed"
+	StatusCheckedIn Status = "checked_in"
+	StatusCompleted Status = "completed"
+	StatusCancelled Status = "cancelled"
+	StatusNoShow    Status = "no_show"
+)
+
+// transitions holds, for every status, the statuses an Appointment can move to from it.
+// Completed, cancelled and no-show appointments are final and can't move anymore.
+var transitions = map[Status][]Status{
+	StatusScheduled: {StatusConfirmed, StatusCheckedIn, StatusCancelled, StatusNoShow},
+	StatusConfirmed: {StatusCheckedIn, StatusCancelled, StatusNoShow},
+	StatusCheckedIn: {StatusCompleted},
+}
+
+// CanTransition reports whether an Appointment in status s can move to the next status.
+func (s Status) CanTransition(next Status) bool {
+	for _, st := range transitions[s] {
+		if st == next {
+			return true
+		}
+	}
+
+	return false
+}
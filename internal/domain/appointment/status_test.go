@@ -0,0 +1,44 @@
+package appointment_test
+
+import (
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	statuses := []appointment.Status{
+		appointment.StatusScheduled,
+		appointment.StatusConfirmed,
+		appointment.StatusCheckedIn,
+		appointment.StatusCompleted,
+		appointment.StatusCancelled,
+		appointment.StatusNoShow,
+	}
+
+	// allowed holds the only transitions of the lifecycle, any other pair must be forbidden.
+	allowed := map[appointment.Status][]appointment.Status{
+		appointment.StatusScheduled: {appointment.StatusConfirmed, appointment.StatusCheckedIn,
+			appointment.StatusCancelled, appointment.StatusNoShow},
+		appointment.StatusConfirmed: {appointment.StatusCheckedIn, appointment.StatusCancelled,
+			appointment.StatusNoShow},
+		appointment.StatusCheckedIn: {appointment.StatusCompleted},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, next := range allowed[from] {
+				if next == to {
+					want = true
+				}
+			}
+
+			t.Run(string(from)+" to "+string(to), func(t *testing.T) {
+				got := from.CanTransition(to)
+				if got != want {
+					t.Errorf("%s.CanTransition(%s) = %v, want %v", from, to, got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"strconv"
+	"time"
+)
+
+// Store wraps all the operations to the in-memory database.
+type Store struct {
+	db           *memory.DB
+	appointments *memory.Table[appointment.Appointment]
+	dentists     *memory.Table[dentist.Dentist]
+	patients     *memory.Table[patient.Patient]
+}
+
+// NewStore creates a new store, appointments reference their dentist and patient like in the SQL database, so they
+// can't be deleted while they have appointments.
+func NewStore(db *memory.DB) *Store {
+	appointments := memory.NewTable(db, "appointment", func(a *appointment.Appointment) *int { return &a.ID })
+	appointments.References("fk_appointment_dentist", "dentist", func(a appointment.Appointment) int {
+		return a.DentistID
+	}, false)
+	appointments.References("fk_appointment_patient", "patient", func(a appointment.Appointment) int {
+		return a.PatientID
+	}, false)
+
+	return &Store{
+		db:           db,
+		appointments: appointments,
+		dentists:     memory.NewTable(db, "dentist", func(d *dentist.Dentist) *int { return &d.ID }),
+		patients:     memory.NewTable(db, "patient", func(p *patient.Patient) *int { return &p.ID }),
+	}
+}
+
+// GetAll returns a page of the appointments matching the filters, in the order of the options, and the metadata of
+// the page. All the fields are returned, whatever the options select.
+func (s *Store) GetAll(_ context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]appointment.Appointment, pagination.Meta, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	appointmentsList := make([]appointment.Appointment, 0)
+
+	for _, a := range s.appointments.Rows() {
+		if s.matches(a, filters) {
+			appointmentsList = append(appointmentsList, a)
+		}
+	}
+
+	appointmentsList, meta, err := memory.Page(appointmentsList, page, options.Order("id", "date"), value)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return appointmentsList, meta, nil
+}
+
+// GetByID returns an appointment by its ID.
+func (s *Store) GetByID(_ context.Context, ID int) (appointment.Appointment, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	a, ok := s.appointments.Get(ID)
+	if !ok {
+		return appointment.Appointment{}, appointment.ErrNotFound
+	}
+
+	return a, nil
+}
+
+// Create creates a new appointment.
+func (s *Store) Create(_ context.Context, a appointment.Appointment) (appointment.Appointment, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	appointments, err := s.create([]appointment.Appointment{a})
+	if err != nil {
+		return appointment.Appointment{}, err
+	}
+
+	return appointments[0], nil
+}
+
+// CreateSeries creates all the occurrences of a series at once.
+func (s *Store) CreateSeries(_ context.Context, appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	return s.create(appointments)
+}
+
+// Update updates an appointment.
+func (s *Store) Update(_ context.Context, a appointment.Appointment) (appointment.Appointment, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	appointments, err := s.update([]appointment.Appointment{a})
+	if err != nil {
+		return appointment.Appointment{}, err
+	}
+
+	return appointments[0], nil
+}
+
+// UpdateSeries updates many occurrences of a series at once.
+func (s *Store) UpdateSeries(_ context.Context, appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	return s.update(appointments)
+}
+
+// UpdateStatus moves an appointment from one status to another.
+func (s *Store) UpdateStatus(_ context.Context, ID int, from appointment.Status, to appointment.Status) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	a, ok := s.appointments.Get(ID)
+
+	// The appointment changed its status since it was read.
+	if !ok || a.Status != from {
+		return appointment.ErrInvalidTransition
+	}
+
+	a.Status = to
+
+	return s.appointments.Update(a)
+}
+
+// CancelSeries cancels the scheduled and confirmed occurrences of a series starting from a date.
+func (s *Store) CancelSeries(_ context.Context, seriesID string, from time.Time) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	for _, a := range s.appointments.Rows() {
+		if a.SeriesID != seriesID || a.Date.Before(from) {
+			continue
+		}
+
+		if a.Status == appointment.StatusScheduled || a.Status == appointment.StatusConfirmed {
+			a.Status = appointment.StatusCancelled
+
+			err := s.appointments.Update(a)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes an appointment.
+func (s *Store) Delete(_ context.Context, ID int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.appointments.Delete(ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, memory.ErrDBNoRows):
+			return appointment.ErrNotFound
+		case errors.Is(err, memory.ErrDBConflict):
+			return appointment.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// create inserts the appointments, reserving the slot of each one before inserting it, so occurrences of the same
+// series can't overlap each other either. When any of them fails, the ones already inserted are deleted again.
+func (s *Store) create(appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	created := make([]appointment.Appointment, 0, len(appointments))
+
+	for _, a := range appointments {
+		err := s.reserveSlot(a)
+		if err == nil {
+			a, err = s.appointments.Insert(a)
+			err = writeError(err)
+		}
+
+		if err != nil {
+			for _, c := range created {
+				_ = s.appointments.Delete(c.ID)
+			}
+
+			return nil, occurrenceError(err, a, len(appointments))
+		}
+
+		created = append(created, a)
+	}
+
+	return created, nil
+}
+
+// update updates the appointments, reserving the new slot of each one before updating it, their status is kept.
+// When any of them fails, the ones already updated are restored.
+func (s *Store) update(appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	previous := make([]appointment.Appointment, 0, len(appointments))
+
+	for _, a := range appointments {
+		current, ok := s.appointments.Get(a.ID)
+		if ok {
+			a.Status = current.Status
+		}
+
+		err := s.reserveSlot(a)
+		if err == nil {
+			err = s.appointments.Update(a)
+
+			// Updating a missing row affects no rows, which is not an error for the SQL stores.
+			if errors.Is(err, memory.ErrDBNoRows) {
+				err = nil
+			}
+
+			err = writeError(err)
+		}
+
+		if err != nil {
+			for _, p := range previous {
+				_ = s.appointments.Update(p)
+			}
+
+			return nil, occurrenceError(err, a, len(appointments))
+		}
+
+		if ok {
+			previous = append(previous, current)
+		}
+	}
+
+	return appointments, nil
+}
+
+// reserveSlot checks that the dentist and the patient of the appointment are active and none of them has another
+// appointment, not cancelled, overlapping it.
+// A missing dentist or patient is reported as active, the foreign keys will report it as a conflict later.
+func (s *Store) reserveSlot(a appointment.Appointment) error {
+	d, ok := s.dentists.Get(a.DentistID)
+	if ok && !d.Active {
+		return appointment.ErrInactive
+	}
+
+	p, ok := s.patients.Get(a.PatientID)
+	if ok && !p.Active {
+		return appointment.ErrInactive
+	}
+
+	for _, other := range s.appointments.Rows() {
+		if other.ID == a.ID || other.Status == appointment.StatusCancelled {
+			continue
+		}
+
+		if other.DentistID != a.DentistID && other.PatientID != a.PatientID {
+			continue
+		}
+
+		if other.Date.Before(a.End()) && other.End().After(a.Date.Time) {
+			return appointment.ErrSlotTaken
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether the appointment matches the filters, like the condition of the SQL stores.
+func (s *Store) matches(a appointment.Appointment, filters map[string]string) bool {
+	if id, ok := filters["patient_id"]; ok && strconv.Itoa(a.PatientID) != id {
+		return false
+	}
+
+	if id, ok := filters["dentist_id"]; ok && strconv.Itoa(a.DentistID) != id {
+		return false
+	}
+
+	if dni, ok := filters["dni"]; ok {
+		p, _ := s.patients.Get(a.PatientID)
+		if strconv.Itoa(p.DNI) != dni {
+			return false
+		}
+	}
+
+	if from, ok := memory.ParseTime(filters["from_date"]); ok && !a.End().After(from) {
+		return false
+	}
+
+	if to, ok := memory.ParseTime(filters["to_date"]); ok && a.Date.After(to) {
+		return false
+	}
+
+	if status, ok := filters["status"]; ok && string(a.Status) != status {
+		return false
+	}
+
+	if seriesID, ok := filters["series_id"]; ok && a.SeriesID != seriesID {
+		return false
+	}
+
+	return true
+}
+
+// value returns the value of a field appointments can be sorted by.
+func value(a appointment.Appointment, field string) any {
+	switch field {
+	case "date":
+		return a.Date.Time
+	case "patient_id":
+		return a.PatientID
+	case "dentist_id":
+		return a.DentistID
+	case "status":
+		return string(a.Status)
+	default:
+		return a.ID
+	}
+}
+
+// writeError maps the errors of inserting or updating an appointment to the errors of the domain.
+func writeError(err error) error {
+	switch {
+	case errors.Is(err, memory.ErrDBDuplicateEntry):
+		return appointment.ErrAlreadyExists
+	case errors.Is(err, memory.ErrDBConflict):
+		return appointment.ErrConflict
+	default:
+		return err
+	}
+}
+
+// occurrenceError adds the date of the appointment to the error when many appointments are saved at once, so it's
+// clear which occurrence of the series failed.
+func occurrenceError(err error, a appointment.Appointment, total int) error {
+	if total == 1 || (!errors.Is(err, appointment.ErrSlotTaken) && !errors.Is(err, appointment.ErrInactive)) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", err, a.Date.Format(time.DateTime))
+}
@@ -0,0 +1,124 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	mysqlAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	mysqlDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	mysqlPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	mysqlSchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	mysqlWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/migrate"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestBookingCommittedWhileWaiting books a slot in a unit of work that already read the database, while another
+// booking of the same slot commits, it must find the slot taken. At the REPEATABLE READ default of MySQL the first
+// read fixes the snapshot, so the overlap check would miss the other booking and both would be saved.
+// It runs against the database of MYSQL_TEST_DSN, the clinic one, since the queries name it.
+func TestBookingCommittedWhileWaiting(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set, like root:root@tcp(localhost:3307)/clinic?parseTime=true")
+	}
+
+	ctx := context.Background()
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrator, err := migrate.New(db, migrate.MySQL, mysql.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := mysqlAppointment.NewStore(db)
+	transactions := transaction.New(db, sql.LevelReadCommitted, nil)
+	service := appointment.NewService(store, transactions, schedule.NewService(mysqlSchedule.NewStore(db)),
+		waitlist.NewService(mysqlWaitlist.NewStore(db)), 30*time.Minute)
+
+	// Every run books its own dentist and patients, so it doesn't find the ones of the previous runs.
+	unique := int(time.Now().UnixNano() % 100000)
+
+	d, err := mysqlDentist.New(db).Create(ctx, dentist.Dentist{FirstName: "Ana", LastName: "Díaz",
+		RegistrationNumber: 800000 + unique, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := nextMonday(10)
+
+	_, err = mysqlSchedule.NewStore(db).Create(ctx, schedule.Shift{DentistID: d.ID, Weekday: 1, StartTime: "09:00",
+		EndTime: "13:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patients := make([]patient.Patient, 0, 2)
+	for i := 0; i < 2; i++ {
+		p, err := mysqlPatient.NewStore(db).Create(ctx, patient.Patient{FirstName: "Juan", LastName: "Pérez",
+			Address: "Mitre 100", DNI: 40000000 + unique*2 + i, DischargeDate: custom_time.Time{Time: date.AddDate(-1, 0, 0)},
+			Active: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		patients = append(patients, p)
+	}
+
+	err = transactions.Do(ctx, func(ctx context.Context) error {
+		// A plain read before locking anything, like the working hours check of the service does.
+		_, _, err := store.GetAll(ctx, appointment.FilterAppointment{DentistID: &d.ID}.ToMap(), pagination.All,
+			listing.Default)
+		if err != nil {
+			return err
+		}
+
+		// Another booking of the slot, out of this unit of work, commits meanwhile.
+		_, err = service.Create(context.Background(), appointment.NewAppointment{PatientID: patients[0].ID,
+			DentistID: d.ID, Date: custom_time.Time{Time: date}, Description: "Checkup"})
+		if err != nil {
+			return err
+		}
+
+		_, err = store.Create(ctx, appointment.Appointment{PatientID: patients[1].ID, DentistID: d.ID,
+			Date: custom_time.Time{Time: date}, Duration: 30, Description: "Cleaning", Status: appointment.StatusScheduled})
+
+		return err
+	})
+	if !errors.Is(err, appointment.ErrSlotTaken) {
+		t.Fatalf("booking a slot taken while the unit of work ran got %v, want %v", err, appointment.ErrSlotTaken)
+	}
+}
+
+// nextMonday returns the next Monday at the hour, in UTC.
+func nextMonday(hour int) time.Time {
+	day := time.Now().UTC().AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.UTC)
+}
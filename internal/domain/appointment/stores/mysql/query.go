@@ -1,36 +1,210 @@
 package mysql
 
-import "github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+import (
+	"database/sql"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"strconv"
+	"strings"
+	"time"
+)
 
 const (
-	QueryGetAllAppointment = `SELECT a.id, a.patient_id, a.dentist_id, a.date, a.description
+	QueryGetAllAppointment = `SELECT %s
+	FROM clinic.appointment a INNER JOIN clinic.patient p on a.patient_id = p.id`
+
+	QueryCountAppointment = `SELECT COUNT(*)
 	FROM clinic.appointment a INNER JOIN clinic.patient p on a.patient_id = p.id`
 
-	QueryGetAppointmentByID = `SELECT id, patient_id, dentist_id, date, description
+	QueryGetAppointmentByID = `SELECT id, patient_id, dentist_id, date, duration, description, status, series_id
 	FROM clinic.appointment WHERE id = ?`
 
-	QueryInsertAppointment = `INSERT INTO clinic.appointment(patient_id,dentist_id,date,description)
-	VALUES(?,?,?,?)`
+	QueryInsertAppointment = `INSERT INTO clinic.appointment(patient_id,dentist_id,date,duration,description,status,series_id)
+	VALUES(?,?,?,?,?,?,?)`
 
-	QueryUpdateAppointment = `UPDATE clinic.appointment SET patient_id = ?, dentist_id = ?, date = ?, description = ?
+	QueryUpdateAppointment = `UPDATE clinic.appointment SET patient_id = ?, dentist_id = ?, date = ?, duration = ?, description = ?
 	WHERE id = ?`
 
+	QueryUpdateAppointmentStatus = `UPDATE clinic.appointment SET status = ? WHERE id = ? AND status = ?`
+
+	QueryCancelSeries = `UPDATE clinic.appointment SET status = ?
+	WHERE series_id = ? AND date >= ? AND status IN (?, ?)`
+
 	QueryDeleteAppointment = `DELETE FROM clinic.appointment WHERE id = ?`
+
+	QueryLockDentist = `SELECT active FROM clinic.dentist WHERE id = ? FOR UPDATE`
+
+	QueryLockPatient = `SELECT active FROM clinic.patient WHERE id = ? FOR UPDATE`
+
+	QueryCountAppointmentsInSlot = `SELECT COUNT(*) FROM clinic.appointment
+	WHERE id <> ? AND status <> ? AND date < ? AND DATE_ADD(date, INTERVAL duration MINUTE) > ?
+	AND (dentist_id = ? OR patient_id = ?)`
 )
 
-// GenerateQuery handles query creation to filter dynamically based on params.
-func GenerateQuery(filter map[string]string) string {
-	// Hardcoded for now :(
-	limit := 1000
-	offset := 0
+// columns maps the fields of appointments to their columns.
+var columns = map[string]string{
+	"id":          "a.id",
+	"patient_id":  "a.patient_id",
+	"dentist_id":  "a.dentist_id",
+	"date":        "a.date",
+	"duration":    "a.duration",
+	"description": "a.description",
+	"status":      "a.status",
+	"series_id":   "a.series_id",
+}
+
+// sortColumns maps the fields appointments can be sorted by to their columns.
+var sortColumns = map[string]string{
+	"id":         "a.id",
+	"date":       "a.date",
+	"patient_id": "a.patient_id",
+	"dentist_id": "a.dentist_id",
+	"status":     "a.status",
+}
+
+// GenerateQuery handles query creation to filter dynamically based on params, for the requested page sorted by order,
+// reading only the given fields. It returns the query and the arguments of its placeholders, filter values are never
+// written in the query itself. Pages read one more row than their size, to know whether there are more rows after them.
+func GenerateQuery(filter map[string]string, page pagination.Request, order []string, fields []string) (string, []any, error) {
+	after, err := cursorValues(order, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", listing.ErrInvalidField, field)
+		}
+
+		selected = append(selected, column)
+	}
 
 	var dqb query_builder.DynamicQueryBuilder
-	query := dqb.And(
+
+	keyset, err := dqb.After(sortColumns, order, after...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dqb, err = dqb.And(
+		generateFilter(filter),
+		keyset,
+	).OrderBy(sortColumns, order...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if page.Paginated() {
+		dqb = dqb.Limit(page.Offset(), page.Size+1)
+	}
+
+	query, args := dqb.BindSql(fmt.Sprintf(QueryGetAllAppointment, strings.Join(selected, ", ")))
+
+	return query, args, nil
+}
+
+// GenerateCountQuery handles query creation to count the appointments matching the filters.
+func GenerateCountQuery(filter map[string]string) (string, []any) {
+	return generateFilter(filter).BindCondition(QueryCountAppointment)
+}
+
+// scanTarget returns where the column of a field is scanned to, series_id is scanned to seriesID since it's nullable.
+func scanTarget(a *appointment.Appointment, seriesID *sql.NullString, field string) interface{} {
+	switch field {
+	case "id":
+		return &a.ID
+	case "patient_id":
+		return &a.PatientID
+	case "dentist_id":
+		return &a.DentistID
+	case "date":
+		return &a.Date.Time
+	case "duration":
+		return &a.Duration
+	case "description":
+		return &a.Description
+	case "status":
+		return &a.Status
+	default:
+		return seriesID
+	}
+}
+
+// cursorKeys returns the keys of the cursor of the page after an appointment, its values of the fields sorted by.
+func cursorKeys(a appointment.Appointment, order []string) []string {
+	keys := make([]string, 0, len(order))
+
+	for _, field := range order {
+		switch listing.Field(field) {
+		case "date":
+			keys = append(keys, a.Date.Format(time.DateTime))
+		case "patient_id":
+			keys = append(keys, strconv.Itoa(a.PatientID))
+		case "dentist_id":
+			keys = append(keys, strconv.Itoa(a.DentistID))
+		case "status":
+			keys = append(keys, string(a.Status))
+		default:
+			keys = append(keys, strconv.Itoa(a.ID))
+		}
+	}
+
+	return keys
+}
+
+// cursorValues parses the keys of the cursor, the values of the fields sorted by of the last appointment of the
+// previous page.
+func cursorValues(order []string, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(order) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(keys))
+
+	for i, field := range order {
+		switch listing.Field(field) {
+		case "date":
+			date, err := time.Parse(time.DateTime, keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, date)
+		case "status":
+			values = append(values, keys[i])
+		default:
+			id, err := strconv.Atoi(keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, id)
+		}
+	}
+
+	return values, nil
+}
+
+// generateFilter builds the condition matching the filters.
+func generateFilter(filter map[string]string) query_builder.DynamicQueryBuilder {
+	var dqb query_builder.DynamicQueryBuilder
+
+	return dqb.And(
 		dqb.NewExpression("a.patient_id", "=", filter["patient_id"]),
 		dqb.NewExpression("a.dentist_id", "=", filter["dentist_id"]),
 		dqb.NewExpression("p.dni", "=", filter["dni"]),
-		dqb.NewExpression("a.date", ">=", filter["from_date"]),
+		dqb.NewExpression("DATE_ADD(a.date, INTERVAL a.duration MINUTE)", ">", filter["from_date"]),
 		dqb.NewExpression("a.date", "<=", filter["to_date"]),
-	).Limit(offset, limit).BindSql(QueryGetAllAppointment)
-	return query
+		dqb.NewExpression("a.status", "=", filter["status"]),
+		dqb.NewExpression("a.series_id", "=", filter["series_id"]),
+	)
 }
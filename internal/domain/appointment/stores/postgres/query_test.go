@@ -0,0 +1,107 @@
+package postgres_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/postgres"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// injection is a value trying to break out of its placeholder, it must only ever be sent as an argument.
+const injection = "scheduled'; DROP TABLE appointment; --"
+
+func TestGenerateQuery(t *testing.T) {
+	filter := map[string]string{
+		"dentist_id": "3",
+		"from_date":  "2024-03-01 00:00:00",
+		"status":     injection,
+	}
+
+	page := pagination.Request{Size: 10, After: []string{"2024-03-04 10:30:00", "7"}}
+
+	query, args, err := postgres.GenerateQuery(filter, page, []string{"date", "id"}, []string{"id", "date"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The quoted interval is written by the store, so its text is kept while the placeholders around it are numbered.
+	want := fmt.Sprintf(postgres.QueryGetAllAppointment, "a.id, a.date") +
+		" WHERE ((a.dentist_id = $1 AND a.date + a.duration * INTERVAL '1 minute' > $2 AND a.status = $3)" +
+		" AND (a.date > $4 OR (a.date = $5 AND a.id > $6))) ORDER BY a.date ASC, a.id ASC LIMIT $7 OFFSET $8"
+	if query != want {
+		t.Errorf("query is %q, want %q", query, want)
+	}
+
+	date := time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC)
+
+	wantArgs := []any{"3", "2024-03-01 00:00:00", injection, date, date, 7, 11, 0}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args are %#v, want %#v", args, wantArgs)
+	}
+
+	query, args = postgres.GenerateCountQuery(filter)
+
+	want = postgres.QueryCountAppointment +
+		" WHERE (a.dentist_id = $1 AND a.date + a.duration * INTERVAL '1 minute' > $2 AND a.status = $3)"
+	if query != want {
+		t.Errorf("count query is %q, want %q", query, want)
+	}
+
+	wantArgs = []any{"3", "2024-03-01 00:00:00", injection}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("count args are %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestGenerateQueryInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   pagination.Request
+		order  []string
+		fields []string
+		err    error
+	}{
+		{
+			name:   "cursor with a value that isn't a date",
+			page:   pagination.Request{Size: 10, After: []string{injection, "7"}},
+			order:  []string{"date", "id"},
+			fields: []string{"id"},
+			err:    pagination.ErrInvalidCursor,
+		},
+		{
+			name:   "cursor with fewer values than the fields sorted by",
+			page:   pagination.Request{Size: 10, After: []string{"7"}},
+			order:  []string{"date", "id"},
+			fields: []string{"id"},
+			err:    pagination.ErrInvalidCursor,
+		},
+		{
+			name:   "field",
+			page:   pagination.All,
+			order:  []string{"id"},
+			fields: []string{"id, (SELECT 1)"},
+			err:    listing.ErrInvalidField,
+		},
+		{
+			name:   "order",
+			page:   pagination.All,
+			order:  []string{"id; DROP TABLE appointment"},
+			fields: []string{"id"},
+			err:    query_builder.ErrInvalidOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := postgres.GenerateQuery(map[string]string{}, tt.page, tt.order, tt.fields)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
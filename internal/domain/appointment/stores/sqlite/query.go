@@ -0,0 +1,211 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	QueryGetAllAppointment = `SELECT %s
+	FROM appointment a INNER JOIN patient p on a.patient_id = p.id`
+
+	QueryCountAppointment = `SELECT COUNT(*)
+	FROM appointment a INNER JOIN patient p on a.patient_id = p.id`
+
+	QueryGetAppointmentByID = `SELECT id, patient_id, dentist_id, date, duration, description, status, series_id
+	FROM appointment WHERE id = ?`
+
+	QueryInsertAppointment = `INSERT INTO appointment(patient_id,dentist_id,date,duration,description,status,series_id)
+	VALUES(?,?,?,?,?,?,?)`
+
+	QueryUpdateAppointment = `UPDATE appointment SET patient_id = ?, dentist_id = ?, date = ?, duration = ?, description = ?
+	WHERE id = ?`
+
+	QueryUpdateAppointmentStatus = `UPDATE appointment SET status = ? WHERE id = ? AND status = ?`
+
+	QueryCancelSeries = `UPDATE appointment SET status = ?
+	WHERE series_id = ? AND date >= ? AND status IN (?, ?)`
+
+	QueryDeleteAppointment = `DELETE FROM appointment WHERE id = ?`
+
+	QueryDentistActive = `SELECT active FROM dentist WHERE id = ?`
+
+	QueryPatientActive = `SELECT active FROM patient WHERE id = ?`
+
+	QueryCountAppointmentsInSlot = `SELECT COUNT(*) FROM appointment
+	WHERE id <> ? AND status <> ? AND date < ? AND datetime(date, '+' || duration || ' minutes') > ?
+	AND (dentist_id = ? OR patient_id = ?)`
+)
+
+// columns maps the fields of appointments to their columns.
+var columns = map[string]string{
+	"id":          "a.id",
+	"patient_id":  "a.patient_id",
+	"dentist_id":  "a.dentist_id",
+	"date":        "a.date",
+	"duration":    "a.duration",
+	"description": "a.description",
+	"status":      "a.status",
+	"series_id":   "a.series_id",
+}
+
+// sortColumns maps the fields appointments can be sorted by to their columns.
+var sortColumns = map[string]string{
+	"id":         "a.id",
+	"date":       "a.date",
+	"patient_id": "a.patient_id",
+	"dentist_id": "a.dentist_id",
+	"status":     "a.status",
+}
+
+// GenerateQuery handles query creation to filter dynamically based on params, for the requested page sorted by order,
+// reading only the given fields. It returns the query and the arguments of its placeholders, filter values are never
+// written in the query itself. Pages read one more row than their size, to know whether there are more rows after them.
+func GenerateQuery(filter map[string]string, page pagination.Request, order []string, fields []string) (string, []any, error) {
+	after, err := cursorValues(order, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", listing.ErrInvalidField, field)
+		}
+
+		selected = append(selected, column)
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	keyset, err := dqb.After(sortColumns, order, after...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dqb, err = dqb.And(
+		generateFilter(filter),
+		keyset,
+	).OrderBy(sortColumns, order...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if page.Paginated() {
+		dqb = dqb.Limit(page.Offset(), page.Size+1)
+	}
+
+	query, args := dqb.BindSql(fmt.Sprintf(QueryGetAllAppointment, strings.Join(selected, ", ")))
+
+	return query, args, nil
+}
+
+// GenerateCountQuery handles query creation to count the appointments matching the filters.
+func GenerateCountQuery(filter map[string]string) (string, []any) {
+	return generateFilter(filter).BindCondition(QueryCountAppointment)
+}
+
+// scanTarget returns where the column of a field is scanned to, series_id is scanned to seriesID since it's nullable.
+func scanTarget(a *appointment.Appointment, seriesID *sql.NullString, field string) interface{} {
+	switch field {
+	case "id":
+		return &a.ID
+	case "patient_id":
+		return &a.PatientID
+	case "dentist_id":
+		return &a.DentistID
+	case "date":
+		return &a.Date.Time
+	case "duration":
+		return &a.Duration
+	case "description":
+		return &a.Description
+	case "status":
+		return &a.Status
+	default:
+		return seriesID
+	}
+}
+
+// cursorKeys returns the keys of the cursor of the page after an appointment, its values of the fields sorted by.
+func cursorKeys(a appointment.Appointment, order []string) []string {
+	keys := make([]string, 0, len(order))
+
+	for _, field := range order {
+		switch listing.Field(field) {
+		case "date":
+			keys = append(keys, a.Date.Format(time.DateTime))
+		case "patient_id":
+			keys = append(keys, strconv.Itoa(a.PatientID))
+		case "dentist_id":
+			keys = append(keys, strconv.Itoa(a.DentistID))
+		case "status":
+			keys = append(keys, string(a.Status))
+		default:
+			keys = append(keys, strconv.Itoa(a.ID))
+		}
+	}
+
+	return keys
+}
+
+// cursorValues parses the keys of the cursor, the values of the fields sorted by of the last appointment of the
+// previous page.
+func cursorValues(order []string, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(order) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(keys))
+
+	for i, field := range order {
+		switch listing.Field(field) {
+		case "date":
+			// Dates are compared as text, the key is only checked.
+			_, err := time.Parse(time.DateTime, keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, keys[i])
+		case "status":
+			values = append(values, keys[i])
+		default:
+			id, err := strconv.Atoi(keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, id)
+		}
+	}
+
+	return values, nil
+}
+
+// generateFilter builds the condition matching the filters.
+func generateFilter(filter map[string]string) query_builder.DynamicQueryBuilder {
+	var dqb query_builder.DynamicQueryBuilder
+
+	return dqb.And(
+		dqb.NewExpression("a.patient_id", "=", filter["patient_id"]),
+		dqb.NewExpression("a.dentist_id", "=", filter["dentist_id"]),
+		dqb.NewExpression("p.dni", "=", filter["dni"]),
+		dqb.NewExpression("datetime(a.date, '+' || a.duration || ' minutes')", ">", filter["from_date"]),
+		dqb.NewExpression("a.date", "<=", filter["to_date"]),
+		dqb.NewExpression("a.status", "=", filter["status"]),
+		dqb.NewExpression("a.series_id", "=", filter["series_id"]),
+	)
+}
@@ -0,0 +1,398 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"time"
+)
+
+// Store wraps all the operations to the database.
+type Store struct {
+	db *transaction.DB
+}
+
+// NewStore creates a new store.
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: transaction.NewDB(db),
+	}
+}
+
+// GetAll returns a page of the appointments matching the filters, with the fields and in the order of the options,
+// and the metadata of the page.
+func (s *Store) GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]appointment.Appointment, pagination.Meta, error) {
+	order := options.Order("id", "date")
+
+	fields := options.Fetch(order, "id")
+	if fields == nil {
+		fields = appointment.Fields
+	}
+
+	query, args, err := GenerateQuery(filters, page, order, fields)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Meta{}, sqlite.CheckError(err)
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	appointmentsList := make([]appointment.Appointment, 0)
+
+	for rows.Next() {
+		var a appointment.Appointment
+		var seriesID sql.NullString
+
+		targets := make([]interface{}, 0, len(fields))
+		for _, field := range fields {
+			targets = append(targets, scanTarget(&a, &seriesID, field))
+		}
+
+		err = rows.Scan(targets...)
+		if err != nil {
+			return nil, pagination.Meta{}, sqlite.CheckError(err)
+		}
+
+		a.SeriesID = seriesID.String
+
+		appointmentsList = append(appointmentsList, a)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, pagination.Meta{}, sqlite.CheckError(err)
+	}
+
+	if !page.Paginated() {
+		return appointmentsList, pagination.NewMeta(page, len(appointmentsList), nil), nil
+	}
+
+	var next []string
+
+	if page.HasMore(len(appointmentsList)) {
+		appointmentsList = appointmentsList[:page.Size]
+
+		next = cursorKeys(appointmentsList[len(appointmentsList)-1], order)
+	}
+
+	var total int
+
+	countQuery, countArgs := GenerateCountQuery(filters)
+
+	err = s.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Meta{}, sqlite.CheckError(err)
+	}
+
+	return appointmentsList, pagination.NewMeta(page, total, next), nil
+}
+
+// GetByID returns an appointment by its ID.
+func (s *Store) GetByID(ctx context.Context, ID int) (appointment.Appointment, error) {
+	row := s.db.QueryRowContext(ctx, QueryGetAppointmentByID, ID)
+
+	var a appointment.Appointment
+	var seriesID sql.NullString
+
+	err := row.Scan(&a.ID, &a.PatientID, &a.DentistID, &a.Date.Time, &a.Duration, &a.Description, &a.Status, &seriesID)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBNoRows):
+			return appointment.Appointment{}, appointment.ErrNotFound
+		default:
+			return appointment.Appointment{}, err
+		}
+	}
+
+	a.SeriesID = seriesID.String
+
+	return a, nil
+}
+
+// Create creates a new appointment.
+func (s *Store) Create(ctx context.Context, a appointment.Appointment) (appointment.Appointment, error) {
+	appointments, err := s.create(ctx, []appointment.Appointment{a})
+	if err != nil {
+		return appointment.Appointment{}, err
+	}
+
+	return appointments[0], nil
+}
+
+// CreateSeries creates all the occurrences of a series in a single transaction.
+func (s *Store) CreateSeries(ctx context.Context, appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	return s.create(ctx, appointments)
+}
+
+// Update updates an appointment.
+func (s *Store) Update(ctx context.Context, a appointment.Appointment) (appointment.Appointment, error) {
+	appointments, err := s.update(ctx, []appointment.Appointment{a})
+	if err != nil {
+		return appointment.Appointment{}, err
+	}
+
+	return appointments[0], nil
+}
+
+// UpdateSeries updates many occurrences of a series in a single transaction.
+func (s *Store) UpdateSeries(ctx context.Context, appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	return s.update(ctx, appointments)
+}
+
+// UpdateStatus moves an appointment from one status to another.
+func (s *Store) UpdateStatus(ctx context.Context, ID int, from appointment.Status, to appointment.Status) error {
+	result, err := s.db.ExecContext(ctx, QueryUpdateAppointmentStatus, to, ID, from)
+	if err != nil {
+		return sqlite.CheckError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return sqlite.CheckError(err)
+	}
+
+	// The appointment changed its status since it was read.
+	if rowsAffected < 1 {
+		return appointment.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+// CancelSeries cancels the scheduled and confirmed occurrences of a series starting from a date.
+func (s *Store) CancelSeries(ctx context.Context, seriesID string, from time.Time) error {
+	_, err := s.db.ExecContext(ctx, QueryCancelSeries, appointment.StatusCancelled, seriesID, sqlite.Time(from),
+		appointment.StatusScheduled, appointment.StatusConfirmed)
+	if err != nil {
+		return sqlite.CheckError(err)
+	}
+
+	return nil
+}
+
+// Delete deletes an appointment.
+func (s *Store) Delete(ctx context.Context, ID int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeleteAppointment, ID)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBNoRows):
+			return appointment.ErrNotFound
+		case errors.Is(err, sqlite.ErrDBConflict):
+			return appointment.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return sqlite.CheckError(err)
+	}
+
+	if rowsAffected < 1 {
+		return appointment.ErrNotFound
+	}
+
+	return nil
+}
+
+// create inserts the appointments in a single transaction, reserving the slot of each one before inserting it, so
+// occurrences of the same series can't overlap each other either.
+func (s *Store) create(ctx context.Context, appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	defer rollback(tx)
+
+	statement, err := tx.PrepareContext(ctx, QueryInsertAppointment)
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	defer func(statement *sql.Stmt) {
+		err = statement.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(statement)
+
+	created := make([]appointment.Appointment, 0, len(appointments))
+
+	for _, a := range appointments {
+		err = reserveSlot(ctx, tx, a)
+		if err != nil {
+			return nil, occurrenceError(err, a, len(appointments))
+		}
+
+		result, err := statement.ExecContext(ctx, a.PatientID, a.DentistID, sqlite.Time(a.Date.Time), a.Duration, a.Description,
+			a.Status, nullString(a.SeriesID))
+		if err != nil {
+			return nil, writeError(err)
+		}
+
+		lastId, err := result.LastInsertId()
+		if err != nil {
+			return nil, sqlite.CheckError(err)
+		}
+
+		a.ID = int(lastId)
+		created = append(created, a)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	return created, nil
+}
+
+// update updates the appointments in a single transaction, reserving the new slot of each one before updating it.
+func (s *Store) update(ctx context.Context, appointments []appointment.Appointment) ([]appointment.Appointment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	defer rollback(tx)
+
+	statement, err := tx.PrepareContext(ctx, QueryUpdateAppointment)
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	defer func(statement *sql.Stmt) {
+		err = statement.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(statement)
+
+	for _, a := range appointments {
+		err = reserveSlot(ctx, tx, a)
+		if err != nil {
+			return nil, occurrenceError(err, a, len(appointments))
+		}
+
+		_, err = statement.ExecContext(ctx, a.PatientID, a.DentistID, sqlite.Time(a.Date.Time), a.Duration, a.Description, a.ID)
+		if err != nil {
+			return nil, writeError(err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	return appointments, nil
+}
+
+// writeError maps the errors of inserting or updating an appointment to the errors of the domain.
+func writeError(err error) error {
+	err = sqlite.CheckError(err)
+	switch {
+	case errors.Is(err, sqlite.ErrDBDuplicateEntry):
+		return appointment.ErrAlreadyExists
+	case errors.Is(err, sqlite.ErrDBConflict):
+		return appointment.ErrConflict
+	case errors.Is(err, sqlite.ErrDBValueExceeded):
+		return appointment.ErrValueExceeded
+	default:
+		return err
+	}
+}
+
+// occurrenceError adds the date of the appointment to the error when many appointments are saved at once, so it's
+// clear which occurrence of the series failed.
+func occurrenceError(err error, a appointment.Appointment, total int) error {
+	if total == 1 || (!errors.Is(err, appointment.ErrSlotTaken) && !errors.Is(err, appointment.ErrInactive)) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", err, a.Date.Format(time.DateTime))
+}
+
+// nullString returns a NULL string when s is empty.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// reserveSlot checks that the dentist and the patient of the appointment are active and none of them has another
+// appointment, not cancelled, overlapping it.
+// The transaction holds the only connection to the database, so concurrent bookings and deactivations involving any
+// of them are serialized until it ends.
+func reserveSlot(ctx context.Context, tx *transaction.Tx, a appointment.Appointment) error {
+	dentistActive, err := isActive(ctx, tx, QueryDentistActive, a.DentistID)
+	if err != nil {
+		return err
+	}
+
+	patientActive, err := isActive(ctx, tx, QueryPatientActive, a.PatientID)
+	if err != nil {
+		return err
+	}
+
+	if !dentistActive || !patientActive {
+		return appointment.ErrInactive
+	}
+
+	var count int
+
+	err = tx.QueryRowContext(ctx, QueryCountAppointmentsInSlot,
+		a.ID, appointment.StatusCancelled, sqlite.Time(a.End()), sqlite.Time(a.Date.Time), a.DentistID, a.PatientID).Scan(&count)
+	if err != nil {
+		return sqlite.CheckError(err)
+	}
+
+	if count > 0 {
+		return appointment.ErrSlotTaken
+	}
+
+	return nil
+}
+
+// isActive reports whether the row returned by the query is active.
+// A missing row is reported as active, the foreign keys will report it as a conflict later.
+func isActive(ctx context.Context, tx *transaction.Tx, query string, id int) (bool, error) {
+	var active bool
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(&active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+
+		return false, sqlite.CheckError(err)
+	}
+
+	return active, nil
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,144 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	sqliteAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	sqliteDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	sqlitePatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	sqliteSchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	sqliteWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/migrate"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/sqlite"
+	sqliteErrors "github.com/Nachofra/final-esp-backend-3/pkg/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentBookings books the same slot of a dentist for many patients at once, through the units of work of the
+// service, only one of them must get it.
+func TestConcurrentBookings(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.Open(sqlite.New(sqlite.WithPath(filepath.Join(t.TempDir(), "clinic.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrator, err := migrate.New(db, migrate.SQLite, sqlite.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := appointment.NewService(sqliteAppointment.NewStore(db), transaction.New(db, sql.LevelDefault, nil),
+		schedule.NewService(sqliteSchedule.NewStore(db)), waitlist.NewService(sqliteWaitlist.NewStore(db)), 30*time.Minute)
+
+	d, err := sqliteDentist.NewStore(db).Create(ctx, dentist.Dentist{FirstName: "Ana", LastName: "Díaz",
+		RegistrationNumber: 777001, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := nextMonday(10)
+
+	_, err = sqliteSchedule.NewStore(db).Create(ctx, schedule.Shift{DentistID: d.ID, Weekday: 1, StartTime: "09:00",
+		EndTime: "13:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const bookings = 8
+
+	patients := make([]patient.Patient, 0, bookings)
+	for i := 0; i < bookings; i++ {
+		p, err := sqlitePatient.NewStore(db).Create(ctx, patient.Patient{FirstName: "Juan", LastName: "Pérez",
+			Address: "Mitre 100", DNI: 30000000 + i, DischargeDate: custom_time.Time{Time: date.AddDate(-1, 0, 0)},
+			Active: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		patients = append(patients, p)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, bookings)
+
+	for i, p := range patients {
+		wg.Add(1)
+
+		go func(i int, p patient.Patient) {
+			defer wg.Done()
+
+			_, errs[i] = service.Create(ctx, appointment.NewAppointment{
+				PatientID:   p.ID,
+				DentistID:   d.ID,
+				Date:        custom_time.Time{Time: date},
+				Description: "Checkup",
+			})
+		}(i, p)
+	}
+
+	wg.Wait()
+
+	booked := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			booked++
+		case !errors.Is(err, appointment.ErrSlotTaken):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if booked != 1 {
+		t.Errorf("booked %d appointments in the same slot, want 1", booked)
+	}
+}
+
+// nextMonday returns the next Monday at the hour, in UTC.
+func nextMonday(hour int) time.Time {
+	day := time.Now().UTC().AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.UTC)
+}
+
+// TestCreateTimeout creates an appointment once the deadline of the context passed, the error of starting the
+// transaction must be reported as a database timeout like any other query of the store.
+func TestCreateTimeout(t *testing.T) {
+	db, err := sqlite.Open(sqlite.New(sqlite.WithPath(filepath.Join(t.TempDir(), "clinic.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err = sqliteAppointment.NewStore(db).Create(ctx, appointment.Appointment{PatientID: 1, DentistID: 1,
+		Date: custom_time.Time{Time: nextMonday(10)}, Duration: 30, Status: appointment.StatusScheduled})
+	if !errors.Is(err, sqliteErrors.ErrDBTimeout) {
+		t.Errorf("creating the appointment returned %v, want %v", err, sqliteErrors.ErrDBTimeout)
+	}
+}
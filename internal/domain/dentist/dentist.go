@@ -3,6 +3,12 @@ package dentist
 import (
 	"context"
 	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"time"
 )
 
 var (
@@ -13,35 +19,60 @@ var (
 )
 
 // Store specifies the contract needed for the Store in the Service.
+// Update never changes whether a dentist is active, that's only done by Deactivate and Reactivate.
 type Store interface {
 	Create(ctx context.Context, dentist Dentist) (Dentist, error)
-	GetAll(ctx context.Context) []Dentist
+	GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]Dentist, pagination.Meta, error)
 	GetByID(ctx context.Context, id int) (Dentist, error)
 	GetByRegistrationNumber(ctx context.Context, rn int) (Dentist, error)
 	Update(ctx context.Context, dentist Dentist) (Dentist, error)
+	Deactivate(ctx context.Context, id int) error
+	Reactivate(ctx context.Context, id int) error
 	Delete(ctx context.Context, id int) error
 }
 
+// Appointments specifies the contract needed to cancel the appointments of a dentist on deactivation.
+// CancelPending must take part in the unit of work of the context and leave offering the freed slots to the caller.
+type Appointments interface {
+	CancelPending(ctx context.Context, filters appointment.FilterAppointment, from time.Time) ([]appointment.Appointment, error)
+}
+
+// Waitlist specifies the contract needed to offer the slots freed by the appointments cancelled on deactivation.
+type Waitlist interface {
+	SlotFreed(ctx context.Context, appointment appointment.Appointment) error
+}
+
 // service unifies all the business operation for the domain.
 type service struct {
-	store Store
+	store        Store
+	transactions transaction.Manager
+	appointments Appointments
+	waitlist     Waitlist
 }
 
 // Service specifies the contract needed for the Service.
 type Service interface {
 	Create(ctx context.Context, newDentist NewDentist) (Dentist, error)
-	GetAll(ctx context.Context) []Dentist
+	GetAll(ctx context.Context, filters FilterDentist, page pagination.Request, options listing.Options) ([]Dentist, pagination.Meta, error)
 	GetByID(ctx context.Context, id int) (Dentist, error)
 	GetByRegistrationNumber(ctx context.Context, rn int) (Dentist, error)
 	Update(ctx context.Context, updateDentist UpdateDentist, id int) (Dentist, error)
 	Delete(ctx context.Context, id int) error
 	Patch(ctx context.Context, dentist Dentist, pd PatchDentist) (Dentist, error)
+	Deactivate(ctx context.Context, id int) error
+	Reactivate(ctx context.Context, id int) error
 }
 
 // NewService creates a new product service.
-func NewService(store Store) Service {
+// transactions runs the operations that read and then write in a unit of work, so nothing changes in between.
+// appointments cancels the pending appointments of a deactivated dentist as part of the same unit of work, and the
+// waitlist is told about every slot freed by a deactivation, once it's committed.
+func NewService(store Store, transactions transaction.Manager, appointments Appointments, waitlist Waitlist) Service {
 	return &service{
-		store: store,
+		store:        store,
+		transactions: transactions,
+		appointments: appointments,
+		waitlist:     waitlist,
 	}
 }
 
@@ -56,10 +87,16 @@ func (s *service) Create(ctx context.Context, newDentist NewDentist) (Dentist, e
 	return response, nil
 }
 
-// GetAll returns all products.
-func (s *service) GetAll(ctx context.Context) []Dentist {
-	dentists := s.store.GetAll(ctx)
-	return dentists
+// GetAll returns a page of dentists by filter.
+func (s *service) GetAll(ctx context.Context, filters FilterDentist, page pagination.Request, options listing.Options) ([]Dentist, pagination.Meta, error) {
+	f := filters.ToMap()
+
+	dentists, meta, err := s.store.GetAll(ctx, f, page, options)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return dentists, meta, nil
 }
 
 // GetByID returns a product by its ID.
@@ -82,11 +119,24 @@ func (s *service) GetByRegistrationNumber(ctx context.Context, dni int) (Dentist
 	return dentist, nil
 }
 
-// Update updates a product.
+// Update updates a product, it's read and written in a unit of work to keep whether it's active.
 func (s *service) Update(ctx context.Context, updateDentist UpdateDentist, id int) (Dentist, error) {
-	dentist := updateToDentist(updateDentist)
-	dentist.ID = id
-	response, err := s.store.Update(ctx, dentist)
+	var response Dentist
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		current, err := s.store.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		dentist := updateToDentist(updateDentist)
+		dentist.ID = id
+		dentist.Active = current.Active
+
+		response, err = s.store.Update(ctx, dentist)
+
+		return err
+	})
 	if err != nil {
 		return Dentist{}, err
 	}
@@ -126,12 +176,52 @@ func (s *service) Patch(ctx context.Context, dentist Dentist, pd PatchDentist) (
 	return d, nil
 }
 
+// Deactivate deactivates a dentist and cancels all its future appointments, their slots are offered to the waitlist.
+func (s *service) Deactivate(ctx context.Context, id int) error {
+	var cancelled []appointment.Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		err := s.store.Deactivate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		cancelled, err = s.appointments.CancelPending(ctx, appointment.FilterAppointment{DentistID: &id}, time.Now())
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The dentist is already deactivated at this point, so errors offering the slots are only logged.
+	for _, a := range cancelled {
+		err = s.waitlist.SlotFreed(ctx, a)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
+
+// Reactivate reactivates a dentist, the appointments cancelled by its deactivation are not restored.
+func (s *service) Reactivate(ctx context.Context, id int) error {
+	err := s.store.Reactivate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // newToDentist parses NewDentist to Dentist
 func newToDentist(newDentist NewDentist) Dentist {
 	var dentist Dentist
 	dentist.FirstName = newDentist.FirstName
 	dentist.LastName = newDentist.LastName
 	dentist.RegistrationNumber = newDentist.RegistrationNumber
+	dentist.Active = true
 
 	return dentist
 }
@@ -1,13 +1,28 @@
 package dentist
 
+import (
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Dentist describes a dentist.
+// Inactive dentists are kept for history, but they can't take new appointments.
 type Dentist struct {
 	ID                 int    `json:"id"`
 	FirstName          string `json:"first_name"`
 	LastName           string `json:"last_name"`
 	RegistrationNumber int    `json:"registration_number"`
+	Active             bool   `json:"active"`
 }
 
+// SortFields are the fields dentists can be sorted by, and Fields the ones that can be selected when listing them.
+var (
+	SortFields = []string{"id", "first_name", "last_name", "registration_number"}
+	Fields     = []string{"id", "first_name", "last_name", "registration_number", "active"}
+)
+
 // NewDentist describes the data needed to create a new Dentist.
 type NewDentist struct {
 	FirstName          string `json:"first_name"          validate:"required"`
@@ -28,3 +43,53 @@ type PatchDentist struct {
 	LastName           *string `json:"last_name"`
 	RegistrationNumber *int    `json:"registration_number"`
 }
+
+// FilterDentist describes the data needed to filter Dentists.
+// Name matches the dentists whose first or last name contains it, so it's never longer than them.
+// AppointmentsFrom and AppointmentsTo match the dentists with an appointment, not cancelled, starting between them.
+// Any end of the range can be left unset, and inactive dentists are only matched when IncludeInactive is set.
+// IDs matches the dentists with any of them, it's not a query parameter but lets the API load many dentists at once.
+type FilterDentist struct {
+	Name               *string           `form:"name"                validate:"omitempty,max=45"`
+	RegistrationNumber *int              `form:"registration_number" validate:"omitempty,min=1"`
+	AppointmentsFrom   *custom_time.Time `form:"appointments_from"`
+	AppointmentsTo     *custom_time.Time `form:"appointments_to"`
+	IncludeInactive    bool              `form:"include_inactive"`
+	IDs                []int             `form:"-"`
+}
+
+// ToMap parses FilterDentist to a map[string]string.
+func (fd FilterDentist) ToMap() map[string]string {
+	newMap := make(map[string]string)
+
+	if fd.Name != nil {
+		newMap["name"] = *fd.Name
+	}
+
+	if fd.RegistrationNumber != nil {
+		newMap["registration_number"] = strconv.Itoa(*fd.RegistrationNumber)
+	}
+
+	if fd.AppointmentsFrom != nil {
+		newMap["appointments_from"] = fd.AppointmentsFrom.Format(time.DateTime)
+	}
+
+	if fd.AppointmentsTo != nil {
+		newMap["appointments_to"] = fd.AppointmentsTo.Format(time.DateTime)
+	}
+
+	if fd.IncludeInactive {
+		newMap["include_inactive"] = strconv.FormatBool(fd.IncludeInactive)
+	}
+
+	if len(fd.IDs) > 0 {
+		ids := make([]string, 0, len(fd.IDs))
+		for _, id := range fd.IDs {
+			ids = append(ids, strconv.Itoa(id))
+		}
+
+		newMap["ids"] = strings.Join(ids, ",")
+	}
+
+	return newMap
+}
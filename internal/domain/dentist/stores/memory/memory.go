@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"strconv"
+)
+
+// Store wraps all the operations to the in-memory database.
+type Store struct {
+	db           *memory.DB
+	dentists     *memory.Table[dentist.Dentist]
+	appointments *memory.Table[appointment.Appointment]
+}
+
+// NewStore creates a new store, registration numbers are unique like in the SQL database.
+func NewStore(db *memory.DB) *Store {
+	dentists := memory.NewTable(db, "dentist", func(d *dentist.Dentist) *int { return &d.ID })
+	dentists.Unique("registration_number_UNIQUE", func(d dentist.Dentist) string {
+		return strconv.Itoa(d.RegistrationNumber)
+	})
+
+	return &Store{
+		db:           db,
+		dentists:     dentists,
+		appointments: memory.NewTable(db, "appointment", func(a *appointment.Appointment) *int { return &a.ID }),
+	}
+}
+
+// GetAll returns a page of the dentists matching the filters, in the order of the options, and the metadata of the
+// page. All the fields are returned, whatever the options select.
+func (s *Store) GetAll(_ context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]dentist.Dentist, pagination.Meta, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	dentistsList := make([]dentist.Dentist, 0)
+
+	for _, d := range s.dentists.Rows() {
+		if s.matches(d, filters) {
+			dentistsList = append(dentistsList, d)
+		}
+	}
+
+	dentistsList, meta, err := memory.Page(dentistsList, page, options.Order("id"), value)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return dentistsList, meta, nil
+}
+
+// GetByID returns a dentist by its ID.
+func (s *Store) GetByID(_ context.Context, id int) (dentist.Dentist, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	d, ok := s.dentists.Get(id)
+	if !ok {
+		return dentist.Dentist{}, dentist.ErrNotFound
+	}
+
+	return d, nil
+}
+
+// GetByRegistrationNumber returns a dentist by its RegistrationNumber.
+func (s *Store) GetByRegistrationNumber(_ context.Context, rn int) (dentist.Dentist, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	for _, d := range s.dentists.Rows() {
+		if d.RegistrationNumber == rn {
+			return d, nil
+		}
+	}
+
+	return dentist.Dentist{}, dentist.ErrNotFound
+}
+
+// Create creates a new dentist.
+func (s *Store) Create(_ context.Context, d dentist.Dentist) (dentist.Dentist, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	d, err := s.dentists.Insert(d)
+	if err != nil {
+		return dentist.Dentist{}, writeError(err)
+	}
+
+	return d, nil
+}
+
+// Update updates a dentist.
+func (s *Store) Update(_ context.Context, d dentist.Dentist) (dentist.Dentist, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.dentists.Update(d)
+	if err != nil {
+		return dentist.Dentist{}, writeError(err)
+	}
+
+	return d, nil
+}
+
+// Deactivate deactivates a dentist.
+func (s *Store) Deactivate(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	return s.setActive(id, false)
+}
+
+// Reactivate reactivates a dentist.
+func (s *Store) Reactivate(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	return s.setActive(id, true)
+}
+
+// Delete deletes a dentist, its shifts and waitlist entries are deleted too.
+func (s *Store) Delete(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.dentists.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, memory.ErrDBNoRows):
+			return dentist.ErrNotFound
+		case errors.Is(err, memory.ErrDBConflict):
+			return dentist.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setActive sets whether a dentist is active, it fails with dentist.ErrNotFound if the dentist doesn't exist.
+func (s *Store) setActive(id int, active bool) error {
+	d, ok := s.dentists.Get(id)
+	if !ok {
+		return dentist.ErrNotFound
+	}
+
+	d.Active = active
+
+	return s.dentists.Update(d)
+}
+
+// matches reports whether the dentist matches the filters, like the condition of the SQL stores.
+func (s *Store) matches(d dentist.Dentist, filters map[string]string) bool {
+	if filters["include_inactive"] != "true" && !d.Active {
+		return false
+	}
+
+	if ids, ok := filters["ids"]; ok && !memory.In(ids, d.ID) {
+		return false
+	}
+
+	if name, ok := filters["name"]; ok && !memory.Contains(d.FirstName, name) && !memory.Contains(d.LastName, name) {
+		return false
+	}
+
+	if rn, ok := filters["registration_number"]; ok && strconv.Itoa(d.RegistrationNumber) != rn {
+		return false
+	}
+
+	from, hasFrom := memory.ParseTime(filters["appointments_from"])
+	to, hasTo := memory.ParseTime(filters["appointments_to"])
+
+	if !hasFrom && !hasTo {
+		return true
+	}
+
+	for _, a := range s.appointments.Rows() {
+		if a.DentistID != d.ID || a.Status == appointment.StatusCancelled {
+			continue
+		}
+
+		if (!hasFrom || !a.Date.Before(from)) && (!hasTo || !a.Date.After(to)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// value returns the value of a field dentists can be sorted by, registration numbers are sorted as text like in the
+// SQL database.
+func value(d dentist.Dentist, field string) any {
+	switch field {
+	case "first_name":
+		return d.FirstName
+	case "last_name":
+		return d.LastName
+	case "registration_number":
+		return strconv.Itoa(d.RegistrationNumber)
+	default:
+		return d.ID
+	}
+}
+
+// writeError maps the errors of inserting or updating a dentist to the errors of the domain.
+func writeError(err error) error {
+	switch {
+	case errors.Is(err, memory.ErrDBNoRows):
+		return dentist.ErrNotFound
+	case errors.Is(err, memory.ErrDBDuplicateEntry):
+		return dentist.ErrAlreadyExists
+	case errors.Is(err, memory.ErrDBConflict):
+		return dentist.ErrConflict
+	default:
+		return err
+	}
+}
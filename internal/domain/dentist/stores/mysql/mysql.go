@@ -4,48 +4,94 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
 	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
 )
 
 const (
-	QueryGetAllDentist = `SELECT id, first_name, last_name, registration_number
+	QueryCountDentist = `SELECT COUNT(*) FROM clinic.dentist`
+
+	QueryDentistHasAppointments = `SELECT 1 FROM clinic.appointment a
+	WHERE a.dentist_id = dentist.id AND a.status <> 'cancelled'`
+
+	QueryGetAllDentist = `SELECT %s
 	FROM clinic.dentist`
 
-	QueryGetDentistById = `SELECT id, first_name, last_name, registration_number
+	QueryGetDentistById = `SELECT id, first_name, last_name, registration_number, active
 	FROM clinic.dentist WHERE id = ?`
 
-	QueryGetDentistByRegistrationNumber = `SELECT id, first_name, last_name, registration_number
+	QueryGetDentistByRegistrationNumber = `SELECT id, first_name, last_name, registration_number, active
 	FROM clinic.dentist WHERE registration_number = ?`
 
-	QueryInsertDentist = `INSERT INTO clinic.dentist(first_name,last_name,registration_number)
-	VALUES(?,?,?)`
+	QueryInsertDentist = `INSERT INTO clinic.dentist(first_name,last_name,registration_number,active)
+	VALUES(?,?,?,?)`
 
 	QueryUpdateDentist = `UPDATE clinic.dentist SET first_name = ?, last_name = ?, registration_number = ?
 	WHERE id = ?`
 
 	QueryDeleteDentist = `DELETE FROM clinic.dentist WHERE id = ?`
+
+	QueryLockDentist = `SELECT id FROM clinic.dentist WHERE id = ? FOR UPDATE`
+
+	QuerySetDentistActive = `UPDATE clinic.dentist SET active = ? WHERE id = ?`
 )
 
+// columns maps the fields of dentists to their columns.
+var columns = map[string]string{
+	"id":                  "id",
+	"first_name":          "first_name",
+	"last_name":           "last_name",
+	"registration_number": "registration_number",
+	"active":              "active",
+}
+
+// sortColumns maps the fields dentists can be sorted by to their columns.
+var sortColumns = map[string]string{
+	"id":                  "id",
+	"first_name":          "first_name",
+	"last_name":           "last_name",
+	"registration_number": "registration_number",
+}
+
 // Store wraps all the operations to the database.
 type Store struct {
-	db *sql.DB
+	db *transaction.DB
 }
 
 // New creates a new repository.
 func New(db *sql.DB) *Store {
 	return &Store{
-		db: db,
+		db: transaction.NewDB(db),
 	}
 }
 
-// GetAll returns all dentists.
-func (s *Store) GetAll(_ context.Context) []dentist.Dentist {
-	rows, err := s.db.Query(QueryGetAllDentist)
+// GetAll returns a page of the dentists matching the filters, with the fields and in the order of the options, and the
+// metadata of the page.
+func (s *Store) GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]dentist.Dentist, pagination.Meta, error) {
+	order := options.Order("id")
+
+	fields := options.Fetch(order, "id")
+	if fields == nil {
+		fields = dentist.Fields
+	}
+
+	query, args, err := GenerateQuery(filters, page, order, fields)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return []dentist.Dentist{}
+		return nil, pagination.Meta{}, mysql.CheckError(err)
 	}
 
 	defer func(rows *sql.Rows) {
@@ -60,25 +106,50 @@ func (s *Store) GetAll(_ context.Context) []dentist.Dentist {
 	for rows.Next() {
 		var d dentist.Dentist
 
-		err := rows.Scan(
-			&d.ID,
-			&d.FirstName,
-			&d.LastName,
-			&d.RegistrationNumber,
-		)
+		targets := make([]interface{}, 0, len(fields))
+		for _, field := range fields {
+			targets = append(targets, scanTarget(&d, field))
+		}
+
+		err := rows.Scan(targets...)
 		if err != nil {
-			return []dentist.Dentist{}
+			return nil, pagination.Meta{}, mysql.CheckError(err)
 		}
 
 		dentistsList = append(dentistsList, d)
 	}
 
-	return dentistsList
+	err = rows.Err()
+	if err != nil {
+		return nil, pagination.Meta{}, mysql.CheckError(err)
+	}
+
+	if !page.Paginated() {
+		return dentistsList, pagination.NewMeta(page, len(dentistsList), nil), nil
+	}
+
+	var next []string
+
+	if page.HasMore(len(dentistsList)) {
+		dentistsList = dentistsList[:page.Size]
+		next = cursorKeys(dentistsList[len(dentistsList)-1], order)
+	}
+
+	var total int
+
+	countQuery, countArgs := GenerateCountQuery(filters)
+
+	err = s.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Meta{}, mysql.CheckError(err)
+	}
+
+	return dentistsList, pagination.NewMeta(page, total, next), nil
 }
 
-// GetByID returns a dentist by its ID.
-func (s *Store) GetByID(_ context.Context, ID int) (dentist.Dentist, error) {
-	row := s.db.QueryRow(QueryGetDentistById, ID)
+// GetByID returns a dentist by its ID, locked until the unit of work of the context ends, when there is one.
+func (s *Store) GetByID(ctx context.Context, ID int) (dentist.Dentist, error) {
+	row := s.db.QueryRowContext(ctx, transaction.ForUpdate(ctx, QueryGetDentistById), ID)
 
 	var d dentist.Dentist
 
@@ -87,6 +158,7 @@ func (s *Store) GetByID(_ context.Context, ID int) (dentist.Dentist, error) {
 		&d.FirstName,
 		&d.LastName,
 		&d.RegistrationNumber,
+		&d.Active,
 	)
 	if err != nil {
 		err := mysql.CheckError(err)
@@ -101,9 +173,10 @@ func (s *Store) GetByID(_ context.Context, ID int) (dentist.Dentist, error) {
 	return d, nil
 }
 
-// GetByRegistrationNumber returns a dentist by its RegistrationNumber.
-func (s *Store) GetByRegistrationNumber(_ context.Context, rn int) (dentist.Dentist, error) {
-	row := s.db.QueryRow(QueryGetDentistByRegistrationNumber, rn)
+// GetByRegistrationNumber returns a dentist by its RegistrationNumber, locked until the unit of work of the context
+// ends, when there is one.
+func (s *Store) GetByRegistrationNumber(ctx context.Context, rn int) (dentist.Dentist, error) {
+	row := s.db.QueryRowContext(ctx, transaction.ForUpdate(ctx, QueryGetDentistByRegistrationNumber), rn)
 
 	var d dentist.Dentist
 
@@ -112,6 +185,7 @@ func (s *Store) GetByRegistrationNumber(_ context.Context, rn int) (dentist.Dent
 		&d.FirstName,
 		&d.LastName,
 		&d.RegistrationNumber,
+		&d.Active,
 	)
 	if err != nil {
 		err := mysql.CheckError(err)
@@ -127,8 +201,8 @@ func (s *Store) GetByRegistrationNumber(_ context.Context, rn int) (dentist.Dent
 }
 
 // Create creates a new dentist.
-func (s *Store) Create(_ context.Context, d dentist.Dentist) (dentist.Dentist, error) {
-	statement, err := s.db.Prepare(QueryInsertDentist)
+func (s *Store) Create(ctx context.Context, d dentist.Dentist) (dentist.Dentist, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryInsertDentist)
 	if err != nil {
 		return dentist.Dentist{}, err
 	}
@@ -140,10 +214,11 @@ func (s *Store) Create(_ context.Context, d dentist.Dentist) (dentist.Dentist, e
 		}
 	}(statement)
 
-	result, err := statement.Exec(
+	result, err := statement.ExecContext(ctx,
 		d.FirstName,
 		d.LastName,
 		d.RegistrationNumber,
+		d.Active,
 	)
 	if err != nil {
 		err := mysql.CheckError(err)
@@ -170,8 +245,8 @@ func (s *Store) Create(_ context.Context, d dentist.Dentist) (dentist.Dentist, e
 }
 
 // Update updates a dentist.
-func (s *Store) Update(_ context.Context, d dentist.Dentist) (dentist.Dentist, error) {
-	statement, err := s.db.Prepare(QueryUpdateDentist)
+func (s *Store) Update(ctx context.Context, d dentist.Dentist) (dentist.Dentist, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryUpdateDentist)
 	if err != nil {
 		return dentist.Dentist{}, err
 	}
@@ -183,7 +258,7 @@ func (s *Store) Update(_ context.Context, d dentist.Dentist) (dentist.Dentist, e
 		}
 	}(statement)
 
-	_, err = statement.Exec(
+	_, err = statement.ExecContext(ctx,
 		d.FirstName,
 		d.LastName,
 		d.RegistrationNumber,
@@ -206,9 +281,53 @@ func (s *Store) Update(_ context.Context, d dentist.Dentist) (dentist.Dentist, e
 	return d, nil
 }
 
+// Deactivate deactivates a dentist.
+func (s *Store) Deactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetDentistActive, false, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reactivate reactivates a dentist.
+func (s *Store) Reactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetDentistActive, true, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // Delete deletes a dentist.
-func (s *Store) Delete(_ context.Context, id int) error {
-	result, err := s.db.Exec(QueryDeleteDentist, id)
+func (s *Store) Delete(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeleteDentist, id)
 	if err != nil {
 		err := mysql.CheckError(err)
 		switch {
@@ -232,3 +351,162 @@ func (s *Store) Delete(_ context.Context, id int) error {
 
 	return nil
 }
+
+// GenerateQuery handles query creation to list a page of the dentists matching the filters, sorted by order and reading
+// only the given fields.
+// Pages read one more row than their size, to know whether there are more rows after them.
+func GenerateQuery(filter map[string]string, page pagination.Request, order []string, fields []string) (string, []any, error) {
+	after, err := cursorValues(order, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", listing.ErrInvalidField, field)
+		}
+
+		selected = append(selected, column)
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	keyset, err := dqb.After(sortColumns, order, after...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dqb, err = dqb.And(
+		generateFilter(filter),
+		keyset,
+	).OrderBy(sortColumns, order...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if page.Paginated() {
+		dqb = dqb.Limit(page.Offset(), page.Size+1)
+	}
+
+	query, args := dqb.BindSql(fmt.Sprintf(QueryGetAllDentist, strings.Join(selected, ", ")))
+
+	return query, args, nil
+}
+
+// GenerateCountQuery handles query creation to count the dentists matching the filters.
+func GenerateCountQuery(filter map[string]string) (string, []any) {
+	return generateFilter(filter).BindCondition(QueryCountDentist)
+}
+
+// generateFilter builds the condition matching the filters, only active dentists match unless inactive ones are included.
+func generateFilter(filter map[string]string) query_builder.DynamicQueryBuilder {
+	var active any
+	if filter["include_inactive"] != "true" {
+		active = true
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	return dqb.And(
+		dqb.NewExpression("active", "=", active),
+		dqb.In("id", query_builder.List(filter["ids"])...),
+		dqb.Or(
+			dqb.Contains("first_name", filter["name"]),
+			dqb.Contains("last_name", filter["name"]),
+		),
+		dqb.NewExpression("registration_number", "=", filter["registration_number"]),
+		dqb.Exists(QueryDentistHasAppointments, dqb.Between("a.date", filter["appointments_from"], filter["appointments_to"])),
+	)
+}
+
+// scanTarget returns where the column of a field is scanned to.
+func scanTarget(d *dentist.Dentist, field string) interface{} {
+	switch field {
+	case "id":
+		return &d.ID
+	case "first_name":
+		return &d.FirstName
+	case "last_name":
+		return &d.LastName
+	case "registration_number":
+		return &d.RegistrationNumber
+	default:
+		return &d.Active
+	}
+}
+
+// cursorKeys returns the keys of the cursor of the page after a dentist, its values of the fields sorted by.
+func cursorKeys(d dentist.Dentist, order []string) []string {
+	keys := make([]string, 0, len(order))
+
+	for _, field := range order {
+		switch listing.Field(field) {
+		case "first_name":
+			keys = append(keys, d.FirstName)
+		case "last_name":
+			keys = append(keys, d.LastName)
+		case "registration_number":
+			keys = append(keys, strconv.Itoa(d.RegistrationNumber))
+		default:
+			keys = append(keys, strconv.Itoa(d.ID))
+		}
+	}
+
+	return keys
+}
+
+// cursorValues parses the keys of the cursor, the values of the fields sorted by of the last dentist of the previous
+// page.
+func cursorValues(order []string, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(order) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(keys))
+
+	for i, field := range order {
+		switch listing.Field(field) {
+		case "id":
+			id, err := strconv.Atoi(keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, id)
+		default:
+			values = append(values, keys[i])
+		}
+	}
+
+	return values, nil
+}
+
+// lock locks the dentist row until the transaction ends, it fails with dentist.ErrNotFound if the dentist doesn't exist.
+func lock(ctx context.Context, tx *transaction.Tx, id int) error {
+	err := tx.QueryRowContext(ctx, QueryLockDentist, id).Scan(&id)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBNoRows):
+			return dentist.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
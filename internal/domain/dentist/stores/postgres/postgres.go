@@ -0,0 +1,511 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/postgres"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+)
+
+const (
+	QueryCountDentist = `SELECT COUNT(*) FROM dentist`
+
+	QueryDentistHasAppointments = `SELECT 1 FROM appointment a
+	WHERE a.dentist_id = dentist.id AND a.status <> 'cancelled'`
+
+	QueryGetAllDentist = `SELECT %s
+	FROM dentist`
+
+	QueryGetDentistById = `SELECT id, first_name, last_name, registration_number, active
+	FROM dentist WHERE id = $1`
+
+	QueryGetDentistByRegistrationNumber = `SELECT id, first_name, last_name, registration_number, active
+	FROM dentist WHERE registration_number = $1`
+
+	QueryInsertDentist = `INSERT INTO dentist(first_name,last_name,registration_number,active)
+	VALUES($1,$2,$3,$4) RETURNING id`
+
+	QueryUpdateDentist = `UPDATE dentist SET first_name = $1, last_name = $2, registration_number = $3
+	WHERE id = $4`
+
+	QueryDeleteDentist = `DELETE FROM dentist WHERE id = $1`
+
+	QueryLockDentist = `SELECT id FROM dentist WHERE id = $1 FOR UPDATE`
+
+	QuerySetDentistActive = `UPDATE dentist SET active = $1 WHERE id = $2`
+)
+
+// columns maps the fields of dentists to their columns.
+var columns = map[string]string{
+	"id":                  "id",
+	"first_name":          "first_name",
+	"last_name":           "last_name",
+	"registration_number": "registration_number",
+	"active":              "active",
+}
+
+// sortColumns maps the fields dentists can be sorted by to their columns.
+var sortColumns = map[string]string{
+	"id":                  "id",
+	"first_name":          "first_name",
+	"last_name":           "last_name",
+	"registration_number": "registration_number",
+}
+
+// Store wraps all the operations to the database.
+type Store struct {
+	db *transaction.DB
+}
+
+// NewStore creates a new repository.
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: transaction.NewDB(db),
+	}
+}
+
+// GetAll returns a page of the dentists matching the filters, with the fields and in the order of the options, and the
+// metadata of the page.
+func (s *Store) GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]dentist.Dentist, pagination.Meta, error) {
+	order := options.Order("id")
+
+	fields := options.Fetch(order, "id")
+	if fields == nil {
+		fields = dentist.Fields
+	}
+
+	query, args, err := GenerateQuery(filters, page, order, fields)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Meta{}, postgres.CheckError(err)
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	dentistsList := make([]dentist.Dentist, 0)
+
+	for rows.Next() {
+		var d dentist.Dentist
+
+		targets := make([]interface{}, 0, len(fields))
+		for _, field := range fields {
+			targets = append(targets, scanTarget(&d, field))
+		}
+
+		err := rows.Scan(targets...)
+		if err != nil {
+			return nil, pagination.Meta{}, postgres.CheckError(err)
+		}
+
+		dentistsList = append(dentistsList, d)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, pagination.Meta{}, postgres.CheckError(err)
+	}
+
+	if !page.Paginated() {
+		return dentistsList, pagination.NewMeta(page, len(dentistsList), nil), nil
+	}
+
+	var next []string
+
+	if page.HasMore(len(dentistsList)) {
+		dentistsList = dentistsList[:page.Size]
+		next = cursorKeys(dentistsList[len(dentistsList)-1], order)
+	}
+
+	var total int
+
+	countQuery, countArgs := GenerateCountQuery(filters)
+
+	err = s.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Meta{}, postgres.CheckError(err)
+	}
+
+	return dentistsList, pagination.NewMeta(page, total, next), nil
+}
+
+// GetByID returns a dentist by its ID, locked until the unit of work of the context ends, when there is one.
+func (s *Store) GetByID(ctx context.Context, ID int) (dentist.Dentist, error) {
+	row := s.db.QueryRowContext(ctx, transaction.ForUpdate(ctx, QueryGetDentistById), ID)
+
+	var d dentist.Dentist
+
+	err := row.Scan(
+		&d.ID,
+		&d.FirstName,
+		&d.LastName,
+		&d.RegistrationNumber,
+		&d.Active,
+	)
+	if err != nil {
+		err := postgres.CheckError(err)
+		switch {
+		case errors.Is(err, postgres.ErrDBNoRows):
+			return dentist.Dentist{}, dentist.ErrNotFound
+		default:
+			return dentist.Dentist{}, err
+		}
+	}
+
+	return d, nil
+}
+
+// GetByRegistrationNumber returns a dentist by its RegistrationNumber, locked until the unit of work of the context
+// ends, when there is one.
+func (s *Store) GetByRegistrationNumber(ctx context.Context, rn int) (dentist.Dentist, error) {
+	row := s.db.QueryRowContext(ctx, transaction.ForUpdate(ctx, QueryGetDentistByRegistrationNumber), strconv.Itoa(rn))
+
+	var d dentist.Dentist
+
+	err := row.Scan(
+		&d.ID,
+		&d.FirstName,
+		&d.LastName,
+		&d.RegistrationNumber,
+		&d.Active,
+	)
+	if err != nil {
+		err := postgres.CheckError(err)
+		switch {
+		case errors.Is(err, postgres.ErrDBNoRows):
+			return dentist.Dentist{}, dentist.ErrNotFound
+		default:
+			return dentist.Dentist{}, err
+		}
+	}
+
+	return d, nil
+}
+
+// Create creates a new dentist.
+func (s *Store) Create(ctx context.Context, d dentist.Dentist) (dentist.Dentist, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryInsertDentist)
+	if err != nil {
+		return dentist.Dentist{}, err
+	}
+
+	defer func(statement *sql.Stmt) {
+		err = statement.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(statement)
+
+	// The registration number is written as text, the type of its column.
+	err = statement.QueryRowContext(ctx,
+		d.FirstName,
+		d.LastName,
+		strconv.Itoa(d.RegistrationNumber),
+		d.Active,
+	).Scan(&d.ID)
+	if err != nil {
+		err := postgres.CheckError(err)
+		switch {
+		case errors.Is(err, postgres.ErrDBDuplicateEntry):
+			return dentist.Dentist{}, dentist.ErrAlreadyExists
+		case errors.Is(err, postgres.ErrDBConflict):
+			return dentist.Dentist{}, dentist.ErrConflict
+		case errors.Is(err, postgres.ErrDBValueExceeded):
+			return dentist.Dentist{}, dentist.ErrValueExceeded
+		default:
+			return dentist.Dentist{}, err
+		}
+	}
+
+	return d, nil
+}
+
+// Update updates a dentist.
+func (s *Store) Update(ctx context.Context, d dentist.Dentist) (dentist.Dentist, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryUpdateDentist)
+	if err != nil {
+		return dentist.Dentist{}, err
+	}
+
+	defer func(statement *sql.Stmt) {
+		err = statement.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(statement)
+
+	_, err = statement.ExecContext(ctx,
+		d.FirstName,
+		d.LastName,
+		strconv.Itoa(d.RegistrationNumber),
+		d.ID,
+	)
+	if err != nil {
+		err := postgres.CheckError(err)
+		switch {
+		case errors.Is(err, postgres.ErrDBDuplicateEntry):
+			return dentist.Dentist{}, dentist.ErrAlreadyExists
+		case errors.Is(err, postgres.ErrDBConflict):
+			return dentist.Dentist{}, dentist.ErrConflict
+		case errors.Is(err, postgres.ErrDBValueExceeded):
+			return dentist.Dentist{}, dentist.ErrValueExceeded
+		default:
+			return dentist.Dentist{}, err
+		}
+	}
+
+	return d, nil
+}
+
+// Deactivate deactivates a dentist.
+func (s *Store) Deactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetDentistActive, false, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reactivate reactivates a dentist.
+func (s *Store) Reactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetDentistActive, true, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a dentist.
+func (s *Store) Delete(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeleteDentist, id)
+	if err != nil {
+		err := postgres.CheckError(err)
+		switch {
+		case errors.Is(err, postgres.ErrDBNoRows):
+			return dentist.ErrNotFound
+		case errors.Is(err, postgres.ErrDBConflict):
+			return dentist.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return dentist.ErrNotFound
+	}
+
+	return nil
+}
+
+// GenerateQuery handles query creation to list a page of the dentists matching the filters, sorted by order and reading
+// only the given fields.
+// Pages read one more row than their size, to know whether there are more rows after them.
+// The placeholders of the query builder are numbered the way PostgreSQL writes them.
+func GenerateQuery(filter map[string]string, page pagination.Request, order []string, fields []string) (string, []any, error) {
+	after, err := cursorValues(order, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", listing.ErrInvalidField, field)
+		}
+
+		selected = append(selected, column)
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	keyset, err := dqb.After(sortColumns, order, after...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dqb, err = dqb.And(
+		generateFilter(filter),
+		keyset,
+	).OrderBy(sortColumns, order...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if page.Paginated() {
+		dqb = dqb.Limit(page.Offset(), page.Size+1)
+	}
+
+	query, args := dqb.BindSql(fmt.Sprintf(QueryGetAllDentist, strings.Join(selected, ", ")))
+
+	return postgres.Rebind(query), args, nil
+}
+
+// GenerateCountQuery handles query creation to count the dentists matching the filters.
+func GenerateCountQuery(filter map[string]string) (string, []any) {
+	query, args := generateFilter(filter).BindCondition(QueryCountDentist)
+
+	return postgres.Rebind(query), args
+}
+
+// generateFilter builds the condition matching the filters, only active dentists match unless inactive ones are included.
+// LIKE is case sensitive in PostgreSQL, so texts are compared in lower case, but unlike the collation of MySQL accents
+// are not ignored.
+func generateFilter(filter map[string]string) query_builder.DynamicQueryBuilder {
+	var active any
+	if filter["include_inactive"] != "true" {
+		active = true
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	return dqb.And(
+		dqb.NewExpression("active", "=", active),
+		dqb.In("id", query_builder.List(filter["ids"])...),
+		dqb.Or(
+			dqb.Contains("LOWER(first_name)", strings.ToLower(filter["name"])),
+			dqb.Contains("LOWER(last_name)", strings.ToLower(filter["name"])),
+		),
+		dqb.NewExpression("registration_number", "=", filter["registration_number"]),
+		dqb.Exists(QueryDentistHasAppointments, dqb.Between("a.date", filter["appointments_from"], filter["appointments_to"])),
+	)
+}
+
+// scanTarget returns where the column of a field is scanned to.
+func scanTarget(d *dentist.Dentist, field string) interface{} {
+	switch field {
+	case "id":
+		return &d.ID
+	case "first_name":
+		return &d.FirstName
+	case "last_name":
+		return &d.LastName
+	case "registration_number":
+		return &d.RegistrationNumber
+	default:
+		return &d.Active
+	}
+}
+
+// cursorKeys returns the keys of the cursor of the page after a dentist, its values of the fields sorted by.
+func cursorKeys(d dentist.Dentist, order []string) []string {
+	keys := make([]string, 0, len(order))
+
+	for _, field := range order {
+		switch listing.Field(field) {
+		case "first_name":
+			keys = append(keys, d.FirstName)
+		case "last_name":
+			keys = append(keys, d.LastName)
+		case "registration_number":
+			keys = append(keys, strconv.Itoa(d.RegistrationNumber))
+		default:
+			keys = append(keys, strconv.Itoa(d.ID))
+		}
+	}
+
+	return keys
+}
+
+// cursorValues parses the keys of the cursor, the values of the fields sorted by of the last dentist of the previous
+// page.
+func cursorValues(order []string, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(order) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(keys))
+
+	for i, field := range order {
+		switch listing.Field(field) {
+		case "id":
+			id, err := strconv.Atoi(keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, id)
+		default:
+			values = append(values, keys[i])
+		}
+	}
+
+	return values, nil
+}
+
+// lock locks the dentist row until the transaction ends, it fails with dentist.ErrNotFound if the dentist doesn't exist.
+func lock(ctx context.Context, tx *transaction.Tx, id int) error {
+	err := tx.QueryRowContext(ctx, QueryLockDentist, id).Scan(&id)
+	if err != nil {
+		err := postgres.CheckError(err)
+		switch {
+		case errors.Is(err, postgres.ErrDBNoRows):
+			return dentist.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
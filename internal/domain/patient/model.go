@@ -1,8 +1,14 @@
 package patient
 
-import "github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+import (
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Patient describes a patient.
+// Inactive patients are kept for history, but they can't take new appointments.
 type Patient struct {
 	ID            int              `json:"id"`
 	FirstName     string           `json:"first_name"`
@@ -10,8 +16,15 @@ type Patient struct {
 	Address       string           `json:"address"`
 	DNI           int              `json:"dni"`
 	DischargeDate custom_time.Time `json:"discharge_date"`
+	Active        bool             `json:"active"`
 }
 
+// SortFields are the fields patients can be sorted by, and Fields the ones that can be selected when listing them.
+var (
+	SortFields = []string{"id", "first_name", "last_name", "dni", "discharge_date"}
+	Fields     = []string{"id", "first_name", "last_name", "address", "dni", "discharge_date", "active"}
+)
+
 // NewPatient describes the data needed to create a new Patient.
 type NewPatient struct {
 	FirstName     string           `json:"first_name"     validate:"required"`
@@ -29,3 +42,88 @@ type PatchPatient struct {
 	DNI           *int              `json:"dni" validate:"omitempty,min=100000,max=99999999"`
 	DischargeDate *custom_time.Time `json:"discharge_date"`
 }
+
+// FilterPatient describes the data needed to filter Patients.
+// Name matches the patients whose first or last name contains it, and Address the ones whose address contains it.
+// AppointmentsFrom and AppointmentsTo match the patients with an appointment, not cancelled, starting between them.
+// Any end of a range can be left unset, and inactive patients are only matched when IncludeInactive is set.
+// IDs matches the patients with any of them, it's not a query parameter but lets the API load many patients at once.
+type FilterPatient struct {
+	Name             *string           `form:"name"`
+	DNI              *int              `form:"dni" validate:"omitempty,min=100000,max=99999999"`
+	Address          *string           `form:"address"`
+	DischargeFrom    *custom_time.Time `form:"discharge_from"`
+	DischargeTo      *custom_time.Time `form:"discharge_to"`
+	AppointmentsFrom *custom_time.Time `form:"appointments_from"`
+	AppointmentsTo   *custom_time.Time `form:"appointments_to"`
+	IncludeInactive  bool              `form:"include_inactive"`
+	IDs              []int             `form:"-"`
+}
+
+// ToMap parses FilterPatient to a map[string]string.
+func (fp FilterPatient) ToMap() map[string]string {
+	newMap := make(map[string]string)
+
+	if fp.Name != nil {
+		newMap["name"] = *fp.Name
+	}
+
+	if fp.DNI != nil {
+		newMap["dni"] = strconv.Itoa(*fp.DNI)
+	}
+
+	if fp.Address != nil {
+		newMap["address"] = *fp.Address
+	}
+
+	if fp.DischargeFrom != nil {
+		newMap["discharge_from"] = fp.DischargeFrom.Format(time.DateTime)
+	}
+
+	if fp.DischargeTo != nil {
+		newMap["discharge_to"] = fp.DischargeTo.Format(time.DateTime)
+	}
+
+	if fp.AppointmentsFrom != nil {
+		newMap["appointments_from"] = fp.AppointmentsFrom.Format(time.DateTime)
+	}
+
+	if fp.AppointmentsTo != nil {
+		newMap["appointments_to"] = fp.AppointmentsTo.Format(time.DateTime)
+	}
+
+	if fp.IncludeInactive {
+		newMap["include_inactive"] = strconv.FormatBool(fp.IncludeInactive)
+	}
+
+	if len(fp.IDs) > 0 {
+		ids := make([]string, 0, len(fp.IDs))
+		for _, id := range fp.IDs {
+			ids = append(ids, strconv.Itoa(id))
+		}
+
+		newMap["ids"] = strings.Join(ids, ",")
+	}
+
+	return newMap
+}
+
+// SearchPatient describes the data needed to search Patients by an approximate text.
+// When Limit is not set, the default limit of the service is used.
+type SearchPatient struct {
+	Query string `form:"q"     validate:"required,max=100"`
+	Limit int    `form:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+// Candidates describes the patients a search ranks, the active ones whose first name, last name or address contains
+// any of the Chunks, ignoring accents and case, or whose DNI starts with any of the DNIPrefixes.
+type Candidates struct {
+	Chunks      []string
+	DNIPrefixes []string
+}
+
+// Match describes a Patient found by a search and its relevance, from 0 to 1.
+type Match struct {
+	Patient Patient `json:"patient"`
+	Score   float64 `json:"score"`
+}
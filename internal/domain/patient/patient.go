@@ -3,6 +3,12 @@ package patient
 import (
 	"context"
 	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"time"
 )
 
 var (
@@ -10,44 +16,74 @@ var (
 	ErrConflict      = errors.New("constraint conflict while doing an action with the store layer")
 	ErrAlreadyExists = errors.New("patient already exists, dni must be unique")
 	ErrValueExceeded = errors.New("attribute value exceed type limit")
+	ErrInvalidQuery  = errors.New("the search query must have some letter or digit")
 )
 
 // Store specifies the contract needed for the Store in the Service.
+// Search returns the candidates of a search, which are ranked in the service, so every Store is searched the same.
+// Update never changes whether a patient is active, that's only done by Deactivate and Reactivate.
 type Store interface {
 	Create(ctx context.Context, patient Patient) (Patient, error)
-	GetAll(ctx context.Context) []Patient
+	GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]Patient, pagination.Meta, error)
 	GetByID(ctx context.Context, id int) (Patient, error)
 	GetByDNI(ctx context.Context, dni int) (Patient, error)
+	Search(ctx context.Context, candidates Candidates) ([]Patient, error)
 	Update(ctx context.Context, patient Patient) (Patient, error)
+	Deactivate(ctx context.Context, id int) error
+	Reactivate(ctx context.Context, id int) error
 	Delete(ctx context.Context, id int) error
 }
 
+// Appointments specifies the contract needed to cancel the appointments of a patient on deactivation.
+// CancelPending must take part in the unit of work of the context and leave offering the freed slots to the caller.
+type Appointments interface {
+	CancelPending(ctx context.Context, filters appointment.FilterAppointment, from time.Time) ([]appointment.Appointment, error)
+}
+
+// Waitlist specifies the contract needed to offer the slots freed by the appointments cancelled on deactivation.
+type Waitlist interface {
+	SlotFreed(ctx context.Context, appointment appointment.Appointment) error
+}
+
 // service unifies all the business operation for the domain.
 type service struct {
-	store Store
+	store        Store
+	transactions transaction.Manager
+	appointments Appointments
+	waitlist     Waitlist
 }
 
 // Service specifies the contract needed for the Service.
 type Service interface {
 	Create(ctx context.Context, newPatient NewPatient) (Patient, error)
-	GetAll(ctx context.Context) []Patient
+	GetAll(ctx context.Context, filters FilterPatient, page pagination.Request, options listing.Options) ([]Patient, pagination.Meta, error)
+	Search(ctx context.Context, sp SearchPatient) ([]Match, error)
 	GetByID(ctx context.Context, id int) (Patient, error)
 	GetByDNI(ctx context.Context, dni int) (Patient, error)
 	Update(ctx context.Context, newPatient NewPatient, id int) (Patient, error)
 	Patch(ctx context.Context, patient Patient, pp PatchPatient) (Patient, error)
+	Deactivate(ctx context.Context, id int) error
+	Reactivate(ctx context.Context, id int) error
 	Delete(ctx context.Context, id int) error
 }
 
 // NewService creates a new service.
-func NewService(store Store) Service {
+// transactions runs the operations that read and then write in a unit of work, so nothing changes in between.
+// appointments cancels the pending appointments of a deactivated patient as part of the same unit of work, and the
+// waitlist is told about every slot freed by a deactivation, once it's committed.
+func NewService(store Store, transactions transaction.Manager, appointments Appointments, waitlist Waitlist) Service {
 	return &service{
-		store: store,
+		store:        store,
+		transactions: transactions,
+		appointments: appointments,
+		waitlist:     waitlist,
 	}
 }
 
 // Create creates a new patient.
 func (s *service) Create(ctx context.Context, newPatient NewPatient) (Patient, error) {
 	patient := requestToPatient(newPatient)
+	patient.Active = true
 
 	response, err := s.store.Create(ctx, patient)
 	if err != nil {
@@ -57,10 +93,16 @@ func (s *service) Create(ctx context.Context, newPatient NewPatient) (Patient, e
 	return response, nil
 }
 
-// GetAll returns all patients.
-func (s *service) GetAll(ctx context.Context) []Patient {
-	patients := s.store.GetAll(ctx)
-	return patients
+// GetAll returns a page of patients by filter.
+func (s *service) GetAll(ctx context.Context, filters FilterPatient, page pagination.Request, options listing.Options) ([]Patient, pagination.Meta, error) {
+	f := filters.ToMap()
+
+	patients, meta, err := s.store.GetAll(ctx, f, page, options)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return patients, meta, nil
 }
 
 // GetByID returns a patient by its ID.
@@ -83,12 +125,24 @@ func (s *service) GetByDNI(ctx context.Context, dni int) (Patient, error) {
 	return patient, nil
 }
 
-// Update updates a patient.
+// Update updates a patient, it's read and written in a unit of work to keep whether it's active.
 func (s *service) Update(ctx context.Context, newPatient NewPatient, id int) (Patient, error) {
-	patient := requestToPatient(newPatient)
-	patient.ID = id
+	var response Patient
 
-	response, err := s.store.Update(ctx, patient)
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		current, err := s.store.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		patient := requestToPatient(newPatient)
+		patient.ID = id
+		patient.Active = current.Active
+
+		response, err = s.store.Update(ctx, patient)
+
+		return err
+	})
 	if err != nil {
 		return Patient{}, err
 	}
@@ -126,6 +180,45 @@ func (s *service) Patch(ctx context.Context, patient Patient, pp PatchPatient) (
 	return p, nil
 }
 
+// Deactivate deactivates a patient and cancels all its future appointments, their slots are offered to the waitlist.
+func (s *service) Deactivate(ctx context.Context, id int) error {
+	var cancelled []appointment.Appointment
+
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		err := s.store.Deactivate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		cancelled, err = s.appointments.CancelPending(ctx, appointment.FilterAppointment{PatientID: &id}, time.Now())
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The patient is already deactivated at this point, so errors offering the slots are only logged.
+	for _, a := range cancelled {
+		err = s.waitlist.SlotFreed(ctx, a)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
+
+// Reactivate reactivates a patient, the appointments cancelled by its deactivation are not restored.
+func (s *service) Reactivate(ctx context.Context, id int) error {
+	err := s.store.Reactivate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Delete deletes a patient.
 func (s *service) Delete(ctx context.Context, id int) error {
 	err := s.store.Delete(ctx, id)
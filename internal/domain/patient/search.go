@@ -0,0 +1,127 @@
+package patient
+
+import (
+	"context"
+	"github.com/Nachofra/final-esp-backend-3/pkg/fuzzy"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minScore is the lowest relevance of the patients found by a search.
+	minScore = 0.7
+	// defaultSearchLimit is how many patients are found when the limit is not set.
+	defaultSearchLimit = 10
+	// addressWeight lowers the relevance of the terms only found in the address, names are more relevant.
+	addressWeight = 0.8
+	// minDNIPrefix is the shortest prefix of a DNI a term is matched to.
+	minDNIPrefix = 3
+)
+
+// Search returns the active patients matching the query, from the most relevant to the least, and how relevant they
+// are. Every term of the query is matched to the first name, the last name, the address or the start of the DNI,
+// ignoring accents, case and small typos, and the relevance of a patient is the average of its terms.
+// Only the candidates of the terms are read from the store, never the whole table.
+func (s *service) Search(ctx context.Context, sp SearchPatient) ([]Match, error) {
+	terms := strings.Fields(fuzzy.Normalize(sp.Query))
+	if len(terms) == 0 {
+		return nil, ErrInvalidQuery
+	}
+
+	limit := sp.Limit
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+
+	patients, err := s.store.Search(ctx, candidates(terms))
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, 0)
+
+	for _, p := range patients {
+		score := relevance(p, terms)
+		if score >= minScore {
+			matches = append(matches, Match{Patient: p, Score: math.Round(score*1000) / 1000})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+
+		return matches[i].Patient.ID < matches[j].Patient.ID
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// candidates returns the candidates of the normalized terms. A patient matching the terms has one of them at least
+// as relevant as the minimum score, so a name or an address similar enough to it or a DNI starting with it.
+func candidates(terms []string) Candidates {
+	var c Candidates
+
+	seen := make(map[string]bool)
+
+	for _, term := range terms {
+		if isNumber(term) && len(term) >= minDNIPrefix {
+			c.DNIPrefixes = append(c.DNIPrefixes, term)
+		}
+
+		for _, chunk := range fuzzy.Chunks(term, minScore) {
+			if !seen[chunk] {
+				seen[chunk] = true
+				c.Chunks = append(c.Chunks, chunk)
+			}
+		}
+	}
+
+	return c
+}
+
+// relevance returns how well the patient matches the normalized terms, from 0 to 1.
+func relevance(p Patient, terms []string) float64 {
+	total := 0.0
+
+	for _, term := range terms {
+		total += termScore(p, term)
+	}
+
+	return total / float64(len(terms))
+}
+
+// termScore returns how well the patient matches a single term, the best of its fields.
+func termScore(p Patient, term string) float64 {
+	if isNumber(term) {
+		if len(term) >= minDNIPrefix && strings.HasPrefix(strconv.Itoa(p.DNI), term) {
+			return 1
+		}
+
+		return fuzzy.Score(term, p.Address) * addressWeight
+	}
+
+	return math.Max(
+		math.Max(fuzzy.Score(term, p.FirstName), fuzzy.Score(term, p.LastName)),
+		fuzzy.Score(term, p.Address)*addressWeight,
+	)
+}
+
+// isNumber reports whether s only has digits.
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return s != ""
+}
@@ -0,0 +1,134 @@
+package patient_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	memoryPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"reflect"
+	"testing"
+)
+
+// found is a patient found by a search and its relevance.
+type found struct {
+	ID    int
+	Score float64
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+
+	store := memoryPatient.NewStore(memory.New())
+
+	patients := []patient.Patient{
+		{FirstName: "Juan", LastName: "González", Address: "Av. Córdoba 1234", DNI: 30123456, Active: true},
+		{FirstName: "María", LastName: "Pérez", Address: "Mitre 100", DNI: 40123456, Active: true},
+		{FirstName: "Mario", LastName: "Pérez", Address: "Belgrano 200", DNI: 30199999, Active: true},
+		{FirstName: "Lucía", LastName: "Gonzalo", Address: "San Martín 850", DNI: 25000000, Active: true},
+		{FirstName: "Pedro", LastName: "González", Address: "Mitre 400", DNI: 30100000, Active: false},
+	}
+
+	for _, p := range patients {
+		_, err := store.Create(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := patient.NewService(store, transaction.None, nil, nil)
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []found
+	}{
+		{
+			name:  "accents and case are ignored",
+			query: "GONZALEZ",
+			want:  []found{{ID: 1, Score: 1}, {ID: 4, Score: 0.75}},
+		},
+		{
+			name:  "typo",
+			query: "gonzales",
+			want:  []found{{ID: 1, Score: 0.875}, {ID: 4, Score: 0.75}},
+		},
+		{
+			name:  "terms are averaged",
+			query: "juan gonzales",
+			want:  []found{{ID: 1, Score: 0.938}},
+		},
+		{
+			name:  "prefix of a name",
+			query: "gonz",
+			want:  []found{{ID: 1, Score: 0.9}, {ID: 4, Score: 0.9}},
+		},
+		{
+			name:  "prefix of a DNI",
+			query: "301",
+			want:  []found{{ID: 1, Score: 1}, {ID: 3, Score: 1}},
+		},
+		{
+			name:  "prefix of a DNI too short",
+			query: "30",
+		},
+		{
+			name:  "address is less relevant than names",
+			query: "cordoba",
+			want:  []found{{ID: 1, Score: 0.8}},
+		},
+		{
+			name:  "ties are sorted by ID",
+			query: "perez",
+			want:  []found{{ID: 2, Score: 1}, {ID: 3, Score: 1}},
+		},
+		{
+			name:  "limit",
+			query: "perez",
+			limit: 1,
+			want:  []found{{ID: 2, Score: 1}},
+		},
+		{
+			name:  "more relevant first",
+			query: "maria perez",
+			want:  []found{{ID: 2, Score: 1}, {ID: 3, Score: 0.9}},
+		},
+		{
+			name:  "nothing similar enough",
+			query: "rodriguez",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := service.Search(ctx, patient.SearchPatient{Query: tt.query, Limit: tt.limit})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]found, 0, len(matches))
+			for _, m := range matches {
+				got = append(got, found{ID: m.Patient.ID, Score: m.Score})
+			}
+
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searching %q found %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	service := patient.NewService(memoryPatient.NewStore(memory.New()), transaction.None, nil, nil)
+
+	_, err := service.Search(context.Background(), patient.SearchPatient{Query: "¿?!"})
+	if !errors.Is(err, patient.ErrInvalidQuery) {
+		t.Errorf("searching without letters or digits got %v, want %v", err, patient.ErrInvalidQuery)
+	}
+}
@@ -0,0 +1,275 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"github.com/Nachofra/final-esp-backend-3/pkg/fuzzy"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"strconv"
+	"strings"
+)
+
+// Store wraps all the operations to the in-memory database.
+type Store struct {
+	db           *memory.DB
+	patients     *memory.Table[patient.Patient]
+	appointments *memory.Table[appointment.Appointment]
+}
+
+// NewStore creates a new store, DNIs are unique like in the SQL database.
+func NewStore(db *memory.DB) *Store {
+	patients := memory.NewTable(db, "patient", func(p *patient.Patient) *int { return &p.ID })
+	patients.Unique("dni_UNIQUE", func(p patient.Patient) string {
+		return strconv.Itoa(p.DNI)
+	})
+
+	return &Store{
+		db:           db,
+		patients:     patients,
+		appointments: memory.NewTable(db, "appointment", func(a *appointment.Appointment) *int { return &a.ID }),
+	}
+}
+
+// GetAll returns a page of the patients matching the filters, in the order of the options, and the metadata of the
+// page. All the fields are returned, whatever the options select.
+func (s *Store) GetAll(_ context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]patient.Patient, pagination.Meta, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	patientsList := make([]patient.Patient, 0)
+
+	for _, p := range s.patients.Rows() {
+		if s.matches(p, filters) {
+			patientsList = append(patientsList, p)
+		}
+	}
+
+	patientsList, meta, err := memory.Page(patientsList, page, options.Order("id"), value)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return patientsList, meta, nil
+}
+
+// GetByID returns a patient by its ID.
+func (s *Store) GetByID(_ context.Context, id int) (patient.Patient, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	p, ok := s.patients.Get(id)
+	if !ok {
+		return patient.Patient{}, patient.ErrNotFound
+	}
+
+	return p, nil
+}
+
+// GetByDNI returns a patient by its DNI.
+func (s *Store) GetByDNI(_ context.Context, dni int) (patient.Patient, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	for _, p := range s.patients.Rows() {
+		if p.DNI == dni {
+			return p, nil
+		}
+	}
+
+	return patient.Patient{}, patient.ErrNotFound
+}
+
+// Search returns the candidates of a search, sorted by ID.
+func (s *Store) Search(_ context.Context, candidates patient.Candidates) ([]patient.Patient, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	patientsList := make([]patient.Patient, 0)
+
+	for _, p := range s.patients.Rows() {
+		if p.Active && candidate(p, candidates) {
+			patientsList = append(patientsList, p)
+		}
+	}
+
+	return patientsList, nil
+}
+
+// Create creates a new patient.
+func (s *Store) Create(_ context.Context, p patient.Patient) (patient.Patient, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	p, err := s.patients.Insert(p)
+	if err != nil {
+		return patient.Patient{}, writeError(err)
+	}
+
+	return p, nil
+}
+
+// Update updates a patient.
+func (s *Store) Update(_ context.Context, p patient.Patient) (patient.Patient, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.patients.Update(p)
+	if err != nil {
+		return patient.Patient{}, writeError(err)
+	}
+
+	return p, nil
+}
+
+// Deactivate deactivates a patient.
+func (s *Store) Deactivate(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	return s.setActive(id, false)
+}
+
+// Reactivate reactivates a patient.
+func (s *Store) Reactivate(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	return s.setActive(id, true)
+}
+
+// Delete deletes a patient, its waitlist entries are deleted too.
+func (s *Store) Delete(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.patients.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, memory.ErrDBNoRows):
+			return patient.ErrNotFound
+		case errors.Is(err, memory.ErrDBConflict):
+			return patient.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setActive sets whether a patient is active, it fails with patient.ErrNotFound if the patient doesn't exist.
+func (s *Store) setActive(id int, active bool) error {
+	p, ok := s.patients.Get(id)
+	if !ok {
+		return patient.ErrNotFound
+	}
+
+	p.Active = active
+
+	return s.patients.Update(p)
+}
+
+// candidate reports whether the patient is one of the candidates of a search, its texts are normalized like the chunks.
+func candidate(p patient.Patient, candidates patient.Candidates) bool {
+	texts := []string{fuzzy.Normalize(p.FirstName), fuzzy.Normalize(p.LastName), fuzzy.Normalize(p.Address)}
+
+	for _, chunk := range candidates.Chunks {
+		for _, text := range texts {
+			if strings.Contains(text, chunk) {
+				return true
+			}
+		}
+	}
+
+	for _, prefix := range candidates.DNIPrefixes {
+		if strings.HasPrefix(strconv.Itoa(p.DNI), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches reports whether the patient matches the filters, like the condition of the SQL stores.
+func (s *Store) matches(p patient.Patient, filters map[string]string) bool {
+	if filters["include_inactive"] != "true" && !p.Active {
+		return false
+	}
+
+	if ids, ok := filters["ids"]; ok && !memory.In(ids, p.ID) {
+		return false
+	}
+
+	if name, ok := filters["name"]; ok && !memory.Contains(p.FirstName, name) && !memory.Contains(p.LastName, name) {
+		return false
+	}
+
+	if dni, ok := filters["dni"]; ok && strconv.Itoa(p.DNI) != dni {
+		return false
+	}
+
+	if address, ok := filters["address"]; ok && !memory.Contains(p.Address, address) {
+		return false
+	}
+
+	if from, ok := memory.ParseTime(filters["discharge_from"]); ok && p.DischargeDate.Before(from) {
+		return false
+	}
+
+	if to, ok := memory.ParseTime(filters["discharge_to"]); ok && p.DischargeDate.After(to) {
+		return false
+	}
+
+	from, hasFrom := memory.ParseTime(filters["appointments_from"])
+	to, hasTo := memory.ParseTime(filters["appointments_to"])
+
+	if !hasFrom && !hasTo {
+		return true
+	}
+
+	for _, a := range s.appointments.Rows() {
+		if a.PatientID != p.ID || a.Status == appointment.StatusCancelled {
+			continue
+		}
+
+		if (!hasFrom || !a.Date.Before(from)) && (!hasTo || !a.Date.After(to)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// value returns the value of a field patients can be sorted by.
+func value(p patient.Patient, field string) any {
+	switch field {
+	case "first_name":
+		return p.FirstName
+	case "last_name":
+		return p.LastName
+	case "dni":
+		return p.DNI
+	case "discharge_date":
+		return p.DischargeDate.Time
+	default:
+		return p.ID
+	}
+}
+
+// writeError maps the errors of inserting or updating a patient to the errors of the domain.
+func writeError(err error) error {
+	switch {
+	case errors.Is(err, memory.ErrDBNoRows):
+		return patient.ErrNotFound
+	case errors.Is(err, memory.ErrDBDuplicateEntry):
+		return patient.ErrAlreadyExists
+	case errors.Is(err, memory.ErrDBConflict):
+		return patient.ErrConflict
+	default:
+		return err
+	}
+}
@@ -4,42 +4,92 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
 	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
-	QueryInsertPatient = `INSERT INTO clinic.patient(first_name,last_name,address,dni,discharge_date)
-	VALUES(?,?,?,?,?)`
-	QueryGetAllPatient = `SELECT id, first_name, last_name, address, dni, discharge_date
+	QueryInsertPatient = `INSERT INTO clinic.patient(first_name,last_name,address,dni,discharge_date,active)
+	VALUES(?,?,?,?,?,?)`
+	QueryCountPatient = `SELECT COUNT(*) FROM clinic.patient`
+
+	QueryPatientHasAppointments = `SELECT 1 FROM clinic.appointment a
+	WHERE a.patient_id = patient.id AND a.status <> 'cancelled'`
+
+	QueryGetAllPatient = `SELECT %s
 	FROM clinic.patient`
 	QueryDeletePatient  = `DELETE FROM clinic.patient WHERE id = ?`
-	QueryGetPatientByID = `SELECT id, first_name, last_name, address, dni, discharge_date
+	QueryGetPatientByID = `SELECT id, first_name, last_name, address, dni, discharge_date, active
 	FROM clinic.patient WHERE id = ?`
-	QueryGetPatientByDNI = `SELECT id, first_name, last_name, address, dni, discharge_date
+	QueryGetPatientByDNI = `SELECT id, first_name, last_name, address, dni, discharge_date, active
 	FROM clinic.patient WHERE dni = ?`
 	QueryUpdatePatient = `UPDATE clinic.patient SET first_name = ?, last_name = ?, address = ? , dni = ?, discharge_date = ?
 	WHERE id = ?`
+	QueryLockPatient      = `SELECT id FROM clinic.patient WHERE id = ? FOR UPDATE`
+	QuerySetPatientActive = `UPDATE clinic.patient SET active = ? WHERE id = ?`
+	QuerySearchPatient    = `SELECT id, first_name, last_name, address, dni, discharge_date, active
+	FROM clinic.patient`
 )
 
+// columns maps the fields of patients to their columns.
+var columns = map[string]string{
+	"id":             "id",
+	"first_name":     "first_name",
+	"last_name":      "last_name",
+	"address":        "address",
+	"dni":            "dni",
+	"discharge_date": "discharge_date",
+	"active":         "active",
+}
+
+// sortColumns maps the fields patients can be sorted by to their columns.
+var sortColumns = map[string]string{
+	"id":             "id",
+	"first_name":     "first_name",
+	"last_name":      "last_name",
+	"dni":            "dni",
+	"discharge_date": "discharge_date",
+}
+
 // Store wraps all the operations to the database.
 type Store struct {
-	db *sql.DB
+	db *transaction.DB
 }
 
 // NewStore creates a new repository.
 func NewStore(db *sql.DB) *Store {
 	return &Store{
-		db: db,
+		db: transaction.NewDB(db),
 	}
 }
 
-// GetAll returns all patients.
-func (s *Store) GetAll(_ context.Context) []patient.Patient {
-	rows, err := s.db.Query(QueryGetAllPatient)
+// GetAll returns a page of the patients matching the filters, with the fields and in the order of the options, and the
+// metadata of the page.
+func (s *Store) GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]patient.Patient, pagination.Meta, error) {
+	order := options.Order("id")
+
+	fields := options.Fetch(order, "id")
+	if fields == nil {
+		fields = patient.Fields
+	}
+
+	query, args, err := GenerateQuery(filters, page, order, fields)
 	if err != nil {
-		return []patient.Patient{}
+		return nil, pagination.Meta{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Meta{}, mysql.CheckError(err)
 	}
 
 	defer func(rows *sql.Rows) {
@@ -54,27 +104,50 @@ func (s *Store) GetAll(_ context.Context) []patient.Patient {
 	for rows.Next() {
 		var p patient.Patient
 
-		err = rows.Scan(
-			&p.ID,
-			&p.FirstName,
-			&p.LastName,
-			&p.Address,
-			&p.DNI,
-			&p.DischargeDate.Time,
-		)
+		targets := make([]interface{}, 0, len(fields))
+		for _, field := range fields {
+			targets = append(targets, scanTarget(&p, field))
+		}
+
+		err := rows.Scan(targets...)
 		if err != nil {
-			return []patient.Patient{}
+			return nil, pagination.Meta{}, mysql.CheckError(err)
 		}
 
 		patientsList = append(patientsList, p)
 	}
 
-	return patientsList
+	err = rows.Err()
+	if err != nil {
+		return nil, pagination.Meta{}, mysql.CheckError(err)
+	}
+
+	if !page.Paginated() {
+		return patientsList, pagination.NewMeta(page, len(patientsList), nil), nil
+	}
+
+	var next []string
+
+	if page.HasMore(len(patientsList)) {
+		patientsList = patientsList[:page.Size]
+		next = cursorKeys(patientsList[len(patientsList)-1], order)
+	}
+
+	var total int
+
+	countQuery, countArgs := GenerateCountQuery(filters)
+
+	err = s.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Meta{}, mysql.CheckError(err)
+	}
+
+	return patientsList, pagination.NewMeta(page, total, next), nil
 }
 
-// GetByID returns a patient by its ID.
-func (s *Store) GetByID(_ context.Context, id int) (patient.Patient, error) {
-	row := s.db.QueryRow(QueryGetPatientByID, id)
+// GetByID returns a patient by its ID, locked until the unit of work of the context ends, when there is one.
+func (s *Store) GetByID(ctx context.Context, id int) (patient.Patient, error) {
+	row := s.db.QueryRowContext(ctx, transaction.ForUpdate(ctx, QueryGetPatientByID), id)
 
 	var p patient.Patient
 
@@ -85,6 +158,7 @@ func (s *Store) GetByID(_ context.Context, id int) (patient.Patient, error) {
 		&p.Address,
 		&p.DNI,
 		&p.DischargeDate.Time,
+		&p.Active,
 	)
 	if err != nil {
 		err := mysql.CheckError(err)
@@ -99,9 +173,9 @@ func (s *Store) GetByID(_ context.Context, id int) (patient.Patient, error) {
 	return p, nil
 }
 
-// GetByDNI returns a patient by its DNI.
-func (s *Store) GetByDNI(_ context.Context, dni int) (patient.Patient, error) {
-	row := s.db.QueryRow(QueryGetPatientByDNI, dni)
+// GetByDNI returns a patient by its DNI, locked until the unit of work of the context ends, when there is one.
+func (s *Store) GetByDNI(ctx context.Context, dni int) (patient.Patient, error) {
+	row := s.db.QueryRowContext(ctx, transaction.ForUpdate(ctx, QueryGetPatientByDNI), dni)
 
 	var p patient.Patient
 
@@ -112,6 +186,7 @@ func (s *Store) GetByDNI(_ context.Context, dni int) (patient.Patient, error) {
 		&p.Address,
 		&p.DNI,
 		&p.DischargeDate.Time,
+		&p.Active,
 	)
 	if err != nil {
 		err := mysql.CheckError(err)
@@ -126,9 +201,49 @@ func (s *Store) GetByDNI(_ context.Context, dni int) (patient.Patient, error) {
 	return p, nil
 }
 
+// Search returns the candidates of a search, sorted by ID.
+func (s *Store) Search(ctx context.Context, candidates patient.Candidates) ([]patient.Patient, error) {
+	query, args, err := GenerateSearchQuery(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mysql.CheckError(err)
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	patientsList := make([]patient.Patient, 0)
+
+	for rows.Next() {
+		var p patient.Patient
+
+		err := rows.Scan(&p.ID, &p.FirstName, &p.LastName, &p.Address, &p.DNI, &p.DischargeDate.Time, &p.Active)
+		if err != nil {
+			return nil, mysql.CheckError(err)
+		}
+
+		patientsList = append(patientsList, p)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, mysql.CheckError(err)
+	}
+
+	return patientsList, nil
+}
+
 // Create creates a new patient.
-func (s *Store) Create(_ context.Context, p patient.Patient) (patient.Patient, error) {
-	statement, err := s.db.Prepare(QueryInsertPatient)
+func (s *Store) Create(ctx context.Context, p patient.Patient) (patient.Patient, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryInsertPatient)
 	if err != nil {
 		return patient.Patient{}, err
 	}
@@ -140,12 +255,13 @@ func (s *Store) Create(_ context.Context, p patient.Patient) (patient.Patient, e
 		}
 	}(statement)
 
-	result, err := statement.Exec(
+	result, err := statement.ExecContext(ctx,
 		p.FirstName,
 		p.LastName,
 		p.Address,
 		p.DNI,
 		p.DischargeDate.Time,
+		p.Active,
 	)
 	if err != nil {
 		err := mysql.CheckError(err)
@@ -172,8 +288,8 @@ func (s *Store) Create(_ context.Context, p patient.Patient) (patient.Patient, e
 }
 
 // Update updates a patient.
-func (s *Store) Update(_ context.Context, p patient.Patient) (patient.Patient, error) {
-	statement, err := s.db.Prepare(QueryUpdatePatient)
+func (s *Store) Update(ctx context.Context, p patient.Patient) (patient.Patient, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryUpdatePatient)
 	if err != nil {
 		return patient.Patient{}, err
 	}
@@ -185,7 +301,7 @@ func (s *Store) Update(_ context.Context, p patient.Patient) (patient.Patient, e
 		}
 	}(statement)
 
-	_, err = statement.Exec(
+	_, err = statement.ExecContext(ctx,
 		p.FirstName,
 		p.LastName,
 		p.Address,
@@ -210,9 +326,53 @@ func (s *Store) Update(_ context.Context, p patient.Patient) (patient.Patient, e
 	return p, nil
 }
 
+// Deactivate deactivates a patient.
+func (s *Store) Deactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetPatientActive, false, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reactivate reactivates a patient.
+func (s *Store) Reactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetPatientActive, true, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // Delete deletes a patient.
-func (s *Store) Delete(_ context.Context, id int) error {
-	result, err := s.db.Exec(QueryDeletePatient, id)
+func (s *Store) Delete(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeletePatient, id)
 	if err != nil {
 		err := mysql.CheckError(err)
 		switch {
@@ -236,3 +396,207 @@ func (s *Store) Delete(_ context.Context, id int) error {
 
 	return nil
 }
+
+// GenerateQuery handles query creation to list a page of the patients matching the filters, sorted by order and reading
+// only the given fields.
+// Pages read one more row than their size, to know whether there are more rows after them.
+func GenerateQuery(filter map[string]string, page pagination.Request, order []string, fields []string) (string, []any, error) {
+	after, err := cursorValues(order, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", listing.ErrInvalidField, field)
+		}
+
+		selected = append(selected, column)
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	keyset, err := dqb.After(sortColumns, order, after...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dqb, err = dqb.And(
+		generateFilter(filter),
+		keyset,
+	).OrderBy(sortColumns, order...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if page.Paginated() {
+		dqb = dqb.Limit(page.Offset(), page.Size+1)
+	}
+
+	query, args := dqb.BindSql(fmt.Sprintf(QueryGetAllPatient, strings.Join(selected, ", ")))
+
+	return query, args, nil
+}
+
+// GenerateCountQuery handles query creation to count the patients matching the filters.
+func GenerateCountQuery(filter map[string]string) (string, []any) {
+	return generateFilter(filter).BindCondition(QueryCountPatient)
+}
+
+// GenerateSearchQuery handles query creation to read the candidates of a search, it returns the query and the arguments
+// of its placeholders. The collation of the columns ignores accents and case, so the chunks are matched as they are.
+func GenerateSearchQuery(candidates patient.Candidates) (string, []any, error) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	matches := make([]interface{}, 0, 3*len(candidates.Chunks)+len(candidates.DNIPrefixes))
+
+	for _, chunk := range candidates.Chunks {
+		for _, column := range []string{"first_name", "last_name", "address"} {
+			matches = append(matches, dqb.Contains(column, chunk))
+		}
+	}
+
+	for _, prefix := range candidates.DNIPrefixes {
+		matches = append(matches, dqb.Prefix("CAST(dni AS CHAR)", prefix))
+	}
+
+	dqb, err := dqb.And(
+		dqb.NewExpression("active", "=", true),
+		dqb.Or(matches...),
+	).OrderBy(sortColumns, "id")
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, args := dqb.BindSql(QuerySearchPatient)
+
+	return query, args, nil
+}
+
+// generateFilter builds the condition matching the filters, only active patients match unless inactive ones are included.
+func generateFilter(filter map[string]string) query_builder.DynamicQueryBuilder {
+	var active any
+	if filter["include_inactive"] != "true" {
+		active = true
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	return dqb.And(
+		dqb.NewExpression("active", "=", active),
+		dqb.In("id", query_builder.List(filter["ids"])...),
+		dqb.Or(
+			dqb.Contains("first_name", filter["name"]),
+			dqb.Contains("last_name", filter["name"]),
+		),
+		dqb.NewExpression("dni", "=", filter["dni"]),
+		dqb.Contains("address", filter["address"]),
+		dqb.Between("discharge_date", filter["discharge_from"], filter["discharge_to"]),
+		dqb.Exists(QueryPatientHasAppointments, dqb.Between("a.date", filter["appointments_from"], filter["appointments_to"])),
+	)
+}
+
+// scanTarget returns where the column of a field is scanned to.
+func scanTarget(p *patient.Patient, field string) interface{} {
+	switch field {
+	case "id":
+		return &p.ID
+	case "first_name":
+		return &p.FirstName
+	case "last_name":
+		return &p.LastName
+	case "address":
+		return &p.Address
+	case "dni":
+		return &p.DNI
+	case "discharge_date":
+		return &p.DischargeDate.Time
+	default:
+		return &p.Active
+	}
+}
+
+// cursorKeys returns the keys of the cursor of the page after a patient, its values of the fields sorted by.
+func cursorKeys(p patient.Patient, order []string) []string {
+	keys := make([]string, 0, len(order))
+
+	for _, field := range order {
+		switch listing.Field(field) {
+		case "first_name":
+			keys = append(keys, p.FirstName)
+		case "last_name":
+			keys = append(keys, p.LastName)
+		case "dni":
+			keys = append(keys, strconv.Itoa(p.DNI))
+		case "discharge_date":
+			keys = append(keys, p.DischargeDate.Format(time.DateTime))
+		default:
+			keys = append(keys, strconv.Itoa(p.ID))
+		}
+	}
+
+	return keys
+}
+
+// cursorValues parses the keys of the cursor, the values of the fields sorted by of the last patient of the previous
+// page.
+func cursorValues(order []string, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(order) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(keys))
+
+	for i, field := range order {
+		switch listing.Field(field) {
+		case "id", "dni":
+			number, err := strconv.Atoi(keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, number)
+		case "discharge_date":
+			date, err := time.Parse(time.DateTime, keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, date)
+		default:
+			values = append(values, keys[i])
+		}
+	}
+
+	return values, nil
+}
+
+// lock locks the patient row until the transaction ends, it fails with patient.ErrNotFound if the patient doesn't exist.
+func lock(ctx context.Context, tx *transaction.Tx, id int) error {
+	err := tx.QueryRowContext(ctx, QueryLockPatient, id).Scan(&id)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBNoRows):
+			return patient.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,52 @@
+package patient_test
+
+import (
+	"fmt"
+	mysqlPatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"reflect"
+	"testing"
+)
+
+// injection is a value trying to break out of its placeholder, it must only ever be sent as an argument.
+const injection = "O'Brien'; DROP TABLE patient; --"
+
+func TestGenerateQuery(t *testing.T) {
+	filter := map[string]string{
+		"name":              injection,
+		"address":           "50%_off",
+		"appointments_from": "2024-01-01",
+	}
+
+	page := pagination.Request{Page: 2, Size: 10}
+
+	query, args, err := mysqlPatient.GenerateQuery(filter, page, []string{"-last_name", "id"}, []string{"id", "first_name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	condition := "(active = ? AND (first_name LIKE ? ESCAPE '!' OR last_name LIKE ? ESCAPE '!')" +
+		" AND address LIKE ? ESCAPE '!' AND EXISTS (" + mysqlPatient.QueryPatientHasAppointments + " AND a.date >= ?))"
+
+	want := fmt.Sprintf(mysqlPatient.QueryGetAllPatient, "id, first_name") + " WHERE " + condition +
+		" ORDER BY last_name DESC, id ASC LIMIT ? OFFSET ?"
+	if query != want {
+		t.Errorf("query is %q, want %q", query, want)
+	}
+
+	wantArgs := []any{true, "%" + injection + "%", "%" + injection + "%", "%50!%!_off%", "2024-01-01", 11, 10}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args are %#v, want %#v", args, wantArgs)
+	}
+
+	query, args = mysqlPatient.GenerateCountQuery(filter)
+
+	want = mysqlPatient.QueryCountPatient + " WHERE " + condition
+	if query != want {
+		t.Errorf("count query is %q, want %q", query, want)
+	}
+
+	if !reflect.DeepEqual(args, wantArgs[:5]) {
+		t.Errorf("count args are %#v, want %#v", args, wantArgs[:5])
+	}
+}
@@ -0,0 +1,58 @@
+package postgres_test
+
+import (
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/postgres"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"reflect"
+	"testing"
+)
+
+// injection is a value trying to break out of its placeholder, it must only ever be sent as an argument.
+const injection = "O'Brien'; DROP TABLE patient; --"
+
+func TestGenerateQuery(t *testing.T) {
+	filter := map[string]string{
+		"name":              injection,
+		"address":           "50%_off",
+		"appointments_from": "2024-01-01",
+	}
+
+	page := pagination.Request{Size: 10, After: []string{"Pérez", "7"}}
+
+	query, args, err := postgres.GenerateQuery(filter, page, []string{"-last_name", "id"}, []string{"id", "first_name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The quotes of the ESCAPE clauses and of the subquery don't stop the placeholders after them from being numbered.
+	want := fmt.Sprintf(postgres.QueryGetAllPatient, "id, first_name") +
+		" WHERE ((active = $1 AND (LOWER(first_name) LIKE $2 ESCAPE '!' OR LOWER(last_name) LIKE $3 ESCAPE '!')" +
+		" AND LOWER(address) LIKE $4 ESCAPE '!' AND EXISTS (" + postgres.QueryPatientHasAppointments +
+		" AND a.date >= $5)) AND (last_name < $6 OR (last_name = $7 AND id > $8)))" +
+		" ORDER BY last_name DESC, id ASC LIMIT $9 OFFSET $10"
+	if query != want {
+		t.Errorf("query is %q, want %q", query, want)
+	}
+
+	lower := "%o'brien'; drop table patient; --%"
+
+	wantArgs := []any{true, lower, lower, "%50!%!_off%", "2024-01-01", "Pérez", "Pérez", 7, 11, 0}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args are %#v, want %#v", args, wantArgs)
+	}
+
+	query, args = postgres.GenerateCountQuery(filter)
+
+	want = postgres.QueryCountPatient +
+		" WHERE (active = $1 AND (LOWER(first_name) LIKE $2 ESCAPE '!' OR LOWER(last_name) LIKE $3 ESCAPE '!')" +
+		" AND LOWER(address) LIKE $4 ESCAPE '!' AND EXISTS (" + postgres.QueryPatientHasAppointments +
+		" AND a.date >= $5))"
+	if query != want {
+		t.Errorf("count query is %q, want %q", query, want)
+	}
+
+	if !reflect.DeepEqual(args, wantArgs[:5]) {
+		t.Errorf("count args are %#v, want %#v", args, wantArgs[:5])
+	}
+}
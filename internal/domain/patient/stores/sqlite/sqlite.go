@@ -0,0 +1,606 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"github.com/Nachofra/final-esp-backend-3/pkg/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	QueryInsertPatient = `INSERT INTO patient(first_name,last_name,address,dni,discharge_date,active)
+	VALUES(?,?,?,?,?,?)`
+	QueryCountPatient = `SELECT COUNT(*) FROM patient`
+
+	QueryPatientHasAppointments = `SELECT 1 FROM appointment a
+	WHERE a.patient_id = patient.id AND a.status <> 'cancelled'`
+
+	QueryGetAllPatient = `SELECT %s
+	FROM patient`
+	QueryDeletePatient  = `DELETE FROM patient WHERE id = ?`
+	QueryGetPatientByID = `SELECT id, first_name, last_name, address, dni, discharge_date, active
+	FROM patient WHERE id = ?`
+	QueryGetPatientByDNI = `SELECT id, first_name, last_name, address, dni, discharge_date, active
+	FROM patient WHERE dni = ?`
+	QueryUpdatePatient = `UPDATE patient SET first_name = ?, last_name = ?, address = ? , dni = ?, discharge_date = ?
+	WHERE id = ?`
+	QueryLockPatient      = `SELECT id FROM patient WHERE id = ?`
+	QuerySetPatientActive = `UPDATE patient SET active = ? WHERE id = ?`
+	QuerySearchPatient    = `SELECT id, first_name, last_name, address, dni, discharge_date, active
+	FROM patient`
+)
+
+// columns maps the fields of patients to their columns.
+var columns = map[string]string{
+	"id":             "id",
+	"first_name":     "first_name",
+	"last_name":      "last_name",
+	"address":        "address",
+	"dni":            "dni",
+	"discharge_date": "discharge_date",
+	"active":         "active",
+}
+
+// sortColumns maps the fields patients can be sorted by to their columns.
+var sortColumns = map[string]string{
+	"id":             "id",
+	"first_name":     "first_name",
+	"last_name":      "last_name",
+	"dni":            "dni",
+	"discharge_date": "discharge_date",
+}
+
+// Store wraps all the operations to the database.
+type Store struct {
+	db *transaction.DB
+}
+
+// NewStore creates a new repository.
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: transaction.NewDB(db),
+	}
+}
+
+// GetAll returns a page of the patients matching the filters, with the fields and in the order of the options, and the
+// metadata of the page.
+func (s *Store) GetAll(ctx context.Context, filters map[string]string, page pagination.Request, options listing.Options) ([]patient.Patient, pagination.Meta, error) {
+	order := options.Order("id")
+
+	fields := options.Fetch(order, "id")
+	if fields == nil {
+		fields = patient.Fields
+	}
+
+	query, args, err := GenerateQuery(filters, page, order, fields)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Meta{}, sqlite.CheckError(err)
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	patientsList := make([]patient.Patient, 0)
+
+	for rows.Next() {
+		var p patient.Patient
+
+		targets := make([]interface{}, 0, len(fields))
+		for _, field := range fields {
+			targets = append(targets, scanTarget(&p, field))
+		}
+
+		err := rows.Scan(targets...)
+		if err != nil {
+			return nil, pagination.Meta{}, sqlite.CheckError(err)
+		}
+
+		patientsList = append(patientsList, p)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, pagination.Meta{}, sqlite.CheckError(err)
+	}
+
+	if !page.Paginated() {
+		return patientsList, pagination.NewMeta(page, len(patientsList), nil), nil
+	}
+
+	var next []string
+
+	if page.HasMore(len(patientsList)) {
+		patientsList = patientsList[:page.Size]
+		next = cursorKeys(patientsList[len(patientsList)-1], order)
+	}
+
+	var total int
+
+	countQuery, countArgs := GenerateCountQuery(filters)
+
+	err = s.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Meta{}, sqlite.CheckError(err)
+	}
+
+	return patientsList, pagination.NewMeta(page, total, next), nil
+}
+
+// GetByID returns a patient by its ID.
+func (s *Store) GetByID(ctx context.Context, id int) (patient.Patient, error) {
+	row := s.db.QueryRowContext(ctx, QueryGetPatientByID, id)
+
+	var p patient.Patient
+
+	err := row.Scan(
+		&p.ID,
+		&p.FirstName,
+		&p.LastName,
+		&p.Address,
+		&p.DNI,
+		&p.DischargeDate.Time,
+		&p.Active,
+	)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBNoRows):
+			return patient.Patient{}, patient.ErrNotFound
+		default:
+			return patient.Patient{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// GetByDNI returns a patient by its DNI.
+func (s *Store) GetByDNI(ctx context.Context, dni int) (patient.Patient, error) {
+	row := s.db.QueryRowContext(ctx, QueryGetPatientByDNI, dni)
+
+	var p patient.Patient
+
+	err := row.Scan(
+		&p.ID,
+		&p.FirstName,
+		&p.LastName,
+		&p.Address,
+		&p.DNI,
+		&p.DischargeDate.Time,
+		&p.Active,
+	)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBNoRows):
+			return patient.Patient{}, patient.ErrNotFound
+		default:
+			return patient.Patient{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// Search returns the candidates of a search, sorted by ID.
+func (s *Store) Search(ctx context.Context, candidates patient.Candidates) ([]patient.Patient, error) {
+	query, args, err := GenerateSearchQuery(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	patientsList := make([]patient.Patient, 0)
+
+	for rows.Next() {
+		var p patient.Patient
+
+		err := rows.Scan(&p.ID, &p.FirstName, &p.LastName, &p.Address, &p.DNI, &p.DischargeDate.Time, &p.Active)
+		if err != nil {
+			return nil, sqlite.CheckError(err)
+		}
+
+		patientsList = append(patientsList, p)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, sqlite.CheckError(err)
+	}
+
+	return patientsList, nil
+}
+
+// Create creates a new patient.
+func (s *Store) Create(ctx context.Context, p patient.Patient) (patient.Patient, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryInsertPatient)
+	if err != nil {
+		return patient.Patient{}, err
+	}
+
+	defer func(statement *sql.Stmt) {
+		err = statement.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(statement)
+
+	result, err := statement.ExecContext(ctx,
+		p.FirstName,
+		p.LastName,
+		p.Address,
+		p.DNI,
+		sqlite.Time(p.DischargeDate.Time),
+		p.Active,
+	)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBDuplicateEntry):
+			return patient.Patient{}, patient.ErrAlreadyExists
+		case errors.Is(err, sqlite.ErrDBConflict):
+			return patient.Patient{}, patient.ErrConflict
+		case errors.Is(err, sqlite.ErrDBValueExceeded):
+			return patient.Patient{}, patient.ErrValueExceeded
+		default:
+			return patient.Patient{}, err
+		}
+	}
+
+	lastId, err := result.LastInsertId()
+	if err != nil {
+		return patient.Patient{}, err
+	}
+
+	p.ID = int(lastId)
+
+	return p, nil
+}
+
+// Update updates a patient.
+func (s *Store) Update(ctx context.Context, p patient.Patient) (patient.Patient, error) {
+	statement, err := s.db.PrepareContext(ctx, QueryUpdatePatient)
+	if err != nil {
+		return patient.Patient{}, err
+	}
+
+	defer func(statement *sql.Stmt) {
+		err = statement.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(statement)
+
+	_, err = statement.ExecContext(ctx,
+		p.FirstName,
+		p.LastName,
+		p.Address,
+		p.DNI,
+		sqlite.Time(p.DischargeDate.Time),
+		p.ID,
+	)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBDuplicateEntry):
+			return patient.Patient{}, patient.ErrAlreadyExists
+		case errors.Is(err, sqlite.ErrDBConflict):
+			return patient.Patient{}, patient.ErrConflict
+		case errors.Is(err, sqlite.ErrDBValueExceeded):
+			return patient.Patient{}, patient.ErrValueExceeded
+		default:
+			return patient.Patient{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// Deactivate deactivates a patient.
+func (s *Store) Deactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetPatientActive, false, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reactivate reactivates a patient.
+func (s *Store) Reactivate(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer rollback(tx)
+
+	err = lock(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, QuerySetPatientActive, true, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a patient.
+func (s *Store) Delete(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeletePatient, id)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBNoRows):
+			return patient.ErrNotFound
+		case errors.Is(err, sqlite.ErrDBConflict):
+			return patient.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return patient.ErrNotFound
+	}
+
+	return nil
+}
+
+// GenerateQuery handles query creation to list a page of the patients matching the filters, sorted by order and reading
+// only the given fields.
+// Pages read one more row than their size, to know whether there are more rows after them.
+func GenerateQuery(filter map[string]string, page pagination.Request, order []string, fields []string) (string, []any, error) {
+	after, err := cursorValues(order, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", listing.ErrInvalidField, field)
+		}
+
+		selected = append(selected, column)
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	keyset, err := dqb.After(sortColumns, order, after...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dqb, err = dqb.And(
+		generateFilter(filter),
+		keyset,
+	).OrderBy(sortColumns, order...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if page.Paginated() {
+		dqb = dqb.Limit(page.Offset(), page.Size+1)
+	}
+
+	query, args := dqb.BindSql(fmt.Sprintf(QueryGetAllPatient, strings.Join(selected, ", ")))
+
+	return query, args, nil
+}
+
+// GenerateCountQuery handles query creation to count the patients matching the filters.
+func GenerateCountQuery(filter map[string]string) (string, []any) {
+	return generateFilter(filter).BindCondition(QueryCountPatient)
+}
+
+// GenerateSearchQuery handles query creation to read the candidates of a search, it returns the query and the arguments
+// of its placeholders. The columns are normalized like the chunks by the normalize function the driver registers.
+func GenerateSearchQuery(candidates patient.Candidates) (string, []any, error) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	matches := make([]interface{}, 0, 3*len(candidates.Chunks)+len(candidates.DNIPrefixes))
+
+	for _, chunk := range candidates.Chunks {
+		for _, column := range []string{"first_name", "last_name", "address"} {
+			matches = append(matches, dqb.Contains("normalize("+column+")", chunk))
+		}
+	}
+
+	for _, prefix := range candidates.DNIPrefixes {
+		matches = append(matches, dqb.Prefix("CAST(dni AS TEXT)", prefix))
+	}
+
+	dqb, err := dqb.And(
+		dqb.NewExpression("active", "=", true),
+		dqb.Or(matches...),
+	).OrderBy(sortColumns, "id")
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, args := dqb.BindSql(QuerySearchPatient)
+
+	return query, args, nil
+}
+
+// generateFilter builds the condition matching the filters, only active patients match unless inactive ones are included.
+// Unlike the collation of MySQL, LIKE only ignores the case of ASCII letters in SQLite, so names must have the same
+// accents.
+func generateFilter(filter map[string]string) query_builder.DynamicQueryBuilder {
+	var active any
+	if filter["include_inactive"] != "true" {
+		active = true
+	}
+
+	var dqb query_builder.DynamicQueryBuilder
+
+	return dqb.And(
+		dqb.NewExpression("active", "=", active),
+		dqb.In("id", query_builder.List(filter["ids"])...),
+		dqb.Or(
+			dqb.Contains("first_name", filter["name"]),
+			dqb.Contains("last_name", filter["name"]),
+		),
+		dqb.NewExpression("dni", "=", filter["dni"]),
+		dqb.Contains("address", filter["address"]),
+		dqb.Between("discharge_date", filter["discharge_from"], filter["discharge_to"]),
+		dqb.Exists(QueryPatientHasAppointments, dqb.Between("a.date", filter["appointments_from"], filter["appointments_to"])),
+	)
+}
+
+// scanTarget returns where the column of a field is scanned to.
+func scanTarget(p *patient.Patient, field string) interface{} {
+	switch field {
+	case "id":
+		return &p.ID
+	case "first_name":
+		return &p.FirstName
+	case "last_name":
+		return &p.LastName
+	case "address":
+		return &p.Address
+	case "dni":
+		return &p.DNI
+	case "discharge_date":
+		return &p.DischargeDate.Time
+	default:
+		return &p.Active
+	}
+}
+
+// cursorKeys returns the keys of the cursor of the page after a patient, its values of the fields sorted by.
+func cursorKeys(p patient.Patient, order []string) []string {
+	keys := make([]string, 0, len(order))
+
+	for _, field := range order {
+		switch listing.Field(field) {
+		case "first_name":
+			keys = append(keys, p.FirstName)
+		case "last_name":
+			keys = append(keys, p.LastName)
+		case "dni":
+			keys = append(keys, strconv.Itoa(p.DNI))
+		case "discharge_date":
+			keys = append(keys, p.DischargeDate.Format(time.DateTime))
+		default:
+			keys = append(keys, strconv.Itoa(p.ID))
+		}
+	}
+
+	return keys
+}
+
+// cursorValues parses the keys of the cursor, the values of the fields sorted by of the last patient of the previous
+// page.
+func cursorValues(order []string, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(order) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, 0, len(keys))
+
+	for i, field := range order {
+		switch listing.Field(field) {
+		case "id", "dni":
+			number, err := strconv.Atoi(keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, number)
+		case "discharge_date":
+			// Dates are compared as text, the key is only checked.
+			_, err := time.Parse(time.DateTime, keys[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, keys[i])
+		default:
+			values = append(values, keys[i])
+		}
+	}
+
+	return values, nil
+}
+
+// lock checks that the patient exists, it fails with patient.ErrNotFound if it doesn't.
+// The transaction holds the only connection to the database, so the patient can't change until it ends.
+func lock(ctx context.Context, tx *transaction.Tx, id int) error {
+	err := tx.QueryRowContext(ctx, QueryLockPatient, id).Scan(&id)
+	if err != nil {
+		err := sqlite.CheckError(err)
+		switch {
+		case errors.Is(err, sqlite.ErrDBNoRows):
+			return patient.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
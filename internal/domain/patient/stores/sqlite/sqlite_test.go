@@ -0,0 +1,169 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	sqliteAppointment "github.com/Nachofra/final-esp-backend-3/internal/domain/appointment/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/dentist"
+	sqliteDentist "github.com/Nachofra/final-esp-backend-3/internal/domain/dentist/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	sqlitePatient "github.com/Nachofra/final-esp-backend-3/internal/domain/patient/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	sqliteSchedule "github.com/Nachofra/final-esp-backend-3/internal/domain/schedule/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	sqliteWaitlist "github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist/stores/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/migrate"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/sqlite"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// injection is a value trying to break out of its placeholder, it must only ever be sent as an argument.
+const injection = "O'Brien'; DROP TABLE patient; --"
+
+func TestGenerateQuery(t *testing.T) {
+	filter := map[string]string{
+		"name":              injection,
+		"address":           "50%_off",
+		"appointments_from": "2024-01-01",
+	}
+
+	page := pagination.Request{Page: 2, Size: 10}
+
+	query, args, err := sqlitePatient.GenerateQuery(filter, page, []string{"-last_name", "id"}, []string{"id", "first_name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	condition := "(active = ? AND (first_name LIKE ? ESCAPE '!' OR last_name LIKE ? ESCAPE '!')" +
+		" AND address LIKE ? ESCAPE '!' AND EXISTS (" + sqlitePatient.QueryPatientHasAppointments + " AND a.date >= ?))"
+
+	want := fmt.Sprintf(sqlitePatient.QueryGetAllPatient, "id, first_name") + " WHERE " + condition +
+		" ORDER BY last_name DESC, id ASC LIMIT ? OFFSET ?"
+	if query != want {
+		t.Errorf("query is %q, want %q", query, want)
+	}
+
+	wantArgs := []any{true, "%" + injection + "%", "%" + injection + "%", "%50!%!_off%", "2024-01-01", 11, 10}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args are %#v, want %#v", args, wantArgs)
+	}
+
+	query, args = sqlitePatient.GenerateCountQuery(filter)
+
+	want = sqlitePatient.QueryCountPatient + " WHERE " + condition
+	if query != want {
+		t.Errorf("count query is %q, want %q", query, want)
+	}
+
+	if !reflect.DeepEqual(args, wantArgs[:5]) {
+		t.Errorf("count args are %#v, want %#v", args, wantArgs[:5])
+	}
+}
+
+// TestDeactivateOffersSlots deactivates a patient with a future appointment, its slot must be offered to the patient
+// waiting for it.
+func TestDeactivateOffersSlots(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.Open(sqlite.New(sqlite.WithPath(filepath.Join(t.TempDir(), "clinic.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrator, err := migrate.New(db, migrate.SQLite, sqlite.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactions := transaction.New(db, sql.LevelDefault, nil)
+	waitlistService := waitlist.NewService(sqliteWaitlist.NewStore(db))
+	appointmentService := appointment.NewService(sqliteAppointment.NewStore(db), transactions,
+		schedule.NewService(sqliteSchedule.NewStore(db)), waitlistService, 30*time.Minute)
+	patientService := patient.NewService(sqlitePatient.NewStore(db), transactions, appointmentService, waitlistService)
+
+	d, err := sqliteDentist.NewStore(db).Create(ctx, dentist.Dentist{FirstName: "Ana", LastName: "Díaz",
+		RegistrationNumber: 777002, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := nextMonday(10)
+
+	_, err = sqliteSchedule.NewStore(db).Create(ctx, schedule.Shift{DentistID: d.ID, Weekday: 1, StartTime: "09:00",
+		EndTime: "13:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patients := make([]patient.Patient, 0, 2)
+	for i := 0; i < 2; i++ {
+		p, err := patientService.Create(ctx, patient.NewPatient{FirstName: "Juan", LastName: "Pérez",
+			Address: "Mitre 100", DNI: 31000000 + i, DischargeDate: custom_time.Time{Time: date.AddDate(-1, 0, 0)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		patients = append(patients, p)
+	}
+
+	app, err := appointmentService.Create(ctx, appointment.NewAppointment{PatientID: patients[0].ID, DentistID: d.ID,
+		Date: custom_time.Time{Time: date}, Description: "Checkup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := waitlistService.Create(ctx, waitlist.NewEntry{PatientID: patients[1].ID, DentistID: &d.ID,
+		Ranges: []custom_time.Range{custom_time.NewRange(date.Add(-time.Hour), date.Add(2*time.Hour))}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = patientService.Deactivate(ctx, patients[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, err := appointmentService.GetByID(ctx, app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cancelled.Status != appointment.StatusCancelled {
+		t.Errorf("the appointment of the deactivated patient is %s, want %s", cancelled.Status,
+			appointment.StatusCancelled)
+	}
+
+	offers, err := waitlistService.GetOffers(ctx, entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(offers) != 1 || offers[0].AppointmentID != app.ID {
+		t.Errorf("offers of the waiting patient are %+v, want the slot of appointment %d", offers, app.ID)
+	}
+}
+
+// nextMonday returns the next Monday at the hour, in UTC.
+func nextMonday(hour int) time.Time {
+	day := time.Now().UTC().AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,36 @@
+package schedule
+
+import "time"
+
+// clock is the layout used for the hours of a Shift.
+const clock = "15:04"
+
+// Shift describes a range of hours a dentist works every week on a given day.
+// A dentist can have many shifts on the same day (split shifts), as long as they don't overlap.
+type Shift struct {
+	ID        int    `json:"id"`
+	DentistID int    `json:"dentist_id"`
+	Weekday   int    `json:"weekday"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// NewShift describes the data needed to create or update a Shift.
+// Weekday goes from 0 (Sunday) to 6 (Saturday), and hours are in HH:mm format.
+type NewShift struct {
+	Weekday   *int   `json:"weekday"    validate:"required,min=0,max=6"`
+	StartTime string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string `json:"end_time"   validate:"required,datetime=15:04"`
+}
+
+// on returns the start and the end of the shift on the given date.
+func (s Shift) on(date time.Time) (time.Time, time.Time) {
+	return atClock(date, s.StartTime), atClock(date, s.EndTime)
+}
+
+// atClock returns the given date at the given HH:mm hour.
+func atClock(date time.Time, hour string) time.Time {
+	t, _ := time.Parse(clock, hour)
+
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, date.Location())
+}
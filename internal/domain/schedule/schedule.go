@@ -0,0 +1,210 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("shift not found")
+	ErrConflict     = errors.New("constraint conflict while doing an action with the store layer")
+	ErrOverlap      = errors.New("the shift overlaps another shift of the dentist on the same day")
+	ErrInvalidRange = errors.New("the shift must end after it starts")
+)
+
+// Store specifies the contract needed for the Store in the Service.
+// Create and Update must fail with ErrOverlap when the shift overlaps another shift of the same dentist on the
+// same weekday, even under concurrent requests.
+type Store interface {
+	GetByDentist(ctx context.Context, dentistID int) ([]Shift, error)
+	GetByID(ctx context.Context, id int) (Shift, error)
+	Create(ctx context.Context, shift Shift) (Shift, error)
+	Update(ctx context.Context, shift Shift) (Shift, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// service unifies all the business operation for the domain.
+type service struct {
+	store Store
+}
+
+// Service specifies the contract needed for the Service.
+type Service interface {
+	GetByDentist(ctx context.Context, dentistID int) ([]Shift, error)
+	GetByID(ctx context.Context, dentistID int, id int) (Shift, error)
+	Create(ctx context.Context, dentistID int, newShift NewShift) (Shift, error)
+	Update(ctx context.Context, dentistID int, id int, newShift NewShift) (Shift, error)
+	Delete(ctx context.Context, dentistID int, id int) error
+	Ranges(ctx context.Context, dentistID int, from time.Time, to time.Time) ([]custom_time.Range, error)
+}
+
+// NewService creates a new service.
+func NewService(store Store) Service {
+	return &service{
+		store: store,
+	}
+}
+
+// GetByDentist returns the weekly schedule of a dentist.
+func (s *service) GetByDentist(ctx context.Context, dentistID int) ([]Shift, error) {
+	shifts, err := s.store.GetByDentist(ctx, dentistID)
+	if err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+// GetByID returns a shift of a dentist by its ID.
+func (s *service) GetByID(ctx context.Context, dentistID int, id int) (Shift, error) {
+	shift, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return Shift{}, err
+	}
+
+	if shift.DentistID != dentistID {
+		return Shift{}, ErrNotFound
+	}
+
+	return shift, nil
+}
+
+// Create adds a new shift to the schedule of a dentist.
+func (s *service) Create(ctx context.Context, dentistID int, newShift NewShift) (Shift, error) {
+	shift := newToShift(newShift)
+	shift.DentistID = dentistID
+
+	if shift.StartTime >= shift.EndTime {
+		return Shift{}, ErrInvalidRange
+	}
+
+	response, err := s.store.Create(ctx, shift)
+	if err != nil {
+		return Shift{}, err
+	}
+
+	return response, nil
+}
+
+// Update updates a shift of a dentist.
+func (s *service) Update(ctx context.Context, dentistID int, id int, newShift NewShift) (Shift, error) {
+	_, err := s.GetByID(ctx, dentistID, id)
+	if err != nil {
+		return Shift{}, err
+	}
+
+	shift := newToShift(newShift)
+	shift.ID = id
+	shift.DentistID = dentistID
+
+	if shift.StartTime >= shift.EndTime {
+		return Shift{}, ErrInvalidRange
+	}
+
+	response, err := s.store.Update(ctx, shift)
+	if err != nil {
+		return Shift{}, err
+	}
+
+	return response, nil
+}
+
+// Delete removes a shift from the schedule of a dentist.
+func (s *service) Delete(ctx context.Context, dentistID int, id int) error {
+	_, err := s.GetByID(ctx, dentistID, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Ranges returns the periods a dentist works between from and to, trimmed to them.
+// Contiguous shifts are merged into a single range, so an appointment can span both of them.
+func (s *service) Ranges(ctx context.Context, dentistID int, from time.Time, to time.Time) ([]custom_time.Range, error) {
+	shifts, err := s.store.GetByDentist(ctx, dentistID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]custom_time.Range, 0)
+
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	for day := first; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, shift := range shifts {
+			if time.Weekday(shift.Weekday) != day.Weekday() {
+				continue
+			}
+
+			start, end := shift.on(day)
+			if !end.After(from) || !start.Before(to) {
+				continue
+			}
+
+			if start.Before(from) {
+				start = from
+			}
+
+			if end.After(to) {
+				end = to
+			}
+
+			ranges = append(ranges, custom_time.NewRange(start, end))
+		}
+	}
+
+	return merge(ranges), nil
+}
+
+// merge sorts the ranges and joins the ones that overlap or are contiguous.
+func merge(ranges []custom_time.Range) []custom_time.Range {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start.Before(ranges[j].Start.Time)
+	})
+
+	merged := make([]custom_time.Range, 0, len(ranges))
+
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && !r.Start.After(merged[last].End.Time) {
+			if r.End.After(merged[last].End.Time) {
+				merged[last].End = r.End
+			}
+
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// newToShift parses NewShift to Shift, hours are normalized to HH:mm so they can be compared as strings.
+func newToShift(newShift NewShift) Shift {
+	var shift Shift
+	shift.Weekday = *newShift.Weekday
+	shift.StartTime = normalize(newShift.StartTime)
+	shift.EndTime = normalize(newShift.EndTime)
+
+	return shift
+}
+
+// normalize returns the hour in HH:mm format, adding the leading zero when it's missing.
+func normalize(hour string) string {
+	t, err := time.Parse(clock, hour)
+	if err != nil {
+		return hour
+	}
+
+	return t.Format(clock)
+}
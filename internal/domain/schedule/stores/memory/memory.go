@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"sort"
+)
+
+// Store wraps all the operations to the in-memory database.
+type Store struct {
+	db     *memory.DB
+	shifts *memory.Table[schedule.Shift]
+}
+
+// NewStore creates a new store, shifts are deleted with their dentist like in the SQL database.
+func NewStore(db *memory.DB) *Store {
+	shifts := memory.NewTable(db, "shift", func(sh *schedule.Shift) *int { return &sh.ID })
+	shifts.References("fk_shift_dentist", "dentist", func(sh schedule.Shift) int { return sh.DentistID }, true)
+
+	return &Store{
+		db:     db,
+		shifts: shifts,
+	}
+}
+
+// GetByDentist returns all the shifts of a dentist.
+func (s *Store) GetByDentist(_ context.Context, dentistID int) ([]schedule.Shift, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	shifts := make([]schedule.Shift, 0)
+
+	for _, sh := range s.shifts.Rows() {
+		if sh.DentistID == dentistID {
+			shifts = append(shifts, sh)
+		}
+	}
+
+	sort.SliceStable(shifts, func(i, j int) bool {
+		if shifts[i].Weekday != shifts[j].Weekday {
+			return shifts[i].Weekday < shifts[j].Weekday
+		}
+
+		return shifts[i].StartTime < shifts[j].StartTime
+	})
+
+	return shifts, nil
+}
+
+// GetByID returns a shift by its ID.
+func (s *Store) GetByID(_ context.Context, id int) (schedule.Shift, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	sh, ok := s.shifts.Get(id)
+	if !ok {
+		return schedule.Shift{}, schedule.ErrNotFound
+	}
+
+	return sh, nil
+}
+
+// Create creates a new shift.
+func (s *Store) Create(_ context.Context, sh schedule.Shift) (schedule.Shift, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.checkOverlap(sh)
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	sh, err = s.shifts.Insert(sh)
+	if err != nil {
+		return schedule.Shift{}, writeError(err)
+	}
+
+	return sh, nil
+}
+
+// Update updates a shift, it's never moved to another dentist.
+func (s *Store) Update(_ context.Context, sh schedule.Shift) (schedule.Shift, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	current, ok := s.shifts.Get(sh.ID)
+	if !ok {
+		return sh, nil
+	}
+
+	current.Weekday = sh.Weekday
+	current.StartTime = sh.StartTime
+	current.EndTime = sh.EndTime
+
+	err := s.checkOverlap(current)
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	err = s.shifts.Update(current)
+	if err != nil {
+		return schedule.Shift{}, writeError(err)
+	}
+
+	return sh, nil
+}
+
+// Delete deletes a shift.
+func (s *Store) Delete(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.shifts.Delete(id)
+	if err != nil {
+		if errors.Is(err, memory.ErrDBNoRows) {
+			return schedule.ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// checkOverlap checks that the shift doesn't overlap any other shift of the dentist on the same weekday.
+func (s *Store) checkOverlap(sh schedule.Shift) error {
+	for _, other := range s.shifts.Rows() {
+		if other.ID == sh.ID || other.DentistID != sh.DentistID || other.Weekday != sh.Weekday {
+			continue
+		}
+
+		// Hours are in HH:mm format, so they're compared as strings.
+		if other.StartTime < sh.EndTime && other.EndTime > sh.StartTime {
+			return schedule.ErrOverlap
+		}
+	}
+
+	return nil
+}
+
+// writeError maps the errors of inserting or updating a shift to the errors of the domain.
+func writeError(err error) error {
+	if errors.Is(err, memory.ErrDBConflict) {
+		return schedule.ErrConflict
+	}
+
+	return err
+}
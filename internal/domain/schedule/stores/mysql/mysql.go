@@ -0,0 +1,225 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/schedule"
+	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+)
+
+const (
+	QueryGetShiftsByDentist = `SELECT id, dentist_id, weekday, TIME_FORMAT(start_time, '%H:%i'), TIME_FORMAT(end_time, '%H:%i')
+	FROM clinic.shift WHERE dentist_id = ? ORDER BY weekday, start_time`
+
+	QueryGetShiftByID = `SELECT id, dentist_id, weekday, TIME_FORMAT(start_time, '%H:%i'), TIME_FORMAT(end_time, '%H:%i')
+	FROM clinic.shift WHERE id = ?`
+
+	QueryInsertShift = `INSERT INTO clinic.shift(dentist_id,weekday,start_time,end_time)
+	VALUES(?,?,?,?)`
+
+	QueryUpdateShift = `UPDATE clinic.shift SET weekday = ?, start_time = ?, end_time = ?
+	WHERE id = ?`
+
+	QueryDeleteShift = `DELETE FROM clinic.shift WHERE id = ?`
+
+	QueryLockDentist = `SELECT id FROM clinic.dentist WHERE id = ? FOR UPDATE`
+
+	QueryCountOverlappingShifts = `SELECT COUNT(*) FROM clinic.shift
+	WHERE id <> ? AND dentist_id = ? AND weekday = ? AND start_time < ? AND end_time > ?`
+)
+
+// Store wraps all the operations to the database.
+type Store struct {
+	db *transaction.DB
+}
+
+// NewStore creates a new store.
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: transaction.NewDB(db),
+	}
+}
+
+// GetByDentist returns all the shifts of a dentist.
+func (s *Store) GetByDentist(ctx context.Context, dentistID int) ([]schedule.Shift, error) {
+	rows, err := s.db.QueryContext(ctx, QueryGetShiftsByDentist, dentistID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	shifts := make([]schedule.Shift, 0)
+
+	for rows.Next() {
+		var sh schedule.Shift
+
+		err = rows.Scan(&sh.ID, &sh.DentistID, &sh.Weekday, &sh.StartTime, &sh.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		shifts = append(shifts, sh)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+// GetByID returns a shift by its ID.
+func (s *Store) GetByID(ctx context.Context, id int) (schedule.Shift, error) {
+	row := s.db.QueryRowContext(ctx, QueryGetShiftByID, id)
+
+	var sh schedule.Shift
+
+	err := row.Scan(&sh.ID, &sh.DentistID, &sh.Weekday, &sh.StartTime, &sh.EndTime)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBNoRows):
+			return schedule.Shift{}, schedule.ErrNotFound
+		default:
+			return schedule.Shift{}, err
+		}
+	}
+
+	return sh, nil
+}
+
+// Create creates a new shift.
+func (s *Store) Create(ctx context.Context, sh schedule.Shift) (schedule.Shift, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	defer rollback(tx)
+
+	err = checkOverlap(ctx, tx, sh)
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	result, err := tx.ExecContext(ctx, QueryInsertShift, sh.DentistID, sh.Weekday, sh.StartTime, sh.EndTime)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBConflict):
+			return schedule.Shift{}, schedule.ErrConflict
+		default:
+			return schedule.Shift{}, err
+		}
+	}
+
+	lastId, err := result.LastInsertId()
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	sh.ID = int(lastId)
+
+	return sh, nil
+}
+
+// Update updates a shift.
+func (s *Store) Update(ctx context.Context, sh schedule.Shift) (schedule.Shift, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	defer rollback(tx)
+
+	err = checkOverlap(ctx, tx, sh)
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, QueryUpdateShift, sh.Weekday, sh.StartTime, sh.EndTime, sh.ID)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBConflict):
+			return schedule.Shift{}, schedule.ErrConflict
+		default:
+			return schedule.Shift{}, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return schedule.Shift{}, err
+	}
+
+	return sh, nil
+}
+
+// Delete deletes a shift.
+func (s *Store) Delete(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeleteShift, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return schedule.ErrNotFound
+	}
+
+	return nil
+}
+
+// checkOverlap locks the dentist row, so concurrent changes to its schedule are serialized until the transaction
+// ends, and then checks that the shift doesn't overlap any other shift of the dentist on the same weekday.
+func checkOverlap(ctx context.Context, tx *transaction.Tx, sh schedule.Shift) error {
+	var id int
+
+	// A missing dentist is not handled here, the foreign key will report it as a conflict.
+	err := tx.QueryRowContext(ctx, QueryLockDentist, sh.DentistID).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var count int
+
+	err = tx.QueryRowContext(ctx, QueryCountOverlappingShifts,
+		sh.ID, sh.DentistID, sh.Weekday, sh.EndTime, sh.StartTime).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return schedule.ErrOverlap
+	}
+
+	return nil
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,42 @@
+package waitlist
+
+import "github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+
+// Status describes whether a waitlist Entry is still waiting for a slot.
+type Status string
+
+const (
+	StatusWaiting Status = "waiting"
+	StatusOffered Status = "offered"
+)
+
+// Entry describes a patient waiting for a free slot, with a dentist or with any dentist when DentistID is nil.
+// Ranges are the periods the patient prefers, a slot must fit in one of them to be offered.
+// Entries with higher Priority are offered slots first, then the ones for a specific dentist and then the oldest ones.
+type Entry struct {
+	ID        int                 `json:"id"`
+	PatientID int                 `json:"patient_id"`
+	DentistID *int                `json:"dentist_id"`
+	Ranges    []custom_time.Range `json:"ranges"`
+	Priority  int                 `json:"priority"`
+	Status    Status              `json:"status"`
+}
+
+// NewEntry describes the data needed to create or update a waitlist Entry.
+type NewEntry struct {
+	PatientID int                 `json:"patient_id" validate:"required"`
+	DentistID *int                `json:"dentist_id"`
+	Ranges    []custom_time.Range `json:"ranges"     validate:"required,min=1,max=10"`
+	Priority  int                 `json:"priority"   validate:"min=0,max=10"`
+}
+
+// Offer describes a freed slot offered to a waitlist Entry.
+// AppointmentID is the appointment that freed the slot, it's only a reference since it may be deleted.
+type Offer struct {
+	ID            int              `json:"id"`
+	EntryID       int              `json:"entry_id"`
+	AppointmentID int              `json:"appointment_id"`
+	DentistID     int              `json:"dentist_id"`
+	Date          custom_time.Time `json:"date"`
+	Duration      int              `json:"duration"`
+}
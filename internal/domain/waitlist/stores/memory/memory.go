@@ -0,0 +1,226 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/patient"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/memory"
+	"sort"
+	"time"
+)
+
+// Store wraps all the operations to the in-memory database.
+// The preferred ranges of the entries are kept with them, instead of in a table of their own.
+type Store struct {
+	db       *memory.DB
+	entries  *memory.Table[waitlist.Entry]
+	offers   *memory.Table[waitlist.Offer]
+	patients *memory.Table[patient.Patient]
+}
+
+// NewStore creates a new store, entries are deleted with their patient or dentist, and offers with their entry,
+// like in the SQL database.
+func NewStore(db *memory.DB) *Store {
+	entries := memory.NewTable(db, "waitlist_entry", func(e *waitlist.Entry) *int { return &e.ID })
+	entries.References("fk_waitlist_entry_patient", "patient", func(e waitlist.Entry) int { return e.PatientID }, true)
+	entries.References("fk_waitlist_entry_dentist", "dentist", func(e waitlist.Entry) int {
+		if e.DentistID == nil {
+			return 0
+		}
+
+		return *e.DentistID
+	}, true)
+
+	offers := memory.NewTable(db, "waitlist_offer", func(o *waitlist.Offer) *int { return &o.ID })
+	offers.References("fk_waitlist_offer_entry", "waitlist_entry", func(o waitlist.Offer) int { return o.EntryID }, true)
+
+	return &Store{
+		db:       db,
+		entries:  entries,
+		offers:   offers,
+		patients: memory.NewTable(db, "patient", func(p *patient.Patient) *int { return &p.ID }),
+	}
+}
+
+// GetAll returns all the waitlist entries.
+func (s *Store) GetAll(_ context.Context) ([]waitlist.Entry, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	return s.entries.Rows(), nil
+}
+
+// GetByID returns a waitlist entry by its ID.
+func (s *Store) GetByID(_ context.Context, id int) (waitlist.Entry, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	e, ok := s.entries.Get(id)
+	if !ok {
+		return waitlist.Entry{}, waitlist.ErrNotFound
+	}
+
+	return e, nil
+}
+
+// Create creates a new waitlist entry with its preferred ranges.
+func (s *Store) Create(_ context.Context, e waitlist.Entry) (waitlist.Entry, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	e, err := s.entries.Insert(sortRanges(e))
+	if err != nil {
+		return waitlist.Entry{}, writeError(err)
+	}
+
+	return e, nil
+}
+
+// Update updates a waitlist entry, replacing its preferred ranges.
+func (s *Store) Update(_ context.Context, e waitlist.Entry) (waitlist.Entry, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.entries.Update(sortRanges(e))
+	if err != nil {
+		// The ranges of a missing entry reference no entry, the SQL stores report it as a conflict too.
+		if errors.Is(err, memory.ErrDBNoRows) {
+			return waitlist.Entry{}, waitlist.ErrConflict
+		}
+
+		return waitlist.Entry{}, writeError(err)
+	}
+
+	return e, nil
+}
+
+// Delete deletes a waitlist entry, its offers are deleted too.
+func (s *Store) Delete(_ context.Context, id int) error {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	err := s.entries.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, memory.ErrDBNoRows):
+			return waitlist.ErrNotFound
+		case errors.Is(err, memory.ErrDBConflict):
+			return waitlist.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Candidates returns the waiting entries that can take the slot of a dentist between start and end, best first.
+func (s *Store) Candidates(_ context.Context, dentistID int, start time.Time, end time.Time) ([]waitlist.Entry, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	entries := make([]waitlist.Entry, 0)
+
+	for _, e := range s.entries.Rows() {
+		if e.Status != waitlist.StatusWaiting || (e.DentistID != nil && *e.DentistID != dentistID) {
+			continue
+		}
+
+		p, ok := s.patients.Get(e.PatientID)
+		if !ok || !p.Active {
+			continue
+		}
+
+		if fits(e.Ranges, start, end) {
+			entries = append(entries, e)
+		}
+	}
+
+	// Rows are sorted by ID already, so the stable sort keeps the oldest entries first.
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Priority != entries[j].Priority {
+			return entries[i].Priority > entries[j].Priority
+		}
+
+		return entries[i].DentistID != nil && entries[j].DentistID == nil
+	})
+
+	return entries, nil
+}
+
+// CreateOffer records an offer and marks its entry as offered at once.
+func (s *Store) CreateOffer(_ context.Context, o waitlist.Offer) (waitlist.Offer, error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	e, ok := s.entries.Get(o.EntryID)
+	if !ok || e.Status != waitlist.StatusWaiting {
+		return waitlist.Offer{}, waitlist.ErrAlreadyOffered
+	}
+
+	o, err := s.offers.Insert(o)
+	if err != nil {
+		return waitlist.Offer{}, writeError(err)
+	}
+
+	e.Status = waitlist.StatusOffered
+
+	err = s.entries.Update(e)
+	if err != nil {
+		return waitlist.Offer{}, err
+	}
+
+	return o, nil
+}
+
+// GetOffers returns the offers made to a waitlist entry.
+func (s *Store) GetOffers(_ context.Context, entryID int) ([]waitlist.Offer, error) {
+	s.db.RLock()
+	defer s.db.RUnlock()
+
+	offers := make([]waitlist.Offer, 0)
+
+	for _, o := range s.offers.Rows() {
+		if o.EntryID == entryID {
+			offers = append(offers, o)
+		}
+	}
+
+	return offers, nil
+}
+
+// fits reports whether the period between start and end fits in any of the ranges.
+func fits(ranges []custom_time.Range, start time.Time, end time.Time) bool {
+	for _, r := range ranges {
+		if !r.Start.After(start) && !r.End.Before(end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortRanges sorts the preferred ranges of the entry by their start, the order the SQL stores read them in.
+func sortRanges(e waitlist.Entry) waitlist.Entry {
+	ranges := make([]custom_time.Range, len(e.Ranges))
+	copy(ranges, e.Ranges)
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Start.Before(ranges[j].Start.Time)
+	})
+
+	e.Ranges = ranges
+
+	return e
+}
+
+// writeError maps the errors of inserting or updating to the errors of the domain.
+func writeError(err error) error {
+	if errors.Is(err, memory.ErrDBConflict) {
+		return waitlist.ErrConflict
+	}
+
+	return err
+}
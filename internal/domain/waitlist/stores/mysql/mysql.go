@@ -0,0 +1,398 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/waitlist"
+	"github.com/Nachofra/final-esp-backend-3/pkg/custom_time"
+	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+	"github.com/Nachofra/final-esp-backend-3/pkg/transaction"
+	"log"
+	"time"
+)
+
+// Store wraps all the operations to the database.
+type Store struct {
+	db *transaction.DB
+}
+
+// NewStore creates a new store.
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: transaction.NewDB(db),
+	}
+}
+
+// GetAll returns all the waitlist entries.
+func (s *Store) GetAll(ctx context.Context) ([]waitlist.Entry, error) {
+	entries, err := s.queryEntries(ctx, QueryGetAllEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, QueryGetAllRanges)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := scanRanges(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Ranges = ranges[entries[i].ID]
+	}
+
+	return entries, nil
+}
+
+// GetByID returns a waitlist entry by its ID.
+func (s *Store) GetByID(ctx context.Context, id int) (waitlist.Entry, error) {
+	row := s.db.QueryRowContext(ctx, QueryGetEntryByID, id)
+
+	e, err := scanEntry(row)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBNoRows):
+			return waitlist.Entry{}, waitlist.ErrNotFound
+		default:
+			return waitlist.Entry{}, err
+		}
+	}
+
+	err = s.loadRanges(ctx, &e)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	return e, nil
+}
+
+// Create creates a new waitlist entry with its preferred ranges.
+func (s *Store) Create(ctx context.Context, e waitlist.Entry) (waitlist.Entry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	defer rollback(tx)
+
+	result, err := tx.ExecContext(ctx, QueryInsertEntry, e.PatientID, e.DentistID, e.Priority, e.Status)
+	if err != nil {
+		return waitlist.Entry{}, writeError(err)
+	}
+
+	lastId, err := result.LastInsertId()
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	e.ID = int(lastId)
+
+	err = insertRanges(ctx, tx, e)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	return e, nil
+}
+
+// Update updates a waitlist entry, replacing its preferred ranges.
+func (s *Store) Update(ctx context.Context, e waitlist.Entry) (waitlist.Entry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	defer rollback(tx)
+
+	_, err = tx.ExecContext(ctx, QueryUpdateEntry, e.PatientID, e.DentistID, e.Priority, e.Status, e.ID)
+	if err != nil {
+		return waitlist.Entry{}, writeError(err)
+	}
+
+	_, err = tx.ExecContext(ctx, QueryDeleteRanges, e.ID)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	err = insertRanges(ctx, tx, e)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	return e, nil
+}
+
+// Delete deletes a waitlist entry, its ranges and offers are deleted by the database.
+func (s *Store) Delete(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, QueryDeleteEntry, id)
+	if err != nil {
+		err := mysql.CheckError(err)
+		switch {
+		case errors.Is(err, mysql.ErrDBConflict):
+			return waitlist.ErrConflict
+		default:
+			return err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected < 1 {
+		return waitlist.ErrNotFound
+	}
+
+	return nil
+}
+
+// Candidates returns the waiting entries that can take the slot of a dentist between start and end, best first.
+func (s *Store) Candidates(ctx context.Context, dentistID int, start time.Time, end time.Time) ([]waitlist.Entry, error) {
+	entries, err := s.queryEntries(ctx, QueryGetCandidates, waitlist.StatusWaiting, dentistID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		err = s.loadRanges(ctx, &entries[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// CreateOffer records an offer and marks its entry as offered in the same transaction.
+func (s *Store) CreateOffer(ctx context.Context, o waitlist.Offer) (waitlist.Offer, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return waitlist.Offer{}, err
+	}
+
+	defer rollback(tx)
+
+	result, err := tx.ExecContext(ctx, QueryOfferEntry, waitlist.StatusOffered, o.EntryID, waitlist.StatusWaiting)
+	if err != nil {
+		return waitlist.Offer{}, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return waitlist.Offer{}, err
+	}
+
+	if rowsAffected < 1 {
+		return waitlist.Offer{}, waitlist.ErrAlreadyOffered
+	}
+
+	result, err = tx.ExecContext(ctx, QueryInsertOffer, o.EntryID, o.AppointmentID, o.DentistID, o.Date.Time, o.Duration)
+	if err != nil {
+		return waitlist.Offer{}, writeError(err)
+	}
+
+	lastId, err := result.LastInsertId()
+	if err != nil {
+		return waitlist.Offer{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return waitlist.Offer{}, err
+	}
+
+	o.ID = int(lastId)
+
+	return o, nil
+}
+
+// GetOffers returns the offers made to a waitlist entry.
+func (s *Store) GetOffers(ctx context.Context, entryID int) ([]waitlist.Offer, error) {
+	rows, err := s.db.QueryContext(ctx, QueryGetOffersByEntry, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	offers := make([]waitlist.Offer, 0)
+
+	for rows.Next() {
+		var o waitlist.Offer
+
+		err = rows.Scan(&o.ID, &o.EntryID, &o.AppointmentID, &o.DentistID, &o.Date.Time, &o.Duration)
+		if err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, o)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// queryEntries returns the entries returned by the query, without their ranges.
+func (s *Store) queryEntries(ctx context.Context, query string, args ...any) ([]waitlist.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	entries := make([]waitlist.Entry, 0)
+
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// loadRanges sets the preferred ranges of the entry.
+func (s *Store) loadRanges(ctx context.Context, e *waitlist.Entry) error {
+	rows, err := s.db.QueryContext(ctx, QueryGetRangesByEntry, e.ID)
+	if err != nil {
+		return err
+	}
+
+	ranges, err := scanRanges(rows)
+	if err != nil {
+		return err
+	}
+
+	e.Ranges = ranges[e.ID]
+	if e.Ranges == nil {
+		e.Ranges = make([]custom_time.Range, 0)
+	}
+
+	return nil
+}
+
+// scanner is implemented by both sql.Row and sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanEntry scans an entry, a NULL dentist means any dentist.
+func scanEntry(row scanner) (waitlist.Entry, error) {
+	var e waitlist.Entry
+	var dentistID sql.NullInt64
+
+	err := row.Scan(&e.ID, &e.PatientID, &dentistID, &e.Priority, &e.Status)
+	if err != nil {
+		return waitlist.Entry{}, err
+	}
+
+	if dentistID.Valid {
+		id := int(dentistID.Int64)
+		e.DentistID = &id
+	}
+
+	e.Ranges = make([]custom_time.Range, 0)
+
+	return e, nil
+}
+
+// scanRanges scans and closes the rows, grouping the ranges by their entry ID.
+func scanRanges(rows *sql.Rows) (map[int][]custom_time.Range, error) {
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	ranges := make(map[int][]custom_time.Range)
+
+	for rows.Next() {
+		var entryID int
+		var r custom_time.Range
+
+		err := rows.Scan(&entryID, &r.Start.Time, &r.End.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		ranges[entryID] = append(ranges[entryID], r)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return ranges, nil
+}
+
+// insertRanges inserts the preferred ranges of the entry.
+func insertRanges(ctx context.Context, tx *transaction.Tx, e waitlist.Entry) error {
+	for _, r := range e.Ranges {
+		_, err := tx.ExecContext(ctx, QueryInsertRange, e.ID, r.Start.Time, r.End.Time)
+		if err != nil {
+			return writeError(err)
+		}
+	}
+
+	return nil
+}
+
+// writeError maps the errors of inserting or updating to the errors of the domain.
+func writeError(err error) error {
+	err = mysql.CheckError(err)
+	switch {
+	case errors.Is(err, mysql.ErrDBConflict):
+		return waitlist.ErrConflict
+	default:
+		return err
+	}
+}
+
+// rollback rolls back the transaction, it does nothing if the transaction was already committed.
+func rollback(tx *transaction.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,41 @@
+package mysql
+
+const (
+	QueryGetAllEntries = `SELECT id, patient_id, dentist_id, priority, status
+	FROM clinic.waitlist_entry ORDER BY id`
+
+	QueryGetEntryByID = `SELECT id, patient_id, dentist_id, priority, status
+	FROM clinic.waitlist_entry WHERE id = ?`
+
+	QueryGetCandidates = `SELECT e.id, e.patient_id, e.dentist_id, e.priority, e.status
+	FROM clinic.waitlist_entry e INNER JOIN clinic.patient p ON e.patient_id = p.id
+	WHERE e.status = ? AND p.active = 1 AND (e.dentist_id = ? OR e.dentist_id IS NULL)
+	AND EXISTS (SELECT 1 FROM clinic.waitlist_range r WHERE r.entry_id = e.id AND r.start_date <= ? AND r.end_date >= ?)
+	ORDER BY e.priority DESC, e.dentist_id IS NULL, e.id`
+
+	QueryInsertEntry = `INSERT INTO clinic.waitlist_entry(patient_id,dentist_id,priority,status)
+	VALUES(?,?,?,?)`
+
+	QueryUpdateEntry = `UPDATE clinic.waitlist_entry SET patient_id = ?, dentist_id = ?, priority = ?, status = ?
+	WHERE id = ?`
+
+	QueryDeleteEntry = `DELETE FROM clinic.waitlist_entry WHERE id = ?`
+
+	QueryGetAllRanges = `SELECT entry_id, start_date, end_date FROM clinic.waitlist_range ORDER BY entry_id, start_date`
+
+	QueryGetRangesByEntry = `SELECT entry_id, start_date, end_date FROM clinic.waitlist_range
+	WHERE entry_id = ? ORDER BY start_date`
+
+	QueryInsertRange = `INSERT INTO clinic.waitlist_range(entry_id,start_date,end_date)
+	VALUES(?,?,?)`
+
+	QueryDeleteRanges = `DELETE FROM clinic.waitlist_range WHERE entry_id = ?`
+
+	QueryOfferEntry = `UPDATE clinic.waitlist_entry SET status = ? WHERE id = ? AND status = ?`
+
+	QueryInsertOffer = `INSERT INTO clinic.waitlist_offer(entry_id,appointment_id,dentist_id,date,duration)
+	VALUES(?,?,?,?,?)`
+
+	QueryGetOffersByEntry = `SELECT id, entry_id, appointment_id, dentist_id, date, duration
+	FROM clinic.waitlist_offer WHERE entry_id = ? ORDER BY id`
+)
@@ -0,0 +1,41 @@
+package postgres
+
+const (
+	QueryGetAllEntries = `SELECT id, patient_id, dentist_id, priority, status
+	FROM waitlist_entry ORDER BY id`
+
+	QueryGetEntryByID = `SELECT id, patient_id, dentist_id, priority, status
+	FROM waitlist_entry WHERE id = $1`
+
+	QueryGetCandidates = `SELECT e.id, e.patient_id, e.dentist_id, e.priority, e.status
+	FROM waitlist_entry e INNER JOIN patient p ON e.patient_id = p.id
+	WHERE e.status = $1 AND p.active AND (e.dentist_id = $2 OR e.dentist_id IS NULL)
+	AND EXISTS (SELECT 1 FROM waitlist_range r WHERE r.entry_id = e.id AND r.start_date <= $3 AND r.end_date >= $4)
+	ORDER BY e.priority DESC, e.dentist_id IS NULL, e.id`
+
+	QueryInsertEntry = `INSERT INTO waitlist_entry(patient_id,dentist_id,priority,status)
+	VALUES($1,$2,$3,$4) RETURNING id`
+
+	QueryUpdateEntry = `UPDATE waitlist_entry SET patient_id = $1, dentist_id = $2, priority = $3, status = $4
+	WHERE id = $5`
+
+	QueryDeleteEntry = `DELETE FROM waitlist_entry WHERE id = $1`
+
+	QueryGetAllRanges = `SELECT entry_id, start_date, end_date FROM waitlist_range ORDER BY entry_id, start_date`
+
+	QueryGetRangesByEntry = `SELECT entry_id, start_date, end_date FROM waitlist_range
+	WHERE entry_id = $1 ORDER BY start_date`
+
+	QueryInsertRange = `INSERT INTO waitlist_range(entry_id,start_date,end_date)
+	VALUES($1,$2,$3)`
+
+	QueryDeleteRanges = `DELETE FROM waitlist_range WHERE entry_id = $1`
+
+	QueryOfferEntry = `UPDATE waitlist_entry SET status = $1 WHERE id = $2 AND status = $3`
+
+	QueryInsertOffer = `INSERT INTO waitlist_offer(entry_id,appointment_id,dentist_id,date,duration)
+	VALUES($1,$2,$3,$4,$5) RETURNING id`
+
+	QueryGetOffersByEntry = `SELECT id, entry_id, appointment_id, dentist_id, date, duration
+	FROM waitlist_offer WHERE entry_id = $1 ORDER BY id`
+)
@@ -0,0 +1,41 @@
+package sqlite
+
+const (
+	QueryGetAllEntries = `SELECT id, patient_id, dentist_id, priority, status
+	FROM waitlist_entry ORDER BY id`
+
+	QueryGetEntryByID = `SELECT id, patient_id, dentist_id, priority, status
+	FROM waitlist_entry WHERE id = ?`
+
+	QueryGetCandidates = `SELECT e.id, e.patient_id, e.dentist_id, e.priority, e.status
+	FROM waitlist_entry e INNER JOIN patient p ON e.patient_id = p.id
+	WHERE e.status = ? AND p.active = 1 AND (e.dentist_id = ? OR e.dentist_id IS NULL)
+	AND EXISTS (SELECT 1 FROM waitlist_range r WHERE r.entry_id = e.id AND r.start_date <= ? AND r.end_date >= ?)
+	ORDER BY e.priority DESC, e.dentist_id IS NULL, e.id`
+
+	QueryInsertEntry = `INSERT INTO waitlist_entry(patient_id,dentist_id,priority,status)
+	VALUES(?,?,?,?)`
+
+	QueryUpdateEntry = `UPDATE waitlist_entry SET patient_id = ?, dentist_id = ?, priority = ?, status = ?
+	WHERE id = ?`
+
+	QueryDeleteEntry = `DELETE FROM waitlist_entry WHERE id = ?`
+
+	QueryGetAllRanges = `SELECT entry_id, start_date, end_date FROM waitlist_range ORDER BY entry_id, start_date`
+
+	QueryGetRangesByEntry = `SELECT entry_id, start_date, end_date FROM waitlist_range
+	WHERE entry_id = ? ORDER BY start_date`
+
+	QueryInsertRange = `INSERT INTO waitlist_range(entry_id,start_date,end_date)
+	VALUES(?,?,?)`
+
+	QueryDeleteRanges = `DELETE FROM waitlist_range WHERE entry_id = ?`
+
+	QueryOfferEntry = `UPDATE waitlist_entry SET status = ? WHERE id = ? AND status = ?`
+
+	QueryInsertOffer = `INSERT INTO waitlist_offer(entry_id,appointment_id,dentist_id,date,duration)
+	VALUES(?,?,?,?,?)`
+
+	QueryGetOffersByEntry = `SELECT id, entry_id, appointment_id, dentist_id, date, duration
+	FROM waitlist_offer WHERE entry_id = ? ORDER BY id`
+)
@@ -0,0 +1,193 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/internal/domain/appointment"
+	"time"
+)
+
+var (
+	ErrNotFound       = errors.New("waitlist entry not found")
+	ErrConflict       = errors.New("constraint conflict while doing an action with the store layer")
+	ErrInvalidRange   = errors.New("every preferred range must end after it starts")
+	ErrAlreadyOffered = errors.New("the waitlist entry is no longer waiting")
+)
+
+// Store specifies the contract needed for the Store in the Service.
+// Candidates returns the waiting entries of active patients for the dentist, or for any dentist, with a preferred
+// range containing the period between start and end, best first: by priority, then the ones for the dentist and then
+// the oldest ones.
+// CreateOffer records the offer and marks its entry as offered at once, it must fail with ErrAlreadyOffered when the
+// entry is no longer waiting.
+type Store interface {
+	GetAll(ctx context.Context) ([]Entry, error)
+	GetByID(ctx context.Context, id int) (Entry, error)
+	Create(ctx context.Context, entry Entry) (Entry, error)
+	Update(ctx context.Context, entry Entry) (Entry, error)
+	Delete(ctx context.Context, id int) error
+	Candidates(ctx context.Context, dentistID int, start time.Time, end time.Time) ([]Entry, error)
+	CreateOffer(ctx context.Context, offer Offer) (Offer, error)
+	GetOffers(ctx context.Context, entryID int) ([]Offer, error)
+}
+
+// service unifies all the business operation for the domain.
+type service struct {
+	store Store
+}
+
+// Service specifies the contract needed for the Service.
+type Service interface {
+	GetAll(ctx context.Context) ([]Entry, error)
+	GetByID(ctx context.Context, id int) (Entry, error)
+	Create(ctx context.Context, newEntry NewEntry) (Entry, error)
+	Update(ctx context.Context, id int, newEntry NewEntry) (Entry, error)
+	Delete(ctx context.Context, id int) error
+	GetOffers(ctx context.Context, id int) ([]Offer, error)
+	SlotFreed(ctx context.Context, a appointment.Appointment) error
+}
+
+// NewService creates a new service.
+func NewService(store Store) Service {
+	return &service{
+		store: store,
+	}
+}
+
+// GetAll returns all the waitlist entries.
+func (s *service) GetAll(ctx context.Context) ([]Entry, error) {
+	entries, err := s.store.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetByID returns a waitlist entry by its ID.
+func (s *service) GetByID(ctx context.Context, id int) (Entry, error) {
+	entry, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Create adds a patient to the waitlist.
+func (s *service) Create(ctx context.Context, newEntry NewEntry) (Entry, error) {
+	entry, err := newToEntry(newEntry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	response, err := s.store.Create(ctx, entry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return response, nil
+}
+
+// Update updates a waitlist entry, which goes back to waiting since its preferences may have changed.
+func (s *service) Update(ctx context.Context, id int, newEntry NewEntry) (Entry, error) {
+	_, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry, err := newToEntry(newEntry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.ID = id
+
+	response, err := s.store.Update(ctx, entry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return response, nil
+}
+
+// Delete removes a patient from the waitlist.
+func (s *service) Delete(ctx context.Context, id int) error {
+	err := s.store.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetOffers returns the slots offered to a waitlist entry.
+func (s *service) GetOffers(ctx context.Context, id int) ([]Offer, error) {
+	_, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := s.store.GetOffers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// SlotFreed offers the slot of a cancelled or deleted appointment to the best matching waitlist entry, if any.
+// Past slots are not offered, neither to the patient who freed them.
+func (s *service) SlotFreed(ctx context.Context, a appointment.Appointment) error {
+	if !a.Date.After(time.Now()) {
+		return nil
+	}
+
+	candidates, err := s.store.Candidates(ctx, a.DentistID, a.Date.Time, a.End())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range candidates {
+		if entry.PatientID == a.PatientID {
+			continue
+		}
+
+		_, err = s.store.CreateOffer(ctx, Offer{
+			EntryID:       entry.ID,
+			AppointmentID: a.ID,
+			DentistID:     a.DentistID,
+			Date:          a.Date,
+			Duration:      a.Duration,
+		})
+		if err != nil {
+			// Another freed slot was offered to the entry meanwhile, the next one can take this slot.
+			if errors.Is(err, ErrAlreadyOffered) {
+				continue
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// newToEntry parses NewEntry to a waiting Entry, checking its preferred ranges.
+func newToEntry(newEntry NewEntry) (Entry, error) {
+	for _, r := range newEntry.Ranges {
+		if !r.End.After(r.Start.Time) {
+			return Entry{}, ErrInvalidRange
+		}
+	}
+
+	return Entry{
+		PatientID: newEntry.PatientID,
+		DentistID: newEntry.DentistID,
+		Ranges:    newEntry.Ranges,
+		Priority:  newEntry.Priority,
+		Status:    StatusWaiting,
+	}, nil
+}
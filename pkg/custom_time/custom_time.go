@@ -28,3 +28,24 @@ func (t *Time) UnmarshalJSON(b []byte) (err error) {
 	t.Time = date
 	return
 }
+
+// Range describes a period of time, it includes its start but not its end.
+type Range struct {
+	Start Time `json:"start"`
+	End   Time `json:"end"`
+}
+
+// NewRange creates a new Range between start and end.
+func NewRange(start time.Time, end time.Time) Range {
+	return Range{Start: Time{start}, End: Time{end}}
+}
+
+// Contains reports whether the period between start and end is fully inside the range.
+func (r Range) Contains(start time.Time, end time.Time) bool {
+	return !start.Before(r.Start.Time) && !end.After(r.End.Time)
+}
+
+// Overlaps reports whether the period between start and end shares some time with the range.
+func (r Range) Overlaps(start time.Time, end time.Time) bool {
+	return start.Before(r.End.Time) && end.After(r.Start.Time)
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrDBNoRows         = errors.New("no rows")
+	ErrDBDuplicateEntry = errors.New("duplicate entry")
+	ErrDBConflict       = errors.New("conflict between constraints")
+	ErrUnknownTable     = errors.New("unknown table")
+)
+
+// DB is an in-memory database. Its tables are shared by all the stores, like the ones of a SQL database, with the
+// same unique and foreign key constraints, and the stores hold its lock during every operation, so all of them are
+// atomic. Tables are only used while holding the lock.
+type DB struct {
+	sync.RWMutex
+	tables map[string]table
+	keys   map[string]foreignKey
+}
+
+// table is implemented by every Table, whatever the type of its rows.
+type table interface {
+	exists(id int) bool
+	references(key func(any) int, id int) []int
+	remove(id int)
+	load(rows []json.RawMessage) error
+	validate() error
+}
+
+// foreignKey describes a reference from the rows of the child table to the rows of the parent table.
+type foreignKey struct {
+	child   string
+	parent  string
+	key     func(any) int
+	cascade bool
+}
+
+// New creates a new empty in-memory database.
+func New() *DB {
+	return &DB{
+		tables: make(map[string]table),
+		keys:   make(map[string]foreignKey),
+	}
+}
+
+// Load inserts the rows of a JSON document like {"table": [rows]}, keeping their IDs, as a dump would.
+// Rows are written as their JSON representation, and constraints are checked once all of them are loaded, so tables
+// can be in any order. Tables must be created by their stores before loading them.
+func (db *DB) Load(r io.Reader) error {
+	db.Lock()
+	defer db.Unlock()
+
+	var dump map[string][]json.RawMessage
+
+	err := json.NewDecoder(r).Decode(&dump)
+	if err != nil {
+		return err
+	}
+
+	for name, rows := range dump {
+		t, ok := db.tables[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownTable, name)
+		}
+
+		err = t.load(rows)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for name, t := range db.tables {
+		err = t.validate()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadFile loads the JSON document of the file with Load.
+func (db *DB) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	return db.Load(f)
+}
+
+// delete deletes a row and the ones referencing it by a cascading foreign key, it fails with ErrDBConflict, deleting
+// nothing, when any other foreign key references them.
+func (db *DB) delete(name string, id int) error {
+	deletions := make(map[string][]int)
+
+	err := db.plan(name, id, deletions)
+	if err != nil {
+		return err
+	}
+
+	for n, ids := range deletions {
+		for _, i := range ids {
+			db.tables[n].remove(i)
+		}
+	}
+
+	return nil
+}
+
+// plan adds the row and the ones deleted with it to deletions.
+func (db *DB) plan(name string, id int, deletions map[string][]int) error {
+	for _, i := range deletions[name] {
+		if i == id {
+			return nil
+		}
+	}
+
+	deletions[name] = append(deletions[name], id)
+
+	names := make([]string, 0, len(db.keys))
+	for n := range db.keys {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		fk := db.keys[n]
+		if fk.parent != name {
+			continue
+		}
+
+		child, ok := db.tables[fk.child]
+		if !ok {
+			continue
+		}
+
+		for _, childID := range child.references(fk.key, id) {
+			if !fk.cascade {
+				return ErrDBConflict
+			}
+
+			err := db.plan(fk.child, childID, deletions)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"github.com/Nachofra/final-esp-backend-3/pkg/fuzzy"
+	"github.com/Nachofra/final-esp-backend-3/pkg/listing"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page sorts the rows by the fields of order, like "ORDER BY", and returns the requested page and its metadata.
+// value returns the value of a field of a row, an int, a string or a time.Time, the ones of the cursors are written
+// the same way the SQL stores do.
+func Page[T any](rows []T, page pagination.Request, order []string, value func(T, string) any) ([]T, pagination.Meta, error) {
+	values := func(row T) []any {
+		v := make([]any, 0, len(order))
+		for _, field := range order {
+			v = append(v, value(row, listing.Field(field)))
+		}
+
+		return v
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compareAll(values(rows[i]), values(rows[j]), order) < 0
+	})
+
+	total := len(rows)
+
+	if len(page.After) > 0 {
+		if len(page.After) != len(order) {
+			return nil, pagination.Meta{}, pagination.ErrInvalidCursor
+		}
+
+		if len(rows) > 0 {
+			after, err := parseKeys(values(rows[0]), page.After)
+			if err != nil {
+				return nil, pagination.Meta{}, err
+			}
+
+			i := sort.Search(len(rows), func(i int) bool {
+				return compareAll(values(rows[i]), after, order) > 0
+			})
+
+			rows = rows[i:]
+		}
+	}
+
+	if !page.Paginated() {
+		return rows, pagination.NewMeta(page, total, nil), nil
+	}
+
+	offset := page.Offset()
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+
+	rows = rows[offset:]
+
+	var next []string
+
+	if page.HasMore(len(rows)) {
+		rows = rows[:page.Size]
+		next = keys(values(rows[len(rows)-1]))
+	}
+
+	return rows, pagination.NewMeta(page, total, next), nil
+}
+
+// Contains reports whether text contains s, ignoring case and accents like "LIKE" does with the collation of the
+// SQL database.
+func Contains(text string, s string) bool {
+	return strings.Contains(fuzzy.Normalize(text), fuzzy.Normalize(s))
+}
+
+// In reports whether id is one of the comma separated list of a filter, like "IN" does.
+func In(list string, id int) bool {
+	for _, v := range strings.Split(list, ",") {
+		if v == strconv.Itoa(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTime parses a date written by the filters of the domains, it reports whether it's valid.
+func ParseTime(s string) (time.Time, bool) {
+	t, err := time.Parse(time.DateTime, s)
+	return t, err == nil
+}
+
+// compareAll compares the values of two rows in the order of the fields, descending fields are compared backwards.
+func compareAll(a []any, b []any, order []string) int {
+	for i, field := range order {
+		c := compare(a[i], b[i])
+		if strings.HasPrefix(field, "-") {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// compare compares two values of the same type, returning -1, 0 or 1.
+func compare(a any, b any) int {
+	switch va := a.(type) {
+	case int:
+		vb := b.(int)
+		switch {
+		case va < vb:
+			return -1
+		case va > vb:
+			return 1
+		}
+
+		return 0
+	case time.Time:
+		return va.Compare(b.(time.Time))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// keys writes the values as the keys of a cursor.
+func keys(values []any) []string {
+	k := make([]string, 0, len(values))
+
+	for _, v := range values {
+		switch value := v.(type) {
+		case int:
+			k = append(k, strconv.Itoa(value))
+		case time.Time:
+			k = append(k, value.Format(time.DateTime))
+		default:
+			k = append(k, value.(string))
+		}
+	}
+
+	return k
+}
+
+// parseKeys parses the keys of a cursor to values of the same types as samples.
+func parseKeys(samples []any, k []string) ([]any, error) {
+	values := make([]any, 0, len(k))
+
+	for i, sample := range samples {
+		switch sample.(type) {
+		case int:
+			v, err := strconv.Atoi(k[i])
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, v)
+		case time.Time:
+			v, ok := ParseTime(k[i])
+			if !ok {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			values = append(values, v)
+		default:
+			values = append(values, k[i])
+		}
+	}
+
+	return values, nil
+}
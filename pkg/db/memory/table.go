@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Table holds the rows of a type, by their ID. IDs are assigned on insert, from 1 on, so 0 is never a valid ID.
+type Table[T any] struct {
+	db      *DB
+	name    string
+	id      func(*T) *int
+	rows    map[int]T
+	lastID  int
+	uniques map[string]func(T) string
+}
+
+// NewTable returns the table with the name, creating it the first time, id returns the ID field of a row.
+// Every store using the same table must use the same type for its rows.
+func NewTable[T any](db *DB, name string, id func(*T) *int) *Table[T] {
+	db.Lock()
+	defer db.Unlock()
+
+	if t, ok := db.tables[name]; ok {
+		return t.(*Table[T])
+	}
+
+	t := &Table[T]{
+		db:      db,
+		name:    name,
+		id:      id,
+		rows:    make(map[int]T),
+		uniques: make(map[string]func(T) string),
+	}
+
+	db.tables[name] = t
+
+	return t
+}
+
+// Unique declares a unique index, no two rows can have the same key. Rows with an empty key are not indexed.
+func (t *Table[T]) Unique(name string, key func(T) string) {
+	t.db.Lock()
+	defer t.db.Unlock()
+
+	t.uniques[name] = key
+}
+
+// References declares a foreign key from the rows of the table to the rows of the parent table, key returns the ID a
+// row references, or 0 when it references none. Deleting a referenced row deletes the rows referencing it too when
+// cascade is set, otherwise it fails with ErrDBConflict.
+func (t *Table[T]) References(name string, parent string, key func(T) int, cascade bool) {
+	t.db.Lock()
+	defer t.db.Unlock()
+
+	t.db.keys[name] = foreignKey{
+		child:   t.name,
+		parent:  parent,
+		key:     func(row any) int { return key(row.(T)) },
+		cascade: cascade,
+	}
+}
+
+// Get returns the row with the ID and reports whether it exists.
+func (t *Table[T]) Get(id int) (T, bool) {
+	row, ok := t.rows[id]
+	return row, ok
+}
+
+// Rows returns all the rows, sorted by ID.
+func (t *Table[T]) Rows() []T {
+	ids := make([]int, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	rows := make([]T, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, t.rows[id])
+	}
+
+	return rows
+}
+
+// Insert inserts the row with a new ID and returns it, it fails with ErrDBDuplicateEntry when it breaks a unique
+// index and with ErrDBConflict when it references a row that doesn't exist.
+func (t *Table[T]) Insert(row T) (T, error) {
+	*t.id(&row) = t.lastID + 1
+
+	err := t.check(row)
+	if err != nil {
+		return row, err
+	}
+
+	t.lastID++
+	t.rows[t.lastID] = row
+
+	return row, nil
+}
+
+// Update replaces the row with the same ID, it fails with ErrDBNoRows when there's none, and like Insert when the
+// new row breaks a constraint.
+func (t *Table[T]) Update(row T) error {
+	id := *t.id(&row)
+
+	if _, ok := t.rows[id]; !ok {
+		return ErrDBNoRows
+	}
+
+	err := t.check(row)
+	if err != nil {
+		return err
+	}
+
+	t.rows[id] = row
+
+	return nil
+}
+
+// Delete deletes the row with the ID, it fails with ErrDBNoRows when there's none and with ErrDBConflict when
+// another row references it, see References.
+func (t *Table[T]) Delete(id int) error {
+	if _, ok := t.rows[id]; !ok {
+		return ErrDBNoRows
+	}
+
+	return t.db.delete(t.name, id)
+}
+
+// check checks that the row doesn't break any unique index or foreign key.
+func (t *Table[T]) check(row T) error {
+	id := *t.id(&row)
+
+	for _, key := range t.uniques {
+		value := key(row)
+		if value == "" {
+			continue
+		}
+
+		for otherID, other := range t.rows {
+			if otherID != id && key(other) == value {
+				return ErrDBDuplicateEntry
+			}
+		}
+	}
+
+	for _, fk := range t.db.keys {
+		if fk.child != t.name {
+			continue
+		}
+
+		ref := fk.key(row)
+		if ref == 0 {
+			continue
+		}
+
+		parent, ok := t.db.tables[fk.parent]
+		if !ok || !parent.exists(ref) {
+			return ErrDBConflict
+		}
+	}
+
+	return nil
+}
+
+// exists reports whether there's a row with the ID.
+func (t *Table[T]) exists(id int) bool {
+	_, ok := t.rows[id]
+	return ok
+}
+
+// references returns the IDs of the rows referencing the ID by the key.
+func (t *Table[T]) references(key func(any) int, id int) []int {
+	ids := make([]int, 0)
+
+	for rowID, row := range t.rows {
+		if key(row) == id {
+			ids = append(ids, rowID)
+		}
+	}
+
+	sort.Ints(ids)
+
+	return ids
+}
+
+// remove removes the row with the ID, without checking any constraint.
+func (t *Table[T]) remove(id int) {
+	delete(t.rows, id)
+}
+
+// load inserts the rows keeping their IDs, without checking any constraint.
+func (t *Table[T]) load(rows []json.RawMessage) error {
+	for _, raw := range rows {
+		var row T
+
+		err := json.Unmarshal(raw, &row)
+		if err != nil {
+			return err
+		}
+
+		id := *t.id(&row)
+		if id < 1 {
+			id = t.lastID + 1
+			*t.id(&row) = id
+		}
+
+		t.rows[id] = row
+
+		if id > t.lastID {
+			t.lastID = id
+		}
+	}
+
+	return nil
+}
+
+// validate checks the constraints of every row.
+func (t *Table[T]) validate() error {
+	for _, row := range t.Rows() {
+		err := t.check(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
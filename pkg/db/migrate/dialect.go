@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	QueryCreateMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL)`
+
+	QueryGetMigrations = `SELECT version FROM schema_migrations`
+
+	QueryInsertMigration = `INSERT INTO schema_migrations(version,name) VALUES(?,?)`
+
+	QueryDeleteMigration = `DELETE FROM schema_migrations WHERE version = ?`
+
+	QueryInsertMigrationPostgres = `INSERT INTO schema_migrations(version,name) VALUES($1,$2)`
+
+	QueryDeleteMigrationPostgres = `DELETE FROM schema_migrations WHERE version = $1`
+
+	QueryTableExistsMySQL = `SELECT COUNT(*) FROM information_schema.tables
+	WHERE table_schema = DATABASE() AND table_name = ?`
+
+	QueryTableExistsPostgres = `SELECT COUNT(*) FROM information_schema.tables
+	WHERE table_schema = current_schema() AND table_name = $1`
+
+	QueryTableExistsSQLite = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+
+	QueryLockMySQL = `SELECT GET_LOCK(?, ?)`
+
+	QueryUnlockMySQL = `SELECT RELEASE_LOCK(?)`
+
+	QueryLockPostgres = `SELECT pg_advisory_lock($1)`
+
+	QueryUnlockPostgres = `SELECT pg_advisory_unlock($1)`
+)
+
+// lockName is the name of the advisory lock of the migrations in MySQL.
+const lockName = "schema_migrations"
+
+// lockTimeout is how many seconds MySQL waits for the advisory lock of the migrations.
+const lockTimeout = 60
+
+// lockKey is the key of the advisory lock of the migrations in PostgreSQL, any number not used by other locks.
+const lockKey = 20230915
+
+// ErrLocked is the error returned when the advisory lock of the migrations can't be taken in time, because another
+// instance of the app is running them.
+var ErrLocked = errors.New("migrations locked by another instance")
+
+// Dialect has what changes between databases to run the migrations, the placeholders of the queries recording them,
+// how a table is looked up and how the advisory lock is taken and released.
+type Dialect struct {
+	insert string
+	delete string
+	exists string
+	lock   func(ctx context.Context, conn *sql.Conn) error
+	unlock func(ctx context.Context, conn *sql.Conn) error
+}
+
+// MySQL is the dialect of MySQL, it locks the migrations with GET_LOCK.
+var MySQL = Dialect{
+	insert: QueryInsertMigration,
+	delete: QueryDeleteMigration,
+	exists: QueryTableExistsMySQL,
+	lock: func(ctx context.Context, conn *sql.Conn) error {
+		var locked sql.NullInt64
+
+		err := conn.QueryRowContext(ctx, QueryLockMySQL, lockName, lockTimeout).Scan(&locked)
+		if err != nil {
+			return err
+		}
+
+		if locked.Int64 != 1 {
+			return ErrLocked
+		}
+
+		return nil
+	},
+	unlock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, QueryUnlockMySQL, lockName)
+		return err
+	},
+}
+
+// Postgres is the dialect of PostgreSQL, it locks the migrations with pg_advisory_lock, waiting until the context is
+// done.
+var Postgres = Dialect{
+	insert: QueryInsertMigrationPostgres,
+	delete: QueryDeleteMigrationPostgres,
+	exists: QueryTableExistsPostgres,
+	lock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, QueryLockPostgres, lockKey)
+		return err
+	},
+	unlock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, QueryUnlockPostgres, lockKey)
+		return err
+	},
+}
+
+// SQLite is the dialect of SQLite, it has no advisory locks, but a migration and its version are written in the same
+// transaction, so a second instance applying it fails on the version already recorded and its changes are rolled back.
+var SQLite = Dialect{
+	insert: QueryInsertMigration,
+	delete: QueryDeleteMigration,
+	exists: QueryTableExistsSQLite,
+}
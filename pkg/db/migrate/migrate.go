@@ -0,0 +1,450 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ErrInvalidFile is the error returned when a migration file is not named like "0001_name.up.sql" or
+// "0001_name.down.sql".
+var ErrInvalidFile = errors.New("invalid migration file name, it must be like 0001_name.up.sql or 0001_name.down.sql")
+
+// ErrDuplicateVersion is the error returned when two migrations have the same version.
+var ErrDuplicateVersion = errors.New("duplicate migration version")
+
+// ErrMissingUp is the error returned when a migration has a down file but not an up one.
+var ErrMissingUp = errors.New("migration without up file")
+
+// ErrIrreversible is the error returned when reverting a migration without a down file.
+var ErrIrreversible = errors.New("migration without down file, it can't be reverted")
+
+// ErrUnknownVersion is the error returned when reverting a version applied to the database that's not in the files.
+var ErrUnknownVersion = errors.New("migration applied to the database not found in the files")
+
+// ErrNoneApplied is the error returned when reverting a migration and none is applied.
+var ErrNoneApplied = errors.New("no migration applied")
+
+// ErrPartialBaseline is the error returned when no migration is recorded but only some of the baseline tables exist,
+// so the schema was built by hand and must be fixed by hand too.
+var ErrPartialBaseline = errors.New("only some of the baseline tables exist, fix the schema by hand")
+
+// fileName matches the name of the migration files, capturing their version, name and direction.
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a numbered change of the schema, Up applies it and Down reverts it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and whether it's applied to the database.
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies and reverts the migrations of a database, keeping the versions applied in the schema_migrations
+// table. The migrations are run holding an advisory lock, so two instances of the app don't run them concurrently.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	baseline   []string
+}
+
+// New creates a new migrator with the migration files, the .sql files of any directory of files.
+// You can pass a series of options as variadic arguments to customize the migrator.
+func New(db *sql.DB, dialect Dialect, files fs.FS, options ...func(*Migrator)) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}
+
+	for _, o := range options {
+		o(m)
+	}
+
+	return m, nil
+}
+
+// WithBaseline sets the tables created by the first migration, so a database built before the migrations, with all of
+// them and no migration recorded, gets the first migration recorded as applied instead of running it.
+func WithBaseline(tables ...string) func(*Migrator) {
+	return func(m *Migrator) {
+		m.baseline = tables
+	}
+}
+
+// Up applies the pending migrations, from the oldest to the newest, and returns them.
+// Each migration is applied in a transaction with its version, but MySQL commits the schema changes implicitly, so a
+// failing migration may be left halfway there.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := make([]Migration, 0)
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		versions, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if len(versions) == 0 && len(m.migrations) > 0 {
+			err = m.markBaseline(ctx, conn, m.migrations[0], versions)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if versions[migration.Version] {
+				continue
+			}
+
+			err = m.run(ctx, conn, migration.Up, m.dialect.insert, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, err
+	}
+
+	return applied, nil
+}
+
+// Down reverts the newest migration applied and returns it.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	var reverted Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		versions, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		last := 0
+		for version := range versions {
+			if version > last {
+				last = version
+			}
+		}
+
+		if last == 0 {
+			return ErrNoneApplied
+		}
+
+		migration, ok := m.find(last)
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, last)
+		}
+
+		if migration.Down == "" {
+			return fmt.Errorf("%w: %d_%s", ErrIrreversible, migration.Version, migration.Name)
+		}
+
+		err = m.run(ctx, conn, migration.Down, m.dialect.delete, migration.Version)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		reverted = migration
+
+		return nil
+	})
+	if err != nil {
+		return Migration{}, err
+	}
+
+	return reverted, nil
+}
+
+// Status returns every migration, from the oldest to the newest, and whether it's applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeConn(conn)
+
+	versions, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status = append(status, Status{Migration: migration, Applied: versions[migration.Version]})
+	}
+
+	return status, nil
+}
+
+// Seed runs the statements of a seed file, like the test records, in a transaction. Seeds aren't recorded like the
+// migrations, so running one twice inserts its records twice, or fails on their unique columns.
+func Seed(ctx context.Context, db *sql.DB, file string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func(tx *sql.Tx) {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println(err)
+		}
+	}(tx)
+
+	for _, statement := range statements(file) {
+		_, err = tx.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// locked runs f with a connection holding the advisory lock of the migrations, the locks belong to the session that
+// takes them, so the same connection is used for everything.
+func (m *Migrator) locked(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer closeConn(conn)
+
+	if m.dialect.lock != nil {
+		err = m.dialect.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// The lock is released even when the context is done, since the connection goes back to the pool.
+		defer func() {
+			err := m.dialect.unlock(context.Background(), conn)
+			if err != nil {
+				log.Println(err)
+			}
+		}()
+	}
+
+	return f(conn)
+}
+
+// markBaseline records the first migration as applied, without running it, when all the baseline tables exist, and
+// adds it to the versions applied. It does nothing when none of them exist, the database is new.
+func (m *Migrator) markBaseline(ctx context.Context, conn *sql.Conn, first Migration, versions map[int]bool) error {
+	if len(m.baseline) == 0 {
+		return nil
+	}
+
+	existing := make([]string, 0, len(m.baseline))
+
+	for _, table := range m.baseline {
+		var count int
+
+		err := conn.QueryRowContext(ctx, m.dialect.exists, table).Scan(&count)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			existing = append(existing, table)
+		}
+	}
+
+	switch len(existing) {
+	case 0:
+		return nil
+	case len(m.baseline):
+	default:
+		return fmt.Errorf("%w: %v of %v", ErrPartialBaseline, existing, m.baseline)
+	}
+
+	err := m.run(ctx, conn, "", m.dialect.insert, first.Version, first.Name)
+	if err != nil {
+		return fmt.Errorf("baseline %d_%s: %w", first.Version, first.Name, err)
+	}
+
+	log.Printf("marked migration %04d_%s as applied, its tables already exist", first.Version, first.Name)
+
+	versions[first.Version] = true
+
+	return nil
+}
+
+// applied creates the schema_migrations table if it doesn't exist and returns the versions applied.
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	_, err := conn.ExecContext(ctx, QueryCreateMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, QueryGetMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	versions := make(map[int]bool)
+
+	for rows.Next() {
+		var version int
+
+		err := rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+
+		versions[version] = true
+	}
+
+	return versions, rows.Err()
+}
+
+// run runs the statements of a migration file and then the query recording it, with its args, in a transaction.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, file string, query string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func(tx *sql.Tx) {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println(err)
+		}
+	}(tx)
+
+	for _, statement := range statements(file) {
+		_, err = tx.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// find returns the migration with the version and reports whether it exists.
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// load reads the migration files, sorted by version.
+func load(files fs.FS) ([]Migration, error) {
+	byVersion := make(map[int]*Migration)
+
+	err := fs.WalkDir(files, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || path.Ext(p) != ".sql" {
+			return nil
+		}
+
+		match := fileName.FindStringSubmatch(d.Name())
+		if match == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidFile, p)
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version < 1 {
+			return fmt.Errorf("%w: %s", ErrInvalidFile, p)
+		}
+
+		content, err := fs.ReadFile(files, p)
+		if err != nil {
+			return err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+
+		script := &migration.Up
+		if match[3] == "down" {
+			script = &migration.Down
+		}
+
+		if *script != "" {
+			return fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+
+		*script = string(content)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// closeConn returns the connection to the pool.
+func closeConn(conn *sql.Conn) {
+	err := conn.Close()
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,122 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/migrate"
+	"github.com/Nachofra/final-esp-backend-3/pkg/db/sqlite"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// files has a first migration creating two tables, with a record, and a second one adding a third table.
+var files = fstest.MapFS{
+	"0001_initial.up.sql": {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY);\n" +
+		"CREATE TABLE b (id INTEGER PRIMARY KEY);\nINSERT INTO a (id) VALUES (1);")},
+	"0001_initial.down.sql": {Data: []byte("DROP TABLE b;\nDROP TABLE a;")},
+	"0002_c.up.sql":         {Data: []byte("CREATE TABLE c (id INTEGER PRIMARY KEY);")},
+}
+
+func TestUpBaseline(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		applied  []int
+		records  int
+		err      error
+	}{
+		{
+			name:    "new database",
+			applied: []int{1, 2},
+			records: 1,
+		},
+		{
+			name:     "every table exists",
+			existing: []string{"a", "b"},
+			applied:  []int{2},
+			records:  0,
+		},
+		{
+			name:     "some tables exist",
+			existing: []string{"a"},
+			err:      migrate.ErrPartialBaseline,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			db := open(t)
+
+			for _, table := range tt.existing {
+				_, err := db.ExecContext(ctx, "CREATE TABLE "+table+" (id INTEGER PRIMARY KEY)")
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			migrator, err := migrate.New(db, migrate.SQLite, files, migrate.WithBaseline("a", "b"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			applied, err := migrator.Up(ctx)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("up returned %v, want %v", err, tt.err)
+			}
+
+			if tt.err != nil {
+				return
+			}
+
+			versions := make([]int, 0, len(applied))
+			for _, m := range applied {
+				versions = append(versions, m.Version)
+			}
+
+			if !reflect.DeepEqual(versions, tt.applied) {
+				t.Errorf("applied %v, want %v", versions, tt.applied)
+			}
+
+			status, err := migrator.Status(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range status {
+				if !s.Applied {
+					t.Errorf("migration %d is pending, want it applied", s.Version)
+				}
+			}
+
+			var records int
+
+			err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM a").Scan(&records)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if records != tt.records {
+				t.Errorf("table a has %d records, want %d", records, tt.records)
+			}
+		})
+	}
+}
+
+// open opens a new SQLite database in a temporary directory.
+func open(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sqlite.Open(sqlite.New(sqlite.WithPath(filepath.Join(t.TempDir(), "clinic.db"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
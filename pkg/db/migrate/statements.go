@@ -0,0 +1,66 @@
+package migrate
+
+import "strings"
+
+// statements splits a migration file in its statements, by the semicolons out of quotes and comments, since the MySQL
+// driver runs one statement at a time. Empty statements and comments are left out.
+func statements(file string) []string {
+	result := make([]string, 0)
+
+	var current strings.Builder
+
+	quote := rune(0)
+	comment := false
+	code := false
+
+	flush := func() {
+		if code {
+			result = append(result, strings.TrimSpace(current.String()))
+		}
+
+		current.Reset()
+		code = false
+	}
+
+	runes := []rune(file)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			comment = true
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ';':
+			flush()
+			continue
+		}
+
+		if !isSpace(r) {
+			code = true
+		}
+
+		current.WriteRune(r)
+	}
+
+	flush()
+
+	return result
+}
+
+// isSpace reports whether r is a blank character.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
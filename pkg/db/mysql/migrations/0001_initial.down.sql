@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `appointment`;
+DROP TABLE IF EXISTS `patient`;
+DROP TABLE IF EXISTS `dentist`;
@@ -0,0 +1,58 @@
+-- Tables of the clinic, the ones the clinic.sql script created before the migrations.
+
+-- -----------------------------------------------------
+-- Table `dentist`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `dentist` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `first_name` VARCHAR(45) NOT NULL,
+  `last_name` VARCHAR(45) NOT NULL,
+  `registration_number` VARCHAR(45) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `id_UNIQUE` (`id` ASC) VISIBLE,
+  UNIQUE INDEX `registration_number_UNIQUE` (`registration_number` ASC) VISIBLE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `patient`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `patient` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `first_name` VARCHAR(45) NOT NULL,
+  `last_name` VARCHAR(45) NOT NULL,
+  `address` VARCHAR(80) NOT NULL,
+  `dni` INT NOT NULL,
+  `discharge_date` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `id_UNIQUE` (`id` ASC) VISIBLE,
+  UNIQUE INDEX `dni_UNIQUE` (`dni` ASC) VISIBLE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `appointment`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `appointment` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `patient_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NOT NULL,
+  `date` DATETIME NOT NULL,
+  `description` VARCHAR(100) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `id_UNIQUE` (`id` ASC) VISIBLE,
+  INDEX `appointment_dentist_dentist_id_id_idx` (`dentist_id` ASC) VISIBLE,
+  INDEX `appointment_patient_patient_id_id` (`patient_id` ASC) VISIBLE,
+  CONSTRAINT `appointment_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`),
+  CONSTRAINT `appointment_patient_patient_id_id`
+    FOREIGN KEY (`patient_id`)
+    REFERENCES `patient` (`id`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
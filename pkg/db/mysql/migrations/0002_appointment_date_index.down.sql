@@ -0,0 +1 @@
+ALTER TABLE `appointment` DROP INDEX `appointment_date_idx`;
@@ -0,0 +1,2 @@
+-- Index of the dates of the appointments, the overlap checks look them up by date.
+ALTER TABLE `appointment` ADD INDEX `appointment_date_idx` (`date` ASC) VISIBLE;
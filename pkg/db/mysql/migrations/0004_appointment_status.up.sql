@@ -0,0 +1,2 @@
+-- Status of the appointments, the ones created before it are scheduled.
+ALTER TABLE `appointment` ADD COLUMN `status` VARCHAR(15) NOT NULL DEFAULT 'scheduled' AFTER `description`;
@@ -0,0 +1,2 @@
+ALTER TABLE `patient` DROP COLUMN `active`;
+ALTER TABLE `dentist` DROP COLUMN `active`;
@@ -0,0 +1,3 @@
+-- Whether the dentists and the patients are active, the ones created before it are.
+ALTER TABLE `dentist` ADD COLUMN `active` TINYINT(1) NOT NULL DEFAULT 1 AFTER `registration_number`;
+ALTER TABLE `patient` ADD COLUMN `active` TINYINT(1) NOT NULL DEFAULT 1 AFTER `discharge_date`;
@@ -0,0 +1,19 @@
+-- -----------------------------------------------------
+-- Table `shift`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `shift` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `dentist_id` BIGINT NOT NULL,
+  `weekday` TINYINT NOT NULL,
+  `start_time` TIME NOT NULL,
+  `end_time` TIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `id_UNIQUE` (`id` ASC) VISIBLE,
+  INDEX `shift_dentist_dentist_id_id_idx` (`dentist_id` ASC, `weekday` ASC) VISIBLE,
+  CONSTRAINT `shift_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`)
+    ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
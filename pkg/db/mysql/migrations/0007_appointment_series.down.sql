@@ -0,0 +1,3 @@
+ALTER TABLE `appointment`
+  DROP INDEX `appointment_series_id_idx`,
+  DROP COLUMN `series_id`;
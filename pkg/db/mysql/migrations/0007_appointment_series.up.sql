@@ -0,0 +1,4 @@
+-- Series of the recurring appointments, the ones created before it belong to none.
+ALTER TABLE `appointment`
+  ADD COLUMN `series_id` CHAR(36) NULL AFTER `status`,
+  ADD INDEX `appointment_series_id_idx` (`series_id` ASC) VISIBLE;
@@ -0,0 +1,61 @@
+-- -----------------------------------------------------
+-- Table `waitlist_entry`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `waitlist_entry` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `patient_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NULL,
+  `priority` TINYINT NOT NULL DEFAULT 0,
+  `status` VARCHAR(10) NOT NULL DEFAULT 'waiting',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `id_UNIQUE` (`id` ASC) VISIBLE,
+  INDEX `waitlist_entry_status_idx` (`status` ASC, `priority` DESC) VISIBLE,
+  CONSTRAINT `waitlist_entry_patient_patient_id_id`
+    FOREIGN KEY (`patient_id`)
+    REFERENCES `patient` (`id`)
+    ON DELETE CASCADE,
+  CONSTRAINT `waitlist_entry_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`)
+    ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+-- -----------------------------------------------------
+-- Table `waitlist_range`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `waitlist_range` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `entry_id` BIGINT NOT NULL,
+  `start_date` DATETIME NOT NULL,
+  `end_date` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `waitlist_range_entry_id_idx` (`entry_id` ASC) VISIBLE,
+  CONSTRAINT `waitlist_range_waitlist_entry_entry_id_id`
+    FOREIGN KEY (`entry_id`)
+    REFERENCES `waitlist_entry` (`id`)
+    ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+-- -----------------------------------------------------
+-- Table `waitlist_offer`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `waitlist_offer` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `entry_id` BIGINT NOT NULL,
+  `appointment_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NOT NULL,
+  `date` DATETIME NOT NULL,
+  `duration` INT NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `waitlist_offer_entry_id_idx` (`entry_id` ASC) VISIBLE,
+  CONSTRAINT `waitlist_offer_waitlist_entry_entry_id_id`
+    FOREIGN KEY (`entry_id`)
+    REFERENCES `waitlist_entry` (`id`)
+    ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
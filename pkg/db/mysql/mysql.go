@@ -1,11 +1,29 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
+	"log"
+	"net/url"
+	"time"
 )
 
+// maxBackoff is the longest wait between two attempts to connect, the backoff doubles after every attempt up to it.
+const maxBackoff = 30 * time.Second
+
+// Migrations has the migrations of the MySQL schema, they're run by the app when it starts and by cmd/migrate.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// TestRecords has the test records of the MySQL schema, they're only loaded on demand, by cmd/seed.
+//
+//go:embed seeds/test_records.sql
+var TestRecords string
+
 // Config centralizes the parameters required to construct the MySQL database URL.
 type Config struct {
 	username  string
@@ -14,6 +32,24 @@ type Config struct {
 	name      string
 	charset   string
 	parseTime bool
+
+	// tls is the tls parameter of the driver, true, false, skip-verify, preferred or the name of a registered config.
+	tls string
+
+	// dialTimeout, readTimeout and writeTimeout are the timeouts of the driver, zero ones are not set.
+	dialTimeout  time.Duration
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	// maxOpenConns, maxIdleConns and connMaxLifetime limit the connection pool, zero ones keep the defaults of sql.DB.
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+
+	// connectAttempts is how many times Open pings the database before giving up, waiting backoff after the first
+	// failure and twice as long after each next one.
+	connectAttempts int
+	backoff         time.Duration
 }
 
 // New creates a new MySQL configuration by applying all the provided options to it.
@@ -24,6 +60,9 @@ func New(options ...func(*Config)) *Config {
 	// Setting default charset
 	db.charset = "utf8"
 
+	// Setting a single attempt to connect by default
+	db.connectAttempts = 1
+
 	for _, o := range options {
 		o(db)
 	}
@@ -31,23 +70,91 @@ func New(options ...func(*Config)) *Config {
 }
 
 // Open establishes a connection to the database using the provided configuration and returns the database connection.
+// It pings the database until it answers, so the app doesn't start before the database is ready, and fails with the
+// error of the last attempt when it never does.
 func Open(cfg *Config) (*sql.DB, error) {
 	db, err := cfg.start()
 	if err != nil {
 		return nil, err
 	}
 
+	err = cfg.connect(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
 	return db, nil
 }
 
-// start opens a MySQL database connection using the current configuration.
+// start opens a MySQL database connection using the current configuration, with the limits of its pool.
 func (cfg *Config) start() (*sql.DB, error) {
-	return sql.Open("mysql", cfg.getConnectionString())
+	db, err := sql.Open("mysql", cfg.getConnectionString())
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.maxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.maxOpenConns)
+	}
+
+	if cfg.maxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.maxIdleConns)
+	}
+
+	if cfg.connMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.connMaxLifetime)
+	}
+
+	return db, nil
+}
+
+// connect pings the database up to connectAttempts times, doubling the wait after every failure up to maxBackoff.
+func (cfg *Config) connect(db *sql.DB) error {
+	wait := cfg.backoff
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = db.PingContext(context.Background())
+		if err == nil || attempt >= cfg.connectAttempts {
+			return err
+		}
+
+		log.Printf("database not ready, attempt %d of %d: %v, retrying in %s", attempt, cfg.connectAttempts, err, wait)
+
+		time.Sleep(wait)
+
+		wait *= 2
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+	}
 }
 
 // getConnectionString generates the MySQL connection string based on the current configuration.
 func (cfg *Config) getConnectionString() string {
-	return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=%s&parseTime=%t", cfg.username, cfg.password, cfg.host, cfg.name, cfg.charset, cfg.parseTime)
+	params := url.Values{}
+	params.Set("charset", cfg.charset)
+	params.Set("parseTime", fmt.Sprint(cfg.parseTime))
+
+	if cfg.tls != "" {
+		params.Set("tls", cfg.tls)
+	}
+
+	if cfg.dialTimeout > 0 {
+		params.Set("timeout", cfg.dialTimeout.String())
+	}
+
+	if cfg.readTimeout > 0 {
+		params.Set("readTimeout", cfg.readTimeout.String())
+	}
+
+	if cfg.writeTimeout > 0 {
+		params.Set("writeTimeout", cfg.writeTimeout.String())
+	}
+
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s", cfg.username, cfg.password, cfg.host, cfg.name, params.Encode())
 }
 
 // WithUsername is used to set database username in the config.
@@ -91,3 +198,51 @@ func WithParseTime(parseTime bool) func(*Config) {
 		db.parseTime = parseTime
 	}
 }
+
+// WithTLS sets the tls param of the database URL, true, false, skip-verify, preferred or the name of a config
+// registered with mysql.RegisterTLSConfig. It's not set when it's empty.
+func WithTLS(tls string) func(*Config) {
+	return func(db *Config) {
+		db.tls = tls
+	}
+}
+
+// WithTimeouts sets the timeouts of the database URL params, to establish a connection and to read and write on it,
+// zero ones are not set.
+func WithTimeouts(dial time.Duration, read time.Duration, write time.Duration) func(*Config) {
+	return func(db *Config) {
+		db.dialTimeout = dial
+		db.readTimeout = read
+		db.writeTimeout = write
+	}
+}
+
+// WithMaxOpenConns sets the maximum number of open connections of the pool, zero keeps it unlimited.
+func WithMaxOpenConns(n int) func(*Config) {
+	return func(db *Config) {
+		db.maxOpenConns = n
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle connections of the pool, zero keeps the default of sql.DB.
+func WithMaxIdleConns(n int) func(*Config) {
+	return func(db *Config) {
+		db.maxIdleConns = n
+	}
+}
+
+// WithConnMaxLifetime sets how long a connection of the pool is reused, zero reuses them forever.
+func WithConnMaxLifetime(d time.Duration) func(*Config) {
+	return func(db *Config) {
+		db.connMaxLifetime = d
+	}
+}
+
+// WithConnectRetry sets how many times Open tries to connect, waiting backoff after the first failure and twice as
+// long after each next one, up to maxBackoff.
+func WithConnectRetry(attempts int, backoff time.Duration) func(*Config) {
+	return func(db *Config) {
+		db.connectAttempts = attempts
+		db.backoff = backoff
+	}
+}
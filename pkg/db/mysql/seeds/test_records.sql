@@ -0,0 +1,30 @@
+-- Test records of the clinic, loaded by 'cmd/seed --test-records' into an empty database, so the records refer to each
+-- other by the IDs they get there.
+
+-- Test records for the 'dentist' table
+INSERT INTO `dentist` (`first_name`, `last_name`, `registration_number`) VALUES
+ ('Dr. Smile', 'McDentist', '12345'),
+ ('Dr. Sparkle', 'Tooth Fairy', '67890'),
+ ('Dr. Chomp', 'Flossington', '54321');
+
+-- Test records for the 'patient' table
+INSERT INTO `patient` (`first_name`, `last_name`, `address`, `dni`, `discharge_date`) VALUES
+('Toothless', 'McGums', '123 Cavity Ln', 12345678, '2023-09-15 09:00:00'),
+('Candy', 'Cane', '456 Sugar Ave', 98765432, '2023-09-16 10:30:00'),
+('Molar', 'Incisor', '789 Brush St', 56789012, '2023-09-17 14:15:00');
+
+-- Test records for the 'appointment' table
+INSERT INTO `appointment` (`patient_id`, `dentist_id`, `date`, `description`) VALUES
+(1, 1, '2023-09-15 11:30:00', 'Appointment for a dazzling smile'),
+(2, 2, '2023-09-16 15:45:00', 'Magical dental cleaning'),
+(3, 3, '2023-09-17 09:30:00', 'Chew-style tooth extraction operation');
+
+-- Test records for the 'shift' table (weekday goes from 0, Sunday, to 6, Saturday)
+INSERT INTO `shift` (`dentist_id`, `weekday`, `start_time`, `end_time`) VALUES
+(1, 1, '09:00', '13:00'), (1, 1, '14:00', '18:00'),
+(1, 2, '09:00', '13:00'), (1, 2, '14:00', '18:00'),
+(1, 3, '09:00', '13:00'), (1, 3, '14:00', '18:00'),
+(1, 4, '09:00', '13:00'), (1, 4, '14:00', '18:00'),
+(1, 5, '09:00', '13:00'), (1, 5, '14:00', '18:00'),
+(2, 1, '10:00', '19:00'), (2, 3, '10:00', '19:00'), (2, 5, '10:00', '19:00'), (2, 6, '09:00', '16:00'),
+(3, 0, '08:00', '12:00'), (3, 2, '08:00', '12:00'), (3, 4, '08:00', '12:00');
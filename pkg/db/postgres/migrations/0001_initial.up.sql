@@ -0,0 +1,42 @@
+-- Tables of the clinic, equivalent to the ones of the first MySQL migration.
+
+-- -----------------------------------------------------
+-- Table dentist
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS dentist (
+  id BIGSERIAL PRIMARY KEY,
+  first_name VARCHAR(45) NOT NULL,
+  last_name VARCHAR(45) NOT NULL,
+  registration_number VARCHAR(45) NOT NULL,
+  CONSTRAINT registration_number_UNIQUE UNIQUE (registration_number));
+
+-- -----------------------------------------------------
+-- Table patient
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS patient (
+  id BIGSERIAL PRIMARY KEY,
+  first_name VARCHAR(45) NOT NULL,
+  last_name VARCHAR(45) NOT NULL,
+  address VARCHAR(80) NOT NULL,
+  dni INT NOT NULL,
+  discharge_date TIMESTAMP NOT NULL,
+  CONSTRAINT dni_UNIQUE UNIQUE (dni));
+
+-- -----------------------------------------------------
+-- Table appointment
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS appointment (
+  id BIGSERIAL PRIMARY KEY,
+  patient_id BIGINT NOT NULL,
+  dentist_id BIGINT NOT NULL,
+  date TIMESTAMP NOT NULL,
+  description VARCHAR(100) NOT NULL,
+  CONSTRAINT appointment_dentist_dentist_id_id
+    FOREIGN KEY (dentist_id)
+    REFERENCES dentist (id),
+  CONSTRAINT appointment_patient_patient_id_id
+    FOREIGN KEY (patient_id)
+    REFERENCES patient (id));
+
+CREATE INDEX IF NOT EXISTS appointment_dentist_dentist_id_id_idx ON appointment (dentist_id);
+CREATE INDEX IF NOT EXISTS appointment_patient_patient_id_id ON appointment (patient_id);
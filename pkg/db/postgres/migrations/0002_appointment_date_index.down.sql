@@ -0,0 +1 @@
+DROP INDEX IF EXISTS appointment_date_idx;
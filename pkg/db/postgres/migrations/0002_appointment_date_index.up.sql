@@ -0,0 +1,2 @@
+-- Index of the dates of the appointments, the overlap checks look them up by date.
+CREATE INDEX IF NOT EXISTS appointment_date_idx ON appointment (date);
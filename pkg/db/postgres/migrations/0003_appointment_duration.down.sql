@@ -0,0 +1 @@
+ALTER TABLE appointment DROP COLUMN duration;
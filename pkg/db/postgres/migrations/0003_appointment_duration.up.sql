@@ -0,0 +1,2 @@
+-- Duration of the appointments in minutes, the ones created before it last the default 30.
+ALTER TABLE appointment ADD COLUMN duration INT NOT NULL DEFAULT 30;
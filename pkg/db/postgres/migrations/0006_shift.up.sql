@@ -0,0 +1,15 @@
+-- -----------------------------------------------------
+-- Table shift
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS shift (
+  id BIGSERIAL PRIMARY KEY,
+  dentist_id BIGINT NOT NULL,
+  weekday SMALLINT NOT NULL,
+  start_time TIME NOT NULL,
+  end_time TIME NOT NULL,
+  CONSTRAINT shift_dentist_dentist_id_id
+    FOREIGN KEY (dentist_id)
+    REFERENCES dentist (id)
+    ON DELETE CASCADE);
+
+CREATE INDEX IF NOT EXISTS shift_dentist_dentist_id_id_idx ON shift (dentist_id, weekday);
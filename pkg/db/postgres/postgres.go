@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"database/sql"
+	"embed"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"net/url"
+)
+
+// Migrations has the migrations of the PostgreSQL schema, equivalent to the MySQL ones.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// TestRecords has the test records of the PostgreSQL schema, they're only loaded on demand, by cmd/seed.
+//
+//go:embed seeds/test_records.sql
+var TestRecords string
+
+// Config centralizes the parameters required to construct the PostgreSQL database URL.
+type Config struct {
+	username string
+	password string
+	host     string
+	name     string
+	sslMode  string
+}
+
+// New creates a new PostgreSQL configuration by applying all the provided options to it.
+// You can pass a series of options as variadic arguments to customize the configuration.
+func New(options ...func(*Config)) *Config {
+	db := &Config{}
+
+	// Setting default SSL mode
+	db.sslMode = "disable"
+
+	for _, o := range options {
+		o(db)
+	}
+	return db
+}
+
+// Open establishes a connection to the database using the provided configuration and returns the database connection.
+func Open(cfg *Config) (*sql.DB, error) {
+	db, err := cfg.start()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// start opens a PostgreSQL database connection using the current configuration.
+func (cfg *Config) start() (*sql.DB, error) {
+	return sql.Open("pgx", cfg.getConnectionString())
+}
+
+// getConnectionString generates the PostgreSQL connection URL based on the current configuration.
+func (cfg *Config) getConnectionString() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.username, cfg.password),
+		Host:     cfg.host,
+		Path:     cfg.name,
+		RawQuery: url.Values{"sslmode": {cfg.sslMode}}.Encode(),
+	}
+
+	return u.String()
+}
+
+// WithUsername is used to set database username in the config.
+func WithUsername(username string) func(*Config) {
+	return func(db *Config) {
+		db.username = username
+	}
+}
+
+// WithPassword is used to set database password in the config.
+func WithPassword(password string) func(*Config) {
+	return func(db *Config) {
+		db.password = password
+	}
+}
+
+// WithHost is used to set database host in the config, with its port.
+func WithHost(host string) func(*Config) {
+	return func(db *Config) {
+		db.host = host
+	}
+}
+
+// WithName is used to set database name in the config.
+func WithName(name string) func(*Config) {
+	return func(db *Config) {
+		db.name = name
+	}
+}
+
+// WithSSLMode sets sslmode in the database URL param, it's "disable" by default.
+func WithSSLMode(sslMode string) func(*Config) {
+	return func(db *Config) {
+		db.sslMode = sslMode
+	}
+}
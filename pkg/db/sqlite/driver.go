@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"github.com/Nachofra/final-esp-backend-3/pkg/fuzzy"
+
+	// The pure Go driver doesn't need cgo, so the app is built with it as it is, in every platform Go supports.
+	modernc "modernc.org/sqlite"
+)
+
+// init registers normalize in the driver, it normalizes a text like fuzzy.Normalize, so the stores can match it to
+// normalized terms whatever its accents and case. Values that aren't texts, like NULL, are returned as they are.
+func init() {
+	modernc.MustRegisterDeterministicScalarFunction("normalize", 1,
+		func(_ *modernc.FunctionContext, args []driver.Value) (driver.Value, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return args[0], nil
+			}
+
+			return fuzzy.Normalize(s), nil
+		})
+}
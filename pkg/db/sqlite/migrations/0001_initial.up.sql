@@ -0,0 +1,44 @@
+-- Tables of the clinic, equivalent to the ones of the first MySQL migration.
+-- SQLite doesn't enforce the length of text columns nor the range of integers, so they are checked explicitly, and
+-- dates are written as text in the 'YYYY-MM-DD HH:MM:SS' format, so they can be compared like the MySQL ones.
+
+-- -----------------------------------------------------
+-- Table `dentist`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `dentist` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `first_name` VARCHAR(45) NOT NULL CHECK (length(`first_name`) <= 45),
+  `last_name` VARCHAR(45) NOT NULL CHECK (length(`last_name`) <= 45),
+  `registration_number` VARCHAR(45) NOT NULL CHECK (length(`registration_number`) <= 45),
+  CONSTRAINT `registration_number_UNIQUE` UNIQUE (`registration_number`));
+
+-- -----------------------------------------------------
+-- Table `patient`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `patient` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `first_name` VARCHAR(45) NOT NULL CHECK (length(`first_name`) <= 45),
+  `last_name` VARCHAR(45) NOT NULL CHECK (length(`last_name`) <= 45),
+  `address` VARCHAR(80) NOT NULL CHECK (length(`address`) <= 80),
+  `dni` INT NOT NULL CHECK (`dni` BETWEEN -2147483648 AND 2147483647),
+  `discharge_date` DATETIME NOT NULL,
+  CONSTRAINT `dni_UNIQUE` UNIQUE (`dni`));
+
+-- -----------------------------------------------------
+-- Table `appointment`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `appointment` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `patient_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NOT NULL,
+  `date` DATETIME NOT NULL,
+  `description` VARCHAR(100) NOT NULL CHECK (length(`description`) <= 100),
+  CONSTRAINT `appointment_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`),
+  CONSTRAINT `appointment_patient_patient_id_id`
+    FOREIGN KEY (`patient_id`)
+    REFERENCES `patient` (`id`));
+
+CREATE INDEX IF NOT EXISTS `appointment_dentist_dentist_id_id_idx` ON `appointment` (`dentist_id`);
+CREATE INDEX IF NOT EXISTS `appointment_patient_patient_id_id` ON `appointment` (`patient_id`);
@@ -0,0 +1,3 @@
+-- Duration of the appointments in minutes, the ones created before it last the default 30.
+ALTER TABLE `appointment` ADD COLUMN `duration` INT NOT NULL DEFAULT 30
+  CHECK (`duration` BETWEEN -2147483648 AND 2147483647);
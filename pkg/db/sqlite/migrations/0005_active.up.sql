@@ -0,0 +1,3 @@
+-- Whether the dentists and the patients are active, the ones created before it are.
+ALTER TABLE `dentist` ADD COLUMN `active` BOOLEAN NOT NULL DEFAULT 1;
+ALTER TABLE `patient` ADD COLUMN `active` BOOLEAN NOT NULL DEFAULT 1;
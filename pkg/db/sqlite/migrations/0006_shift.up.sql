@@ -0,0 +1,15 @@
+-- -----------------------------------------------------
+-- Table `shift` (hours are written as text in the 'HH:MM' format)
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `shift` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `dentist_id` BIGINT NOT NULL,
+  `weekday` TINYINT NOT NULL,
+  `start_time` TEXT NOT NULL,
+  `end_time` TEXT NOT NULL,
+  CONSTRAINT `shift_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`)
+    ON DELETE CASCADE);
+
+CREATE INDEX IF NOT EXISTS `shift_dentist_dentist_id_id_idx` ON `shift` (`dentist_id`, `weekday`);
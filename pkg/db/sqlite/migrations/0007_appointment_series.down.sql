@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `appointment_series_id_idx`;
+ALTER TABLE `appointment` DROP COLUMN `series_id`;
@@ -0,0 +1,4 @@
+-- Series of the recurring appointments, the ones created before it belong to none.
+ALTER TABLE `appointment` ADD COLUMN `series_id` CHAR(36) NULL CHECK (length(`series_id`) <= 36);
+
+CREATE INDEX IF NOT EXISTS `appointment_series_id_idx` ON `appointment` (`series_id`);
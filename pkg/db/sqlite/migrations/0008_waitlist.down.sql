@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `waitlist_offer`;
+DROP TABLE IF EXISTS `waitlist_range`;
+DROP TABLE IF EXISTS `waitlist_entry`;
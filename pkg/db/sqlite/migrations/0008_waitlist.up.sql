@@ -0,0 +1,51 @@
+-- -----------------------------------------------------
+-- Table `waitlist_entry`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `waitlist_entry` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `patient_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NULL,
+  `priority` TINYINT NOT NULL DEFAULT 0,
+  `status` VARCHAR(10) NOT NULL DEFAULT 'waiting' CHECK (length(`status`) <= 10),
+  CONSTRAINT `waitlist_entry_patient_patient_id_id`
+    FOREIGN KEY (`patient_id`)
+    REFERENCES `patient` (`id`)
+    ON DELETE CASCADE,
+  CONSTRAINT `waitlist_entry_dentist_dentist_id_id`
+    FOREIGN KEY (`dentist_id`)
+    REFERENCES `dentist` (`id`)
+    ON DELETE CASCADE);
+
+CREATE INDEX IF NOT EXISTS `waitlist_entry_status_idx` ON `waitlist_entry` (`status`, `priority` DESC);
+
+-- -----------------------------------------------------
+-- Table `waitlist_range`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `waitlist_range` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `entry_id` BIGINT NOT NULL,
+  `start_date` DATETIME NOT NULL,
+  `end_date` DATETIME NOT NULL,
+  CONSTRAINT `waitlist_range_waitlist_entry_entry_id_id`
+    FOREIGN KEY (`entry_id`)
+    REFERENCES `waitlist_entry` (`id`)
+    ON DELETE CASCADE);
+
+CREATE INDEX IF NOT EXISTS `waitlist_range_entry_id_idx` ON `waitlist_range` (`entry_id`);
+
+-- -----------------------------------------------------
+-- Table `waitlist_offer`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `waitlist_offer` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `entry_id` BIGINT NOT NULL,
+  `appointment_id` BIGINT NOT NULL,
+  `dentist_id` BIGINT NOT NULL,
+  `date` DATETIME NOT NULL,
+  `duration` INT NOT NULL,
+  CONSTRAINT `waitlist_offer_waitlist_entry_entry_id_id`
+    FOREIGN KEY (`entry_id`)
+    REFERENCES `waitlist_entry` (`id`)
+    ON DELETE CASCADE);
+
+CREATE INDEX IF NOT EXISTS `waitlist_offer_entry_id_idx` ON `waitlist_offer` (`entry_id`);
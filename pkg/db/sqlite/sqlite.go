@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+)
+
+// driverName is the name the pure Go SQLite driver registers itself with, see driver.go.
+const driverName = "sqlite"
+
+// Migrations has the migrations of the SQLite schema, equivalent to the MySQL ones.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// TestRecords has the test records of the SQLite schema, they're only loaded on demand, by cmd/seed.
+//
+//go:embed seeds/test_records.sql
+var TestRecords string
+
+// Config centralizes the parameters required to construct the SQLite database URL.
+type Config struct {
+	path string
+}
+
+// New creates a new SQLite configuration by applying all the provided options to it.
+// You can pass a series of options as variadic arguments to customize the configuration.
+func New(options ...func(*Config)) *Config {
+	db := &Config{}
+
+	// Setting default path
+	db.path = "clinic.db"
+
+	for _, o := range options {
+		o(db)
+	}
+	return db
+}
+
+// Open establishes a connection to the database using the provided configuration and returns the database connection.
+// The file is created when it doesn't exist, its tables are created by the migrations.
+func Open(cfg *Config) (*sql.DB, error) {
+	db, err := cfg.start()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// start opens a SQLite database connection using the current configuration.
+// SQLite only allows one writer at a time, so a single connection is used, which serializes the transactions of the
+// stores like the row locks of MySQL do.
+func (cfg *Config) start() (*sql.DB, error) {
+	db, err := sql.Open(driverName, cfg.getConnectionString())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// getConnectionString generates the SQLite connection string based on the current configuration, foreign keys are
+// enabled since SQLite doesn't check them by default.
+func (cfg *Config) getConnectionString() string {
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", cfg.path)
+}
+
+// WithPath is used to set the path of the database file in the config.
+func WithPath(path string) func(*Config) {
+	return func(db *Config) {
+		db.path = path
+	}
+}
@@ -0,0 +1,127 @@
+package fuzzy
+
+import (
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// prefixScore is the score of a word starting with the term, below an exact match but over most typos.
+const prefixScore = 0.9
+
+// Normalize lowercases s and removes its accents, and everything that is not a letter or a digit becomes a space,
+// so "González-Pérez" and "gonzalez perez" are the same.
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		result = s
+	}
+
+	return strings.Join(strings.FieldsFunc(strings.ToLower(result), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Distance returns the Levenshtein distance between a and b, the number of runes inserted, deleted or replaced to
+// change one into the other.
+func Distance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+// Similarity returns how similar a and b are, from 0 when they have nothing in common to 1 when they're equal.
+func Similarity(a string, b string) float64 {
+	length := max(len([]rune(a)), len([]rune(b)))
+	if length == 0 {
+		return 1
+	}
+
+	return 1 - float64(Distance(a, b))/float64(length)
+}
+
+// Score returns how well a normalized term matches any word of text, from 0 to 1: 1 for the same word, a bit less for
+// a word starting with the term, and the Similarity of the closest word otherwise.
+func Score(term string, text string) float64 {
+	best := 0.0
+
+	for _, word := range strings.Fields(Normalize(text)) {
+		score := Similarity(term, word)
+
+		if word != term && len([]rune(term)) > 1 && strings.HasPrefix(word, term) {
+			score = max(score, prefixScore)
+		}
+
+		best = max(best, score)
+	}
+
+	return best
+}
+
+// Chunks splits a normalized term in pieces, every word at least minSimilarity similar to it, or starting with it,
+// contains one of them at least. Such a word is at most len(term)/minSimilarity runes long, so it's that many times
+// 1-minSimilarity edits away at most, and every edit breaks a single piece, so one more piece than edits is left whole.
+func Chunks(term string, minSimilarity float64) []string {
+	r := []rune(term)
+	if len(r) == 0 || minSimilarity <= 0 {
+		return nil
+	}
+
+	// The tolerance keeps the edits from being rounded down when the division isn't exact in floating point.
+	edits := int(float64(len(r))*(1-minSimilarity)/minSimilarity + 1e-9)
+	pieces := min(edits+1, len(r))
+
+	chunks := make([]string, 0, pieces)
+	for i := 0; i < pieces; i++ {
+		chunks = append(chunks, string(r[i*len(r)/pieces:(i+1)*len(r)/pieces]))
+	}
+
+	return chunks
+}
+
+// min returns the smallest of the values.
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// max returns the largest of a and b.
+func max[T int | float64](a T, b T) T {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,139 @@
+package fuzzy_test
+
+import (
+	"github.com/Nachofra/final-esp-backend-3/pkg/fuzzy"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "González", want: "gonzalez"},
+		{s: "GONZÁLEZ-PÉREZ", want: "gonzalez perez"},
+		{s: "  Muñoz,   Ñandú ", want: "munoz nandu"},
+		{s: "O'Brien", want: "o brien"},
+		{s: "Av. Córdoba 1234", want: "av cordoba 1234"},
+		{s: "¿?!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got := fuzzy.Normalize(tt.s)
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "abc", want: 3},
+		{a: "gonzales", b: "gonzalez", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "perez", b: "pérez", want: 1},
+		{a: "ana", b: "ana", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			got := fuzzy.Distance(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+
+			if reverse := fuzzy.Distance(tt.b, tt.a); reverse != got {
+				t.Errorf("Distance(%q, %q) = %d, want it the same both ways, %d", tt.b, tt.a, reverse, got)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name string
+		term string
+		text string
+		want float64
+	}{
+		{name: "same word", term: "gonzalez", text: "González", want: 1},
+		{name: "accents and case", term: "perez", text: "PÉREZ", want: 1},
+		{name: "typo", term: "gonzales", text: "González", want: 0.875},
+		{name: "prefix", term: "gonz", text: "González", want: 0.9},
+		{name: "best word", term: "cane", text: "Candy Cane", want: 1},
+		{name: "single letter prefix", term: "g", text: "González", want: 0.125},
+		{name: "nothing in common", term: "xyz", text: "Ana", want: 0},
+		{name: "empty text", term: "ana", text: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fuzzy.Score(tt.term, tt.text)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score(%q, %q) = %v, want %v", tt.term, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{term: "", want: nil},
+		{term: "a", want: []string{"a"}},
+		{term: "an", want: []string{"an"}},
+		{term: "ana", want: []string{"a", "na"}},
+		{term: "gonzales", want: []string{"go", "nz", "al", "es"}},
+		{term: "munoz", want: []string{"m", "un", "oz"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			got := fuzzy.Chunks(tt.term, 0.7)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chunks(%q, 0.7) = %q, want %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestChunksSimilarWords checks that every word similar enough to a term, or starting with it, has one of its chunks.
+func TestChunksSimilarWords(t *testing.T) {
+	const minSimilarity = 0.7
+
+	terms := []string{"ana", "gonzales", "perez", "rodrigues", "martinez", "fernandes", "lopes", "sanches"}
+	words := []string{"ana", "anna", "gonzalez", "gonzalezz", "ggonzalez", "perez", "peres", "rodriguez", "martines",
+		"martin", "fernandez", "lopez", "sanchez", "sanchezz", "xgonzalesx", "onzales", "gonzalesperez"}
+
+	for _, term := range terms {
+		chunks := fuzzy.Chunks(term, minSimilarity)
+
+		for _, word := range words {
+			if fuzzy.Score(term, word) < minSimilarity {
+				continue
+			}
+
+			found := false
+			for _, chunk := range chunks {
+				found = found || strings.Contains(word, chunk)
+			}
+
+			if !found {
+				t.Errorf("%q scores %v for %q, but has none of its chunks %q", word, fuzzy.Score(term, word), term,
+					chunks)
+			}
+		}
+	}
+}
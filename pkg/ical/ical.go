@@ -0,0 +1,132 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ContentType is the media type of iCalendar documents.
+	ContentType = "text/calendar; charset=utf-8"
+
+	// maxLineLength is the longest a content line can be, in octets and without the line break, as RFC 5545 says.
+	maxLineLength = 75
+
+	// dateTime is the format of the UTC date-times.
+	dateTime = "20060102T150405Z"
+)
+
+// Status is the STATUS of an event.
+type Status string
+
+const (
+	StatusTentative Status = "TENTATIVE"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Event describes a VEVENT. UID must be globally unique and stable, so calendar apps can update it when it changes.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Status      Status
+}
+
+// Calendar describes a VCALENDAR with its events.
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Encode writes the calendar as an RFC 5545 document. Times are written in UTC, so they are the same in any time
+// zone, and now is used as the stamp of every event.
+func (c Calendar) Encode(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//final-esp-backend-3//clinic//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + Escape(c.Name),
+	}
+
+	for _, e := range c.Events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+Escape(e.UID),
+			"DTSTAMP:"+now.UTC().Format(dateTime),
+			"DTSTART:"+e.Start.UTC().Format(dateTime),
+			"DTEND:"+e.End.UTC().Format(dateTime),
+			"SUMMARY:"+Escape(e.Summary),
+			"DESCRIPTION:"+Escape(e.Description),
+		)
+
+		if e.Status != "" {
+			lines = append(lines, "STATUS:"+string(e.Status))
+		}
+
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		_, err := bw.WriteString(Fold(line))
+		if err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Escape escapes a TEXT value: backslashes, semicolons and commas are escaped with a backslash, and line breaks are
+// written as \n.
+func Escape(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+
+	return replacer.Replace(s)
+}
+
+// Fold splits a content line in lines of at most 75 octets, continued by a line break and a space, without breaking
+// any UTF-8 character. The returned line ends with a line break.
+func Fold(line string) string {
+	var b strings.Builder
+
+	limit := maxLineLength
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+
+		line = line[cut:]
+
+		// The leading space of continuation lines takes one octet.
+		limit = maxLineLength - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package listing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidSort  = errors.New("invalid sort field")
+	ErrInvalidField = errors.New("invalid field")
+)
+
+// Query describes the sorting and the sparse fieldset params of a list endpoint, both as comma separated fields,
+// like "sort=-date,id" and "fields=id,first_name".
+type Query struct {
+	Sort   string `form:"sort"`
+	Fields string `form:"fields"`
+}
+
+// Options describes how rows are listed. Sort are the fields they're sorted by, ascending, or descending when prefixed
+// by "-", and Fields the only ones returned. The zero value lists all the fields in the default order.
+type Options struct {
+	Sort   []string
+	Fields []string
+}
+
+// Default is the Options of all the fields in the default order.
+var Default = Options{}
+
+// Options parses the query to Options, rows can only be sorted by the sortable fields and only the selectable ones can
+// be returned.
+func (q Query) Options(sortable []string, selectable []string) (Options, error) {
+	sort := split(q.Sort)
+	for _, field := range sort {
+		if !contains(sortable, Field(field)) {
+			return Options{}, fmt.Errorf("%w: %s", ErrInvalidSort, Field(field))
+		}
+	}
+
+	fields := split(q.Fields)
+	for _, field := range fields {
+		if !contains(selectable, field) {
+			return Options{}, fmt.Errorf("%w: %s", ErrInvalidField, field)
+		}
+	}
+
+	return Options{Sort: sort, Fields: fields}, nil
+}
+
+// Order returns the fields to sort by, the defaults when none was requested, always ending with the unique field, so
+// the order is the same every time.
+func (o Options) Order(unique string, defaults ...string) []string {
+	order := o.Sort
+	if len(order) == 0 {
+		order = defaults
+	}
+
+	for _, field := range order {
+		if Field(field) == unique {
+			return order
+		}
+	}
+
+	return append(append(make([]string, 0, len(order)+1), order...), unique)
+}
+
+// Fetch returns the fields to read for the given order: the ones requested, the ones sorted by and the required ones.
+// It's empty when all the fields are requested.
+func (o Options) Fetch(order []string, required ...string) []string {
+	if len(o.Fields) == 0 {
+		return nil
+	}
+
+	fetch := make([]string, 0, len(o.Fields)+len(order)+len(required))
+
+	for _, field := range append(append(append([]string{}, required...), o.Fields...), order...) {
+		if !contains(fetch, Field(field)) {
+			fetch = append(fetch, Field(field))
+		}
+	}
+
+	return fetch
+}
+
+// Field returns the name of a sort field, without the "-" prefix.
+func Field(sort string) string {
+	return strings.TrimPrefix(sort, "-")
+}
+
+// Select returns the items with only the given fields, as they're named in JSON, or the items as they are when
+// fields is empty.
+func Select[T any](items []T, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]json.RawMessage, 0, len(items))
+
+	err = json.Unmarshal(b, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for key := range row {
+			if !contains(fields, key) {
+				delete(row, key)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// split returns the non-empty comma separated values of s.
+func split(s string) []string {
+	values := make([]string, 0)
+
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// contains reports whether value is in values.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
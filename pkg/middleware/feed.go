@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+)
+
+// FeedToken returns the token that grants read access to the calendar feed of a resource, like "dentist/1".
+func FeedToken(secret string, resource string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(resource))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AuthenticateFeed manages the security of calendar feeds by validating the token, given by the TOKEN header or by
+// the token query param, which must be the FeedToken of the kind and the id path param, so calendar apps can fetch
+// feeds without headers. Query tokens are never valid when the secret is empty.
+func AuthenticateFeed(secret string, kind string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenHeader := ctx.GetHeader("TOKEN")
+		if tokenHeader != "" {
+			if tokenHeader != os.Getenv("TOKEN") {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized,
+					gin.H{"message": "Invalid token"})
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		tokenQuery := ctx.Query("token")
+		if tokenQuery == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized,
+				gin.H{"message": "Token not found"})
+			return
+		}
+
+		expected := FeedToken(secret, kind+"/"+ctx.Param("id"))
+		if secret == "" || !hmac.Equal([]byte(tokenQuery), []byte(expected)) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized,
+				gin.H{"message": "Invalid token"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
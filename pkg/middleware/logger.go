@@ -10,7 +10,7 @@ const (
 	basePath = "http://localhost:8080"
 )
 
-// Logger manages the security by validating the token
+// Logger prints the time, path, method and size of every request, and the errors the handlers added to its context
 func Logger() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		path := ctx.Request.URL
@@ -29,5 +29,9 @@ func Logger() gin.HandlerFunc {
 			"METHOD: %s\n"+
 			"SIZE: %d\n",
 			localTime, basePath, path, method, size)
+
+		if len(ctx.Errors) > 0 {
+			fmt.Printf("ERRORS:\n%s", ctx.Errors.String())
+		}
 	}
 }
@@ -1,9 +1,13 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"github.com/go-sql-driver/mysql"
+	"net"
 )
 
 // uniqueViolation is the error number returned by MySQL for unique violation.
@@ -21,6 +25,9 @@ const constraintViolationDelete = 1451
 // constraintViolationInsertAndUpdate is the error number returned by MySQL for constraint violation while inserting or updating rows.
 const constraintViolationInsertAndUpdate = 1452
 
+// deadlock is the error number returned by MySQL when it rolls back a transaction to break a deadlock.
+const deadlock = 1213
+
 // ErrDBDuplicateEntry is the error returned when the database has a duplicated entry
 var ErrDBDuplicateEntry = errors.New("duplicate entry")
 
@@ -33,12 +40,34 @@ var ErrDBConflict = errors.New("conflict between constraints")
 // ErrDBValueExceeded is the error returned when the database finds a constraint conflict.
 var ErrDBValueExceeded = errors.New("attribute value exceeded")
 
+// ErrDBTimeout is the error returned when the database doesn't answer before the deadline of the context, it wraps
+// context.DeadlineExceeded, so it's told apart from other errors the same way wherever it comes from.
+var ErrDBTimeout = fmt.Errorf("database timeout: %w", context.DeadlineExceeded)
+
+// ErrDBDeadlock is the error returned when the database rolls back the transaction to break a deadlock with another
+// one, running it again from the start usually succeeds.
+var ErrDBDeadlock = errors.New("deadlock found, the transaction was rolled back")
+
+// ErrDBUnavailable is the error returned when the database can't be reached or the connection to it is lost, it wraps
+// driver.ErrBadConn, so it's told apart from other errors the same way wherever it comes from. CheckError returns it
+// with the error of the driver, so the cause is still logged.
+var ErrDBUnavailable = fmt.Errorf("database unavailable: %w", driver.ErrBadConn)
+
 // CheckError checks if the passed error originates from MySQL, if it does, it parses it into a generic database error for the application.
 func CheckError(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return ErrDBNoRows
 	}
 
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrDBTimeout
+	}
+
+	var netErr *net.OpError
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %v", ErrDBUnavailable, err)
+	}
+
 	var mysqlerr *mysql.MySQLError
 	ok := errors.As(err, &mysqlerr)
 
@@ -50,6 +79,8 @@ func CheckError(err error) error {
 			return ErrDBConflict
 		case dataOutOfRange, dataTooLong:
 			return ErrDBValueExceeded
+		case deadlock:
+			return ErrDBDeadlock
 		}
 	}
 
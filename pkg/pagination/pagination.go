@@ -0,0 +1,127 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is the error returned when a cursor can't be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Query describes the pagination params of a list endpoint: a page number or an opaque cursor, returned by the
+// previous page, and the size of the page. The cursor wins when both are set.
+type Query struct {
+	Page     int    `form:"page"      validate:"omitempty,min=1"`
+	PageSize int    `form:"page_size" validate:"omitempty,min=1"`
+	Cursor   string `form:"cursor"`
+}
+
+// Limits describes the default and the maximum size of the pages.
+type Limits struct {
+	Default int
+	Max     int
+}
+
+// Request describes the page asked to a store. Pages are numbered from 1, unless After is set, then the page starts
+// right after the row with those keys (keyset pagination). The zero value asks for all the rows at once.
+type Request struct {
+	Page  int
+	Size  int
+	After []string
+}
+
+// All is the Request of all the rows at once.
+var All = Request{}
+
+// Request parses the query to a Request, with the default size when it's not set and never over the maximum.
+func (q Query) Request(limits Limits) (Request, error) {
+	r := Request{Page: q.Page, Size: q.PageSize}
+
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Size < 1 {
+		r.Size = limits.Default
+	}
+
+	if r.Size > limits.Max {
+		r.Size = limits.Max
+	}
+
+	if q.Cursor != "" {
+		after, err := DecodeCursor(q.Cursor)
+		if err != nil {
+			return Request{}, err
+		}
+
+		r.Page = 0
+		r.After = after
+	}
+
+	return r, nil
+}
+
+// Paginated reports whether the request asks for a single page instead of all the rows.
+func (r Request) Paginated() bool {
+	return r.Size > 0
+}
+
+// Offset returns how many rows are skipped before the page starts.
+func (r Request) Offset() int {
+	if r.Page < 1 {
+		return 0
+	}
+
+	return (r.Page - 1) * r.Size
+}
+
+// HasMore reports whether n rows, read with a limit of one more than the size, go past the page, so there are more
+// rows after it.
+func (r Request) HasMore(n int) bool {
+	return r.Paginated() && n > r.Size
+}
+
+// Meta describes the page returned. Page is only set for numbered pages and NextCursor is empty on the last page.
+type Meta struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewMeta creates the Meta of the page returned for the request, next are the keys of its last row when there are
+// more rows after it.
+func NewMeta(r Request, total int, next []string) Meta {
+	meta := Meta{Total: total, Page: r.Page, PageSize: r.Size}
+
+	if next != nil {
+		meta.NextCursor = EncodeCursor(next...)
+	}
+
+	return meta
+}
+
+// EncodeCursor returns an opaque cursor holding the keys of a row.
+func EncodeCursor(keys ...string) string {
+	b, _ := json.Marshal(keys)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor returns the keys held by a cursor made by EncodeCursor.
+func DecodeCursor(cursor string) ([]string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var keys []string
+
+	err = json.Unmarshal(b, &keys)
+	if err != nil || len(keys) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return keys, nil
+}
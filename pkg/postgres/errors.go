@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net"
+)
+
+// uniqueViolation is the SQLSTATE returned by PostgreSQL for unique violation.
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is the SQLSTATE returned by PostgreSQL for foreign key violations, while inserting, updating or
+// deleting rows.
+const foreignKeyViolation = "23503"
+
+// stringDataRightTruncation is the SQLSTATE returned by PostgreSQL due to data being too long.
+const stringDataRightTruncation = "22001"
+
+// numericValueOutOfRange is the SQLSTATE returned by PostgreSQL due to data being out of range.
+const numericValueOutOfRange = "22003"
+
+// queryCanceled is the SQLSTATE returned by PostgreSQL when a query is canceled, the driver does it when the context is
+// done.
+const queryCanceled = "57014"
+
+// serializationFailure and deadlockDetected are the SQLSTATEs returned by PostgreSQL when it rolls back a transaction
+// that conflicts with another one.
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// The errors of the database are the same ones of the MySQL package, so stores handle them the same way.
+var (
+	ErrDBDuplicateEntry = mysql.ErrDBDuplicateEntry
+	ErrDBNoRows         = mysql.ErrDBNoRows
+	ErrDBConflict       = mysql.ErrDBConflict
+	ErrDBValueExceeded  = mysql.ErrDBValueExceeded
+	ErrDBTimeout        = mysql.ErrDBTimeout
+	ErrDBDeadlock       = mysql.ErrDBDeadlock
+	ErrDBUnavailable    = mysql.ErrDBUnavailable
+)
+
+// CheckError checks if the passed error originates from PostgreSQL, if it does, it parses it into a generic database error for the application.
+func CheckError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDBNoRows
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrDBTimeout
+	}
+
+	var netErr *net.OpError
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %v", ErrDBUnavailable, err)
+	}
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return ErrDBDuplicateEntry
+		case foreignKeyViolation:
+			return ErrDBConflict
+		case stringDataRightTruncation, numericValueOutOfRange:
+			return ErrDBValueExceeded
+		case queryCanceled:
+			return ErrDBTimeout
+		case serializationFailure, deadlockDetected:
+			return ErrDBDeadlock
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rebind replaces the "?" placeholders of a query, the ones written by the query builder, with the numbered ones
+// PostgreSQL uses, "$1", "$2" and so on. Question marks inside quoted strings are kept.
+func Rebind(query string) string {
+	var b strings.Builder
+
+	b.Grow(len(query) + 8)
+
+	n := 0
+	quoted := false
+
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
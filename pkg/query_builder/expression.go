@@ -1,49 +1,48 @@
 package query_builder
 
 import (
-	"fmt"
-	"html/template"
-	"strconv"
+	"reflect"
 	"strings"
 )
 
-// Expression represents a portion of a SQL query, example: "id >= 1".
+// Expression represents a portion of a SQL query, example: "id >= ?" with 1 as its argument.
+// Key and Exp are written as they are in the query, so they must never come from user input, Value is always sent
+// as a placeholder argument.
 type Expression struct {
 	Key   string
 	Exp   string
 	Value interface{}
 }
 
-// ToString converts an Expression.Value to a string and formats it for query construction.
-// It ensures proper escaping and returns a string representation of the expression.
-func (e Expression) ToString() string {
-
-	switch e.Value.(type) {
-
-	case int, int16, int32, int64:
-		val := strconv.Itoa(e.Value.(int))
-		clause := e.Key + e.Exp + e.getReplaceExp()
-		return fmt.Sprintf(clause, val)
-
-	default:
-		if strings.TrimSpace(e.Value.(string)) == "" {
-			return ""
-		} else {
-			e.Value = template.HTMLEscapeString(e.Value.(string))
-			clause := e.Key + e.Exp + e.getReplaceExp()
-			val := fmt.Sprintf(clause, e.Value)
-			return val
-		}
+// ToSql returns the clause of the expression, with a placeholder for its value, and its arguments.
+// Expressions without value (nil, a nil pointer or a blank string) are not set, so their clause is empty.
+func (e Expression) ToSql() (string, []any) {
+	value, ok := argument(e.Value)
+	if !ok {
+		return "", nil
 	}
+
+	return e.Key + " " + e.Exp + " ?", []any{value}
 }
 
-// getReplaceExp parses type to SQL query format, when type is int we need to be without quotes,
-// and all the others types goes with quotes.
-func (e Expression) getReplaceExp() string {
-	switch e.Value.(type) {
-	case int, int64, int32, int16:
-		return "%s"
-	default:
-		return "'%s'"
+// argument returns the value to send as a placeholder argument, dereferencing pointers, and reports whether it's set.
+func argument(value interface{}) (any, bool) {
+	if value == nil {
+		return nil, false
 	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+
+		value = v.Elem().Interface()
+	}
+
+	if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+		return nil, false
+	}
+
+	return value, true
 }
@@ -0,0 +1,161 @@
+package query_builder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// likeEscaper escapes the wildcards of LIKE with "!", so they are matched literally. "!" is used instead of a
+// backslash since it needs no escaping in the string literals of any database.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// In creates a "key IN (?, ...)" condition with a placeholder for every value that is set.
+// It's empty when no value is set, like any other expression without value.
+func (dqb DynamicQueryBuilder) In(key string, values ...interface{}) DynamicQueryBuilder {
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		if value, ok := argument(v); ok {
+			args = append(args, value)
+		}
+	}
+
+	if len(args) == 0 {
+		return DynamicQueryBuilder{}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+
+	return DynamicQueryBuilder{clause: key + " IN (" + placeholders + ")", args: args}
+}
+
+// List returns the values of a comma separated list, like the ones of the filter maps, to be used with In.
+func List(s string) []interface{} {
+	values := make([]interface{}, 0)
+	for _, v := range strings.Split(s, ",") {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// Like creates a "key LIKE ?" condition, the pattern is sent as it is, wildcards included.
+func (dqb DynamicQueryBuilder) Like(key string, pattern string) DynamicQueryBuilder {
+	return fromExpression(Expression{Key: key, Exp: "LIKE", Value: pattern})
+}
+
+// Prefix creates a condition matching the values of key starting with prefix, its wildcards are matched literally.
+func (dqb DynamicQueryBuilder) Prefix(key string, prefix string) DynamicQueryBuilder {
+	if strings.TrimSpace(prefix) == "" {
+		return DynamicQueryBuilder{}
+	}
+
+	return DynamicQueryBuilder{clause: key + " LIKE ? ESCAPE '!'", args: []any{likeEscaper.Replace(prefix) + "%"}}
+}
+
+// Contains creates a condition matching the values of key containing s, its wildcards are matched literally.
+func (dqb DynamicQueryBuilder) Contains(key string, s string) DynamicQueryBuilder {
+	if strings.TrimSpace(s) == "" {
+		return DynamicQueryBuilder{}
+	}
+
+	return DynamicQueryBuilder{clause: key + " LIKE ? ESCAPE '!'", args: []any{"%" + likeEscaper.Replace(s) + "%"}}
+}
+
+// Between creates a "key BETWEEN ? AND ?" condition, both ends included.
+// When only one end is set, it's a ">=" or "<=" condition, and it's empty when none is.
+func (dqb DynamicQueryBuilder) Between(key string, from interface{}, to interface{}) DynamicQueryBuilder {
+	fromValue, fromOk := argument(from)
+	toValue, toOk := argument(to)
+
+	switch {
+	case fromOk && toOk:
+		return DynamicQueryBuilder{clause: key + " BETWEEN ? AND ?", args: []any{fromValue, toValue}}
+	case fromOk:
+		return fromExpression(Expression{Key: key, Exp: ">=", Value: fromValue})
+	case toOk:
+		return fromExpression(Expression{Key: key, Exp: "<=", Value: toValue})
+	default:
+		return DynamicQueryBuilder{}
+	}
+}
+
+// After creates the keyset condition matching the rows after the one with the given values, when they're sorted by
+// fields like in OrderBy, like "(a > ? OR (a = ? AND b < ?))" for "a,-b". Fields are mapped to their columns the same
+// way too, it's empty when the number of values doesn't match the fields.
+func (dqb DynamicQueryBuilder) After(columns map[string]string, fields []string, values ...interface{}) (DynamicQueryBuilder, error) {
+	if len(fields) == 0 || len(fields) != len(values) {
+		return DynamicQueryBuilder{}, nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	comparisons := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		comparison := ">"
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			comparison = "<"
+		}
+
+		column, ok := columns[field]
+		if !ok {
+			return DynamicQueryBuilder{}, fmt.Errorf("%w: %s", ErrInvalidOrder, field)
+		}
+
+		keys = append(keys, column)
+		comparisons = append(comparisons, comparison)
+	}
+
+	// Values are bound as they are, even blank strings, since the last row can have them.
+	branches := make([]interface{}, 0, len(keys))
+
+	for i := range keys {
+		conditions := make([]interface{}, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, DynamicQueryBuilder{clause: keys[j] + " = ?", args: []any{values[j]}})
+		}
+
+		conditions = append(conditions, DynamicQueryBuilder{clause: keys[i] + " " + comparisons[i] + " ?", args: []any{values[i]}})
+		branches = append(branches, dqb.And(conditions...))
+	}
+
+	return dqb.Or(branches...), nil
+}
+
+// Exists creates an "EXISTS (subquery)" condition, adding the component to the WHERE of the subquery, like
+// "SELECT 1 FROM b WHERE b.a_id = a.id". It's empty when the component is, so the subquery must never come from user
+// input and must already have a WHERE.
+func (dqb DynamicQueryBuilder) Exists(subquery string, component interface{}) DynamicQueryBuilder {
+	clause, args := componentToSql(component)
+	if clause == "" {
+		return DynamicQueryBuilder{}
+	}
+
+	return DynamicQueryBuilder{clause: "EXISTS (" + subquery + " AND " + clause + ")", args: args}
+}
+
+// IsNull creates a "key IS NULL" condition.
+func (dqb DynamicQueryBuilder) IsNull(key string) DynamicQueryBuilder {
+	return DynamicQueryBuilder{clause: key + " IS NULL"}
+}
+
+// IsNotNull creates a "key IS NOT NULL" condition.
+func (dqb DynamicQueryBuilder) IsNotNull(key string) DynamicQueryBuilder {
+	return DynamicQueryBuilder{clause: key + " IS NOT NULL"}
+}
+
+// Not negates a component, it's empty when the component is.
+func (dqb DynamicQueryBuilder) Not(component interface{}) DynamicQueryBuilder {
+	clause, args := componentToSql(component)
+	if clause == "" {
+		return DynamicQueryBuilder{}
+	}
+
+	return DynamicQueryBuilder{clause: "NOT (" + clause + ")", args: args}
+}
+
+// fromExpression wraps an Expression as a DynamicQueryBuilder.
+func fromExpression(e Expression) DynamicQueryBuilder {
+	clause, args := e.ToSql()
+	return DynamicQueryBuilder{clause: clause, args: args}
+}
@@ -1,12 +1,23 @@
 package query_builder
 
 import (
-	"strconv"
+	"errors"
+	"fmt"
 	"strings"
 )
 
-// DynamicQueryBuilder is the core of the package, it wraps a native string type.
-type DynamicQueryBuilder string
+// ErrInvalidOrder is the error returned when a query is ordered by a field that is not allowed.
+var ErrInvalidOrder = errors.New("invalid order field")
+
+// DynamicQueryBuilder is the core of the package, it holds a SQL condition with placeholders and its arguments in
+// the same order. Its zero value is an empty condition, ready to build expressions with.
+type DynamicQueryBuilder struct {
+	clause    string
+	args      []any
+	order     string
+	limit     string
+	limitArgs []any
+}
 
 // NewExpression creates a new SQL Expression.
 func (dqb DynamicQueryBuilder) NewExpression(key string, assignment string, value interface{}) Expression {
@@ -24,73 +35,111 @@ func (dqb DynamicQueryBuilder) Or(component ...interface{}) DynamicQueryBuilder
 }
 
 // getOperationExpression generates a DynamicQueryBuilder by combining multiple expressions with the specified SQL operator.
-// Example of SQL operators: "AND", "OR".
+// Example of SQL operators: "AND", "OR". Empty expressions are skipped, and groups made by And or Or can be nested.
 func (dqb DynamicQueryBuilder) getOperationExpression(operation string, component ...interface{}) DynamicQueryBuilder {
-	if len(component) == 0 {
-		return ""
+	clauses := make([]string, 0, len(component))
+	args := make([]any, 0)
+
+	for _, c := range component {
+		clause, cArgs := componentToSql(c)
+		if clause != "" {
+			clauses = append(clauses, clause)
+			args = append(args, cArgs...)
+		}
+	}
+
+	switch len(clauses) {
+	case 0:
+		return DynamicQueryBuilder{}
+	case 1:
+		return DynamicQueryBuilder{clause: clauses[0], args: args}
+	default:
+		return DynamicQueryBuilder{clause: "(" + strings.Join(clauses, " "+operation+" ") + ")", args: args}
 	}
-	if len(component) == 1 {
-		return componentToString(component[0])
-	} else {
-		clauses := make([]string, 0)
-		for _, v := range component {
-			value := componentToString(v)
-			if value != "" {
-				clauses = append(clauses, ""+string(value)+"")
-			}
+}
+
+// OrderBy sets "ORDER BY" for the query. Fields are sorted ascending, or descending when prefixed by "-", like
+// "-date". Only the fields in columns can be used, it maps every field to the column written in the query, so they
+// never come from user input.
+func (dqb DynamicQueryBuilder) OrderBy(columns map[string]string, fields ...string) (DynamicQueryBuilder, error) {
+	orders := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		direction := " ASC"
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			direction = " DESC"
 		}
 
-		if len(clauses) > 0 {
-			return DynamicQueryBuilder("( " + strings.Join(clauses, " "+operation+" ") + ")")
+		column, ok := columns[field]
+		if !ok {
+			return DynamicQueryBuilder{}, fmt.Errorf("%w: %s", ErrInvalidOrder, field)
 		}
 
-		return ""
+		orders = append(orders, column+direction)
 	}
-}
 
-// Limit sets "LIMIT" and "OFFSET" for the query.
-func (dqb DynamicQueryBuilder) Limit(offset int, length int) DynamicQueryBuilder {
-	query := string(dqb)
-	query += " LIMIT " + strconv.Itoa(length) + " OFFSET " + strconv.Itoa(offset)
-	return DynamicQueryBuilder(query)
+	dqb.order = ""
+	if len(orders) > 0 {
+		dqb.order = " ORDER BY " + strings.Join(orders, ", ")
+	}
+
+	return dqb, nil
 }
 
-// CopyQuery parses a string query into the DynamicQueryBuilder type and stores the result in the provided destination string.
-// It returns the DynamicQueryBuilder for method chaining if required.
-func (dqb DynamicQueryBuilder) CopyQuery(dest *string) DynamicQueryBuilder {
-	*dest = dqb.ToString()
+// Limit sets "LIMIT" and "OFFSET" for the query, both as placeholder arguments after the ones of the condition.
+func (dqb DynamicQueryBuilder) Limit(offset int, length int) DynamicQueryBuilder {
+	dqb.limit = " LIMIT ? OFFSET ?"
+	dqb.limitArgs = []any{length, offset}
 	return dqb
 }
 
-// BindSql generates and returns the final SQL query by combining the DynamicQueryBuilder with an existing SQL string.
-// Example of existing SQL string: "SELECT * FROM table".
-func (dqb DynamicQueryBuilder) BindSql(sql string) string {
-	if dqb != "" && dqb != "( )" {
-		index := strings.Index(dqb.ToString(), "LIMIT")
-		if index == 1 {
-			return sql + dqb.ToString()
-		}
+// BindSql generates and returns the final SQL query by combining the DynamicQueryBuilder with an existing SQL string,
+// and the arguments for its placeholders in order. Example of existing SQL string: "SELECT * FROM table".
+func (dqb DynamicQueryBuilder) BindSql(sql string) (string, []any) {
+	args := make([]any, 0, len(dqb.args)+len(dqb.limitArgs))
+
+	if dqb.clause != "" {
+		sql += " WHERE " + dqb.clause
+		args = append(args, dqb.args...)
+	}
 
-		return sql + " WHERE " + string(dqb)
+	sql += dqb.order + dqb.limit
+	args = append(args, dqb.limitArgs...)
+
+	return sql, args
+}
+
+// BindCondition is like BindSql but without the order and the limit, for queries like "SELECT COUNT(*) FROM table".
+func (dqb DynamicQueryBuilder) BindCondition(sql string) (string, []any) {
+	if dqb.clause == "" {
+		return sql, []any{}
 	}
-	return sql
+
+	return sql + " WHERE " + dqb.clause, dqb.args
 }
 
-// ToString converts the DynamicQueryBuilder to a string representation of the query.
+// ToString converts the condition of the DynamicQueryBuilder to its SQL representation, with placeholders.
 func (dqb DynamicQueryBuilder) ToString() string {
-	return string(dqb)
+	return dqb.clause
+}
+
+// Args returns the arguments of the placeholders of the condition, in order.
+func (dqb DynamicQueryBuilder) Args() []any {
+	return dqb.args
 }
 
-// componentToString converts various types of components into a DynamicQueryBuilder for query construction.
-func componentToString(c interface{}) DynamicQueryBuilder {
+// componentToSql converts various types of components into a SQL clause and its arguments.
+// Strings are taken as raw SQL without arguments, so they must never come from user input.
+func componentToSql(c interface{}) (string, []any) {
 	switch v := c.(type) {
 	case Expression:
-		return DynamicQueryBuilder(c.(Expression).ToString())
-	case string, *string:
-		return DynamicQueryBuilder(c.(string))
+		return v.ToSql()
 	case DynamicQueryBuilder:
-		return v
+		return v.clause, v.args
+	case string:
+		return v, nil
 	default:
-		return ""
+		return "", nil
 	}
 }
@@ -0,0 +1,258 @@
+package query_builder_test
+
+import (
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/pkg/query_builder"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// injection is a value trying to break out of its placeholder, it must only ever be sent as an argument.
+const injection = "O'Brien'; DROP TABLE patient; --"
+
+var columns = map[string]string{
+	"id":   "a.id",
+	"date": "a.date",
+}
+
+func TestOperators(t *testing.T) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	date := time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC)
+	name := injection
+	var unset *string
+
+	keyset, err := dqb.After(columns, []string{"date", "-id"}, date, injection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		condition query_builder.DynamicQueryBuilder
+		clause    string
+		args      []any
+	}{
+		{
+			name:      "expression",
+			condition: dqb.And(dqb.NewExpression("first_name", "=", injection)),
+			clause:    "first_name = ?",
+			args:      []any{injection},
+		},
+		{
+			name:      "expression with a pointer",
+			condition: dqb.And(dqb.NewExpression("first_name", "=", &name)),
+			clause:    "first_name = ?",
+			args:      []any{injection},
+		},
+		{
+			name: "expressions without value",
+			condition: dqb.And(
+				dqb.NewExpression("first_name", "=", nil),
+				dqb.NewExpression("last_name", "=", unset),
+				dqb.NewExpression("address", "=", "  "),
+			),
+		},
+		{
+			name:      "in",
+			condition: dqb.In("status", injection, nil, " ", "50%_off"),
+			clause:    "status IN (?, ?)",
+			args:      []any{injection, "50%_off"},
+		},
+		{
+			name:      "in without values",
+			condition: dqb.In("status", nil, ""),
+		},
+		{
+			name:      "in a list",
+			condition: dqb.In("id", query_builder.List("1,2,3")...),
+			clause:    "id IN (?, ?, ?)",
+			args:      []any{"1", "2", "3"},
+		},
+		{
+			name:      "in an empty list",
+			condition: dqb.In("id", query_builder.List("")...),
+		},
+		{
+			name:      "like keeps the wildcards",
+			condition: dqb.Like("first_name", "%"+injection+"_"),
+			clause:    "first_name LIKE ?",
+			args:      []any{"%" + injection + "_"},
+		},
+		{
+			name:      "prefix escapes the wildcards",
+			condition: dqb.Prefix("dni", "12%_!"),
+			clause:    "dni LIKE ? ESCAPE '!'",
+			args:      []any{"12!%!_!!%"},
+		},
+		{
+			name:      "contains",
+			condition: dqb.Contains("address", injection),
+			clause:    "address LIKE ? ESCAPE '!'",
+			args:      []any{"%" + injection + "%"},
+		},
+		{
+			name:      "contains escapes the wildcards",
+			condition: dqb.Contains("address", "50%_off"),
+			clause:    "address LIKE ? ESCAPE '!'",
+			args:      []any{"%50!%!_off%"},
+		},
+		{
+			name:      "contains without value",
+			condition: dqb.Contains("address", " "),
+		},
+		{
+			name:      "between",
+			condition: dqb.Between("a.date", injection, "2024-03-31"),
+			clause:    "a.date BETWEEN ? AND ?",
+			args:      []any{injection, "2024-03-31"},
+		},
+		{
+			name:      "between from",
+			condition: dqb.Between("a.date", injection, nil),
+			clause:    "a.date >= ?",
+			args:      []any{injection},
+		},
+		{
+			name:      "between to",
+			condition: dqb.Between("a.date", "", injection),
+			clause:    "a.date <= ?",
+			args:      []any{injection},
+		},
+		{
+			name:      "between without ends",
+			condition: dqb.Between("a.date", nil, ""),
+		},
+		{
+			name:      "after",
+			condition: keyset,
+			clause:    "(a.date > ? OR (a.date = ? AND a.id < ?))",
+			args:      []any{date, date, injection},
+		},
+		{
+			name: "nested groups keep the order of the arguments",
+			condition: dqb.And(
+				dqb.NewExpression("active", "=", true),
+				dqb.Or(
+					dqb.Contains("first_name", injection),
+					dqb.Contains("last_name", injection),
+				),
+				dqb.Between("discharge_date", "2024-01-01", "2024-12-31"),
+			),
+			clause: "(active = ? AND (first_name LIKE ? ESCAPE '!' OR last_name LIKE ? ESCAPE '!') " +
+				"AND discharge_date BETWEEN ? AND ?)",
+			args: []any{true, "%" + injection + "%", "%" + injection + "%", "2024-01-01", "2024-12-31"},
+		},
+		{
+			name: "exists",
+			condition: dqb.Exists("SELECT 1 FROM appointment a WHERE a.patient_id = patient.id",
+				dqb.Between("a.date", injection, nil)),
+			clause: "EXISTS (SELECT 1 FROM appointment a WHERE a.patient_id = patient.id AND a.date >= ?)",
+			args:   []any{injection},
+		},
+		{
+			name:      "not",
+			condition: dqb.Not(dqb.In("status", injection)),
+			clause:    "NOT (status IN (?))",
+			args:      []any{injection},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause := tt.condition.ToString()
+			if clause != tt.clause {
+				t.Errorf("clause is %q, want %q", clause, tt.clause)
+			}
+
+			if strings.Contains(clause, "DROP") || strings.Contains(clause, "50%") {
+				t.Errorf("clause %q has a value written in it", clause)
+			}
+
+			if len(tt.args) == 0 && len(tt.condition.Args()) == 0 {
+				return
+			}
+
+			if !reflect.DeepEqual(tt.condition.Args(), tt.args) {
+				t.Errorf("args are %#v, want %#v", tt.condition.Args(), tt.args)
+			}
+		})
+	}
+}
+
+func TestAfterInvalid(t *testing.T) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	_, err := dqb.After(columns, []string{"date; DROP TABLE patient"}, injection)
+	if !errors.Is(err, query_builder.ErrInvalidOrder) {
+		t.Errorf("after an unknown field got %v, want %v", err, query_builder.ErrInvalidOrder)
+	}
+
+	keyset, err := dqb.After(columns, []string{"date", "id"}, injection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keyset.ToString() != "" {
+		t.Errorf("after with fewer values than fields is %q, want it empty", keyset.ToString())
+	}
+}
+
+func TestOrderByInvalid(t *testing.T) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	for _, field := range []string{"id; DROP TABLE patient", "-" + injection, "a.id"} {
+		_, err := dqb.OrderBy(columns, field)
+		if !errors.Is(err, query_builder.ErrInvalidOrder) {
+			t.Errorf("ordering by %q got %v, want %v", field, err, query_builder.ErrInvalidOrder)
+		}
+	}
+}
+
+func TestBindSql(t *testing.T) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	dqb, err := dqb.And(
+		dqb.Contains("first_name", injection),
+		dqb.In("a.id", 1, 2),
+	).OrderBy(columns, "-date", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query, args := dqb.Limit(20, 11).BindSql("SELECT a.id FROM appointment a")
+
+	want := "SELECT a.id FROM appointment a WHERE (first_name LIKE ? ESCAPE '!' AND a.id IN (?, ?)) " +
+		"ORDER BY a.date DESC, a.id ASC LIMIT ? OFFSET ?"
+	if query != want {
+		t.Errorf("query is %q, want %q", query, want)
+	}
+
+	wantArgs := []any{"%" + injection + "%", 1, 2, 11, 20}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args are %#v, want %#v", args, wantArgs)
+	}
+
+	query, args = dqb.BindCondition("SELECT COUNT(*) FROM appointment a")
+
+	want = "SELECT COUNT(*) FROM appointment a WHERE (first_name LIKE ? ESCAPE '!' AND a.id IN (?, ?))"
+	if query != want {
+		t.Errorf("count query is %q, want %q", query, want)
+	}
+
+	wantArgs = []any{"%" + injection + "%", 1, 2}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("count args are %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestBindSqlEmpty(t *testing.T) {
+	var dqb query_builder.DynamicQueryBuilder
+
+	query, args := dqb.And(dqb.Contains("first_name", ""), dqb.In("id")).BindSql("SELECT id FROM patient")
+	if query != "SELECT id FROM patient" || len(args) != 0 {
+		t.Errorf("empty condition got %q %#v, want the query as it is without args", query, args)
+	}
+}
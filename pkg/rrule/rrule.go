@@ -0,0 +1,244 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of a recurrence rule.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the periods walked while expanding a rule, so rules that rarely match can't loop forever.
+const maxPeriods = 10000
+
+// untilLayouts are the accepted formats for the UNTIL part, as UTC date-time, local date-time or date.
+var untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+
+// weekdays maps the two letters codes of BYDAY to weekdays.
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ErrInvalidRule is the error returned when a recurrence rule can't be parsed.
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Rule is a recurrence rule, following the subset of RFC 5545 RRULE made by FREQ, INTERVAL, COUNT, UNTIL and BYDAY.
+// BYDAY only takes plain weekdays (no ordinals like 1MO) and is only supported with DAILY and WEEKLY frequencies.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// Parse parses a recurrence rule like "FREQ=WEEKLY;INTERVAL=4;COUNT=13", the "RRULE:" prefix is optional.
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("%w: it's empty", ErrInvalidRule)
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return Rule{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+
+		var err error
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly && rule.Freq != Yearly {
+				return Rule{}, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil || rule.Interval < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRule)
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err != nil || rule.Count < 1 {
+				return Rule{}, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRule)
+			}
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+			if err != nil {
+				return Rule{}, err
+			}
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported part %q", ErrInvalidRule, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, fmt.Errorf("%w: COUNT and UNTIL can't be used together", ErrInvalidRule)
+	}
+
+	if len(rule.ByDay) > 0 && rule.Freq != Daily && rule.Freq != Weekly {
+		return Rule{}, fmt.Errorf("%w: BYDAY is only supported with DAILY and WEEKLY", ErrInvalidRule)
+	}
+
+	return rule, nil
+}
+
+// Occurrences returns the dates of the recurrence starting at start. Like DTSTART in RFC 5545, start is always the
+// first one, even when it doesn't match the rule, and COUNT counts it. None is returned when UNTIL is before start.
+// No more than limit dates are returned, so rules without COUNT nor UNTIL can be expanded too.
+func (r Rule) Occurrences(start time.Time, limit int) []time.Time {
+	dates := make([]time.Time, 0)
+
+	if limit < 1 || (!r.Until.IsZero() && start.After(r.Until)) {
+		return dates
+	}
+
+	dates = append(dates, start)
+
+	if len(dates) == limit || len(dates) == r.Count {
+		return dates
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, date := range r.period(start, period) {
+			if !date.After(start) {
+				continue
+			}
+
+			if !r.Until.IsZero() && date.After(r.Until) {
+				return dates
+			}
+
+			dates = append(dates, date)
+
+			if len(dates) == limit || len(dates) == r.Count {
+				return dates
+			}
+		}
+	}
+
+	return dates
+}
+
+// period returns the candidate dates of the nth period of the rule, in chronological order.
+// Dates that don't exist, like February 30, are skipped as RFC 5545 says.
+func (r Rule) period(start time.Time, n int) []time.Time {
+	step := n * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		date := start.AddDate(0, 0, step)
+		if len(r.ByDay) > 0 && !r.on(date.Weekday()) {
+			return nil
+		}
+
+		return []time.Time{date}
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*step)}
+		}
+
+		// Weeks start on Monday, the default WKST.
+		monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+7*step)
+
+		dates := make([]time.Time, 0, len(r.ByDay))
+		for i := 0; i < 7; i++ {
+			date := monday.AddDate(0, 0, i)
+			if r.on(date.Weekday()) {
+				dates = append(dates, date)
+			}
+		}
+
+		return dates
+	case Monthly:
+		return exists(start, start.Year(), start.Month()+time.Month(step), start.Day())
+	case Yearly:
+		return exists(start, start.Year()+step, start.Month(), start.Day())
+	default:
+		return nil
+	}
+}
+
+// on reports whether the weekday is one of the BYDAY weekdays of the rule.
+func (r Rule) on(weekday time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exists returns the date with the given year, month and day, and the clock of start, only if that date exists.
+func exists(start time.Time, year int, month time.Month, day int) []time.Time {
+	date := time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+
+	// time.Date normalizes overflowing days into the next month.
+	if date.Day() != day {
+		return nil
+	}
+
+	return []time.Time{date}
+}
+
+// parseUntil parses the UNTIL part of a rule.
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range untilLayouts {
+		until, err := time.Parse(layout, value)
+		if err == nil {
+			// A date alone includes the whole day.
+			if layout == "20060102" {
+				until = until.Add(24*time.Hour - time.Second)
+			}
+
+			return until, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: UNTIL must be a date (YYYYMMDD) or a date-time (YYYYMMDDTHHmmssZ)", ErrInvalidRule)
+}
+
+// parseByDay parses the BYDAY part of a rule.
+func parseByDay(value string) ([]time.Weekday, error) {
+	days := make([]time.Weekday, 0)
+
+	for _, code := range strings.Split(value, ",") {
+		day, ok := weekdays[strings.ToUpper(code)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRule, code)
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
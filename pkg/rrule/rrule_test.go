@@ -0,0 +1,207 @@
+package rrule_test
+
+import (
+	"errors"
+	"github.com/Nachofra/final-esp-backend-3/pkg/rrule"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule string
+		want rrule.Rule
+	}{
+		{
+			rule: "FREQ=WEEKLY;INTERVAL=4;COUNT=13",
+			want: rrule.Rule{Freq: rrule.Weekly, Interval: 4, Count: 13},
+		},
+		{
+			rule: "RRULE:freq=daily;byday=mo,WE",
+			want: rrule.Rule{Freq: rrule.Daily, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Wednesday}},
+		},
+		{
+			rule: "FREQ=MONTHLY;UNTIL=20300131T120000Z",
+			want: rrule.Rule{Freq: rrule.Monthly, Interval: 1,
+				Until: time.Date(2030, time.January, 31, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			rule: "FREQ=YEARLY;UNTIL=20300131",
+			want: rrule.Rule{Freq: rrule.Yearly, Interval: 1,
+				Until: time.Date(2030, time.January, 31, 23, 59, 59, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := rrule.Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{name: "empty", rule: "  "},
+		{name: "only the prefix", rule: "RRULE:"},
+		{name: "malformed part", rule: "FREQ=DAILY;COUNT"},
+		{name: "empty value", rule: "FREQ=DAILY;COUNT="},
+		{name: "missing FREQ", rule: "COUNT=3"},
+		{name: "unsupported FREQ", rule: "FREQ=HOURLY"},
+		{name: "zero INTERVAL", rule: "FREQ=DAILY;INTERVAL=0"},
+		{name: "INTERVAL not a number", rule: "FREQ=DAILY;INTERVAL=two"},
+		{name: "negative COUNT", rule: "FREQ=DAILY;COUNT=-1"},
+		{name: "malformed UNTIL", rule: "FREQ=DAILY;UNTIL=2030-01-31"},
+		{name: "COUNT and UNTIL", rule: "FREQ=DAILY;COUNT=3;UNTIL=20300131"},
+		{name: "unsupported BYDAY", rule: "FREQ=WEEKLY;BYDAY=1MO"},
+		{name: "BYDAY with MONTHLY", rule: "FREQ=MONTHLY;BYDAY=MO"},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYMONTH=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rrule.Parse(tt.rule)
+			if !errors.Is(err, rrule.ErrInvalidRule) {
+				t.Errorf("Parse(%q) returned %v, want %v", tt.rule, err, rrule.ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	// start is a Monday.
+	start := time.Date(2030, time.January, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		limit int
+		want  []string
+	}{
+		{
+			name: "count",
+			rule: "FREQ=DAILY;COUNT=3",
+			want: []string{"2030-01-07", "2030-01-08", "2030-01-09"},
+		},
+		{
+			name: "interval",
+			rule: "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			want: []string{"2030-01-07", "2030-01-21", "2030-02-04"},
+		},
+		{
+			name: "until includes its date",
+			rule: "FREQ=WEEKLY;UNTIL=20300121",
+			want: []string{"2030-01-07", "2030-01-14", "2030-01-21"},
+		},
+		{
+			name: "until before the clock of the last day",
+			rule: "FREQ=WEEKLY;UNTIL=20300121T090000Z",
+			want: []string{"2030-01-07", "2030-01-14"},
+		},
+		{
+			name: "until before the start",
+			rule: "FREQ=WEEKLY;UNTIL=20300106",
+			want: []string{},
+		},
+		{
+			name: "weekly by day",
+			rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=5",
+			want: []string{"2030-01-07", "2030-01-09", "2030-01-11", "2030-01-14", "2030-01-16"},
+		},
+		{
+			name: "weekly by day every other week",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=5",
+			want: []string{"2030-01-07", "2030-01-08", "2030-01-10", "2030-01-22", "2030-01-24"},
+		},
+		{
+			name: "daily by day",
+			rule: "FREQ=DAILY;BYDAY=SA,SU;COUNT=3",
+			want: []string{"2030-01-07", "2030-01-12", "2030-01-13"},
+		},
+		{
+			name:  "start not matching by day counts as the first",
+			rule:  "FREQ=WEEKLY;BYDAY=MO;COUNT=3",
+			start: time.Date(2030, time.January, 9, 10, 0, 0, 0, time.UTC),
+			want:  []string{"2030-01-09", "2030-01-14", "2030-01-21"},
+		},
+		{
+			name:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY;COUNT=4",
+			start: time.Date(2030, time.January, 31, 10, 0, 0, 0, time.UTC),
+			want:  []string{"2030-01-31", "2030-03-31", "2030-05-31", "2030-07-31"},
+		},
+		{
+			name:  "monthly on the 30th skips February",
+			rule:  "FREQ=MONTHLY;UNTIL=20300430",
+			start: time.Date(2030, time.January, 30, 10, 0, 0, 0, time.UTC),
+			want:  []string{"2030-01-30", "2030-03-30", "2030-04-30"},
+		},
+		{
+			name:  "yearly skips years without February 29",
+			rule:  "FREQ=YEARLY;COUNT=3",
+			start: time.Date(2028, time.February, 29, 10, 0, 0, 0, time.UTC),
+			want:  []string{"2028-02-29", "2032-02-29", "2036-02-29"},
+		},
+		{
+			name:  "limit caps a rule without end",
+			rule:  "FREQ=DAILY",
+			limit: 4,
+			want:  []string{"2030-01-07", "2030-01-08", "2030-01-09", "2030-01-10"},
+		},
+		{
+			name:  "limit caps the count",
+			rule:  "FREQ=DAILY;COUNT=10",
+			limit: 2,
+			want:  []string{"2030-01-07", "2030-01-08"},
+		},
+		{
+			name:  "limit of the start only",
+			rule:  "FREQ=DAILY;COUNT=10",
+			limit: 1,
+			want:  []string{"2030-01-07"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := rrule.Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			from := tt.start
+			if from.IsZero() {
+				from = start
+			}
+
+			limit := tt.limit
+			if limit == 0 {
+				limit = 100
+			}
+
+			got := make([]string, 0)
+			for _, date := range rule.Occurrences(from, limit) {
+				if date.Hour() != 10 || date.Minute() != 0 {
+					t.Errorf("occurrence %v doesn't keep the clock of the start", date)
+				}
+
+				got = append(got, date.Format(time.DateOnly))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("occurrences of %q are %v, want %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
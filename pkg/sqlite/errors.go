@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/pkg/mysql"
+)
+
+// interrupt is the result code returned by SQLite when a query is interrupted, the driver does it when the context is
+// done.
+const interrupt = 9
+
+// cantOpen is the result code returned by SQLite when the database file can't be opened.
+const cantOpen = 14
+
+// tooBig is the result code returned by SQLite when a value is larger than the maximum allowed.
+const tooBig = 18
+
+// constraintCheck is the extended result code returned by SQLite when a check constraint fails, the schema checks the
+// length of the text columns with them, since SQLite doesn't enforce the length of VARCHAR columns.
+const constraintCheck = 275
+
+// constraintForeignKey is the extended result code returned by SQLite for foreign key violations.
+const constraintForeignKey = 787
+
+// constraintPrimaryKey is the extended result code returned by SQLite for primary key violations.
+const constraintPrimaryKey = 1555
+
+// constraintUnique is the extended result code returned by SQLite for unique violations.
+const constraintUnique = 2067
+
+// The errors of the database are the same ones of the MySQL package, so stores handle them the same way.
+var (
+	ErrDBDuplicateEntry = mysql.ErrDBDuplicateEntry
+	ErrDBNoRows         = mysql.ErrDBNoRows
+	ErrDBConflict       = mysql.ErrDBConflict
+	ErrDBValueExceeded  = mysql.ErrDBValueExceeded
+	ErrDBTimeout        = mysql.ErrDBTimeout
+	ErrDBDeadlock       = mysql.ErrDBDeadlock
+	ErrDBUnavailable    = mysql.ErrDBUnavailable
+)
+
+// coder is implemented by the errors of the SQLite driver, Code returns their extended result code.
+type coder interface {
+	Code() int
+}
+
+// CheckError checks if the passed error originates from SQLite, if it does, it parses it into a generic database error for the application.
+func CheckError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDBNoRows
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrDBTimeout
+	}
+
+	var sqliteErr coder
+	ok := errors.As(err, &sqliteErr)
+
+	if ok {
+		switch sqliteErr.Code() {
+		case constraintUnique, constraintPrimaryKey:
+			return ErrDBDuplicateEntry
+		case constraintForeignKey:
+			return ErrDBConflict
+		case constraintCheck, tooBig:
+			return ErrDBValueExceeded
+		case interrupt:
+			return ErrDBTimeout
+		case cantOpen:
+			return fmt.Errorf("%w: %v", ErrDBUnavailable, err)
+		}
+	}
+
+	return err
+}
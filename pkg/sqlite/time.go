@@ -0,0 +1,9 @@
+package sqlite
+
+import "time"
+
+// Time writes a date the way the schema stores them, as text in the time.DateTime format, so dates are compared as
+// text in the queries. The drivers would write the time zone too otherwise.
+func Time(t time.Time) string {
+	return t.Format(time.DateTime)
+}
@@ -0,0 +1,142 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// executor runs queries, both sql.DB and sql.Tx are executors.
+type executor interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// DB is the database of a store, its queries are run in the transaction of the unit of work of the context, when it
+// carries one, or on their own otherwise.
+type DB struct {
+	db *sql.DB
+}
+
+// NewDB wraps the database of a store.
+func NewDB(db *sql.DB) *DB {
+	return &DB{
+		db: db,
+	}
+}
+
+// QueryContext runs a query returning rows.
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.executor(ctx).QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query returning at most one row.
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.executor(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext runs a query without returning rows.
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.executor(ctx).ExecContext(ctx, query, args...)
+}
+
+// PrepareContext creates a prepared statement.
+func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return d.executor(ctx).PrepareContext(ctx, query)
+}
+
+// BeginTx starts a transaction. When the context carries a unit of work, it starts a savepoint of its transaction
+// instead, so committing it keeps its changes until the unit of work ends and rolling it back undoes only them.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	u := current(ctx)
+	if u == nil {
+		tx, err := d.db.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Tx{tx: tx}, nil
+	}
+
+	u.savepoints++
+	savepoint := fmt.Sprintf("savepoint_%d", u.savepoints)
+
+	_, err := u.tx.ExecContext(ctx, "SAVEPOINT "+savepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{tx: u.tx, ctx: ctx, savepoint: savepoint}, nil
+}
+
+// executor returns the transaction of the unit of work of the context, or the database when it carries none.
+func (d *DB) executor(ctx context.Context) executor {
+	u := current(ctx)
+	if u == nil {
+		return d.db
+	}
+
+	return u.tx
+}
+
+// Tx is a transaction of a store, a transaction of its own or a savepoint of the one of a unit of work.
+type Tx struct {
+	tx        *sql.Tx
+	ctx       context.Context
+	savepoint string
+	done      bool
+}
+
+// QueryContext runs a query returning rows.
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query returning at most one row.
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext runs a query without returning rows.
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext creates a prepared statement of the transaction.
+func (t *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.tx.PrepareContext(ctx, query)
+}
+
+// Commit commits the transaction, or releases the savepoint, keeping its changes in the unit of work.
+func (t *Tx) Commit() error {
+	if t.savepoint == "" {
+		return t.tx.Commit()
+	}
+
+	return t.end("RELEASE SAVEPOINT ")
+}
+
+// Rollback rolls back the transaction, or the changes done since the savepoint. Like sql.Tx, it fails with
+// sql.ErrTxDone when the transaction was already committed or rolled back.
+func (t *Tx) Rollback() error {
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+
+	return t.end("ROLLBACK TO SAVEPOINT ")
+}
+
+// end runs the statement ending the savepoint, once.
+func (t *Tx) end(statement string) error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+
+	t.done = true
+
+	_, err := t.tx.ExecContext(t.ctx, statement+t.savepoint)
+
+	return err
+}
@@ -0,0 +1,124 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+)
+
+// maxAttempts is how many times a unit of work is run when the database rolls it back to break a deadlock.
+const maxAttempts = 3
+
+// Manager runs units of work, many operations of the stores, even of different services, that are saved all together
+// or none of them.
+// Do runs fn with a context carrying the unit of work, every store called with it takes part in it, and it's committed
+// when fn succeeds or rolled back when it fails. Calling Do with a context already carrying a unit of work runs fn as
+// part of it.
+type Manager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// None is the manager of the stores without transactions, like the memory ones, fn is run as it is, so every operation
+// of the stores is still atomic but the ones done before an error are kept.
+var None Manager = none{}
+
+// none runs the units of work without a transaction.
+type none struct{}
+
+// Do runs fn.
+func (none) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// key is the key of the unit of work in the context.
+type key struct{}
+
+// unit is the transaction of a unit of work, savepoints counts the savepoints created in it to name them.
+type unit struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// SQL is the manager of the stores of a SQL database, it runs every unit of work in a transaction.
+type SQL struct {
+	db        *sql.DB
+	isolation sql.IsolationLevel
+	retryable func(err error) bool
+}
+
+// New creates a manager of the units of work of the database, their transactions are started with the isolation level.
+// Stores check the rows they lock reading them again, so the isolation must let every query see the rows committed
+// before it, like sql.LevelReadCommitted. With the REPEATABLE READ default of MySQL, the first plain read of a unit of
+// work fixes what the next ones see, so they miss the rows committed while it waited for a lock.
+// retryable reports whether the unit of work failed due to a deadlock, so it's run again from the start, it's never
+// run again when retryable is nil.
+func New(db *sql.DB, isolation sql.IsolationLevel, retryable func(err error) bool) *SQL {
+	return &SQL{
+		db:        db,
+		isolation: isolation,
+		retryable: retryable,
+	}
+}
+
+// Do runs fn in a transaction, it's run again up to maxAttempts times while it fails with a deadlock.
+func (m *SQL) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if current(ctx) != nil {
+		return fn(ctx)
+	}
+
+	var err error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		err = m.run(ctx, fn)
+		if err == nil || m.retryable == nil || !m.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// run runs fn in a new transaction, committing it when it succeeds.
+func (m *SQL) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: m.isolation})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Println(err)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, key{}, &unit{tx: tx}))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// current returns the unit of work the context carries, or nil when it carries none.
+func current(ctx context.Context) *unit {
+	u, _ := ctx.Value(key{}).(*unit)
+	return u
+}
+
+// InProgress reports whether the context carries a unit of work.
+func InProgress(ctx context.Context) bool {
+	return current(ctx) != nil
+}
+
+// ForUpdate returns the query locking the rows it reads until the unit of work ends, when the context carries one, so
+// they can't change between reading and writing them. Otherwise, it returns the query as it is.
+// SQLite has no row locks, but it runs one transaction at a time, so its stores don't need it.
+func ForUpdate(ctx context.Context, query string) string {
+	if current(ctx) == nil {
+		return query
+	}
+
+	return query + " FOR UPDATE"
+}
@@ -1,15 +1,27 @@
 package web
 
 import (
+	"context"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"github.com/Nachofra/final-esp-backend-3/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
-// response is a struc for responses
+// ErrTimeout is the message of the error responses of the requests that ran out of time waiting for the database.
+var ErrTimeout = errors.New("the database took too long to answer, try again later")
+
+// ErrUnavailable is the message of the error responses of the requests that couldn't reach the database.
+var ErrUnavailable = errors.New("the database is unavailable, try again later")
+
+// response is a struc for responses, Meta is only set for paginated ones
 type response struct {
-	Data interface{} `json:"data"`
+	Data interface{}      `json:"data"`
+	Meta *pagination.Meta `json:"meta,omitempty"`
 }
 
 // errorResponse is a struc for errors
@@ -29,6 +41,50 @@ func Success(c *gin.Context, status int, data interface{}) {
 	Response(c, status, response{Data: data})
 }
 
+// Page creates the successful response of a page with its metadata, and sets the Link header with the URLs of the
+// next, previous, first and last pages when they exist, keeping the rest of the query of the request.
+func Page(c *gin.Context, status int, data interface{}, meta pagination.Meta) {
+	links := make([]string, 0, 4)
+
+	link := func(rel string, key string, value string) {
+		u := *c.Request.URL
+		q := u.Query()
+		q.Del("page")
+		q.Del("cursor")
+		q.Set(key, value)
+		u.RawQuery = q.Encode()
+
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+
+	if meta.NextCursor != "" {
+		if meta.Page > 0 {
+			link("next", "page", strconv.Itoa(meta.Page+1))
+		} else {
+			link("next", "cursor", meta.NextCursor)
+		}
+	}
+
+	if meta.Page > 1 {
+		link("prev", "page", strconv.Itoa(meta.Page-1))
+	}
+
+	if meta.PageSize > 0 {
+		link("first", "page", "1")
+
+		last := (meta.Total + meta.PageSize - 1) / meta.PageSize
+		if last > 1 {
+			link("last", "page", strconv.Itoa(last))
+		}
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+
+	Response(c, status, response{Data: data, Meta: &meta})
+}
+
 // Error creates a new error with the given status code and the message
 // formatted according to args and format.
 func Error(c *gin.Context, status int, format string, args ...interface{}) {
@@ -40,3 +96,25 @@ func Error(c *gin.Context, status int, format string, args ...interface{}) {
 
 	Response(c, status, err)
 }
+
+// InternalError creates the error response of an error the client can't fix, with 504 and ErrTimeout when the request
+// ran out of time waiting for the database, with 503 and ErrUnavailable when it couldn't reach it, otherwise with 500
+// and the message. The error is added to the errors of the context, so the logger prints the cause the client doesn't
+// see.
+func InternalError(c *gin.Context, err error, message error) {
+	if err != nil {
+		_ = c.Error(err)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		Error(c, http.StatusGatewayTimeout, "%s", ErrTimeout)
+		return
+	}
+
+	if errors.Is(err, driver.ErrBadConn) {
+		Error(c, http.StatusServiceUnavailable, "%s", ErrUnavailable)
+		return
+	}
+
+	Error(c, http.StatusInternalServerError, "%s", message)
+}